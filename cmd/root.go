@@ -32,6 +32,8 @@ func NewCommand() *cobra.Command {
 
 	command.AddCommand(getVersionCmd())
 	command.AddCommand(server.GetServerCmd())
+	command.AddCommand(server.GetDeviceLoginCmd())
+	command.AddCommand(server.GetDedupeDocumentsCmd())
 	command.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 
 	return command
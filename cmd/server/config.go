@@ -1,54 +1,153 @@
 package server
 
+import (
+	"time"
+
+	"github.com/ishank09/data-extraction-service/pkg/mongodb"
+)
+
 type Config struct {
 	Server struct {
-		Port int64
+		Port              int64
+		CORSAllowOrigins  []string // Origins allowed to call the API with credentials (empty allows any origin without credentials)
+		MaxUploadSize     int64    // Largest upload request body in bytes
+		MaxUploadFileSize int64    // Largest single uploaded file in bytes
 	}
 	MSGraph struct {
 		ClientID     string
 		ClientSecret string
-		TenantID     string // Use "common" for personal accounts, specific tenant ID for work/school accounts
-		UserID       string // Required for application flow when accessing user data
+		TenantID     string        // Use "common" for personal accounts, specific tenant ID for work/school accounts
+		UserID       string        // Required for application flow when accessing user data
+		MaxRetries   int           // Retries for throttled (429/503) Graph requests
+		RetryBase    time.Duration // Initial backoff when Graph sends no Retry-After header
+		RetryMax     time.Duration // Upper bound for the backoff between retries
+
+		// Application credentials used instead of the client secret
+		CertificatePath     string // PEM or PKCS#12 (PFX) file with certificate and RSA private key
+		CertificatePassword string // Password of an encrypted certificate file
+		FederatedTokenFile  string // Federated workload identity token file
+
+		// National cloud
+		Cloud         string // "global", "usgov", "usgov-dod", "china" or "germany"
+		LoginEndpoint string // Overrides the cloud's identity platform host
+		GraphEndpoint string // Overrides the cloud's Graph API base URL
 	}
 	OAuth struct {
 		RedirectURI string
 		Scopes      []string
+
+		// Server-side token storage
+		TokenEncryptionKey string // 32-byte key as base64 or hex (empty returns tokens to the caller)
+		TokenDir           string // Directory for encrypted tokens (empty stores them in MongoDB)
+
+		SecureCookies bool // Mark the session cookie Secure behind a TLS-terminating proxy
 	}
 	OneNote struct {
-		MaxSectionWorkers int // Maximum concurrent section workers for OneNote processing
-		MaxContentWorkers int // Maximum concurrent content workers for OneNote processing
+		MaxSectionWorkers  int    // Maximum concurrent section workers for OneNote processing
+		MaxContentWorkers  int    // Maximum concurrent content workers for OneNote processing
+		MaxResourceWorkers int    // Maximum concurrent attachment download workers for OneNote processing
+		MaxAttachmentSize  int64  // Largest OneNote attachment downloaded in bytes
+		PageSize           int    // Items requested per Graph page ($top, 0 uses the Graph default)
+		MaxItems           int    // Maximum notebooks, sections and pages listed per run (0 = unlimited)
+		SyncStateDir       string // Directory for incremental sync checkpoints (empty keeps them in memory)
+
+		// Crawl filter
+		IncludeNotebooks []string // Notebook names or IDs to crawl (empty crawls all)
+		ExcludeNotebooks []string // Notebook names or IDs to skip
+		IncludeSections  []string // Section names or IDs to crawl (empty crawls all)
+		ExcludeSections  []string // Section names or IDs to skip
+		PageTitlePattern string   // Regular expression page titles must match
+	}
+	Filesystem struct {
+		Roots         []string // Host directories walked by the filesystem source
+		Include       []string // Glob patterns files must match
+		Exclude       []string // Glob patterns excluding files and directories
+		MaxDepth      int      // Maximum directory depth below each root (0 = unlimited)
+		SymlinkPolicy string   // "skip" or "follow"
 	}
 	MongoDB struct {
-		URI        string
-		Database   string
-		Username   string
-		Password   string
-		AuthSource string
+		URI         string
+		Database    string
+		Username    string
+		Password    string
+		AuthSource  string
+		StoreMode   string        // "upsert" or "insert"
+		DocumentTTL time.Duration // Expire documents this long after fetched_at (0 = never)
+	}
+	Retention struct {
+		Policies []mongodb.RetentionPolicy // Per-source maximum document ages
+		Interval time.Duration             // How often policies run in the background (0 = only on demand)
 	}
 }
 
 const (
-	PortEnvVar            = "PORT"
-	EnvironmentNameEnvVar = "ENVIRONMENT_NAME"
+	PortEnvVar              = "PORT"
+	EnvironmentNameEnvVar   = "ENVIRONMENT_NAME"
+	CORSAllowOriginsEnvVar  = "CORS_ALLOW_ORIGINS"   // Comma-separated origins allowed with credentials (default: any origin, no credentials)
+	MaxUploadSizeEnvVar     = "UPLOAD_MAX_SIZE"      // Largest upload request body in bytes (default: 100 MiB)
+	MaxUploadFileSizeEnvVar = "UPLOAD_MAX_FILE_SIZE" // Largest single uploaded file in bytes (default: 25 MiB)
 
 	// MSGraph environment variables
 	MSGraphClientIDEnvVar     = "MSGRAPH_CLIENT_ID"
 	MSGraphClientSecretEnvVar = "MSGRAPH_CLIENT_SECRET"
-	MSGraphTenantIDEnvVar     = "MSGRAPH_TENANT_ID" // Use "common" for personal accounts
-	MSGraphUserIDEnvVar       = "MSGRAPH_USER_ID"   // New environment variable for user ID
+	MSGraphTenantIDEnvVar     = "MSGRAPH_TENANT_ID"        // Use "common" for personal accounts
+	MSGraphUserIDEnvVar       = "MSGRAPH_USER_ID"          // New environment variable for user ID
+	MSGraphMaxRetriesEnvVar   = "MSGRAPH_MAX_RETRIES"      // Retries for throttled Graph requests (default: 5)
+	MSGraphRetryBaseEnvVar    = "MSGRAPH_RETRY_BASE_DELAY" // Initial backoff without Retry-After (default: 1s)
+	MSGraphRetryMaxEnvVar     = "MSGRAPH_RETRY_MAX_DELAY"  // Maximum backoff between retries (default: 1m)
+
+	// MSGraph application credential environment variables (alternatives to the client secret)
+	MSGraphCertificatePathEnvVar     = "MSGRAPH_CERTIFICATE_PATH"     // PEM or PFX file with certificate and private key
+	MSGraphCertificatePasswordEnvVar = "MSGRAPH_CERTIFICATE_PASSWORD" // Password of an encrypted certificate file
+	MSGraphFederatedTokenFileEnvVar  = "MSGRAPH_FEDERATED_TOKEN_FILE" // Federated workload identity token file
+
+	// MSGraph national cloud environment variables
+	MSGraphCloudEnvVar         = "MSGRAPH_CLOUD"          // global (default), usgov, usgov-dod, china or germany
+	MSGraphLoginEndpointEnvVar = "MSGRAPH_LOGIN_ENDPOINT" // Overrides the cloud's identity platform host
+	MSGraphGraphEndpointEnvVar = "MSGRAPH_GRAPH_ENDPOINT" // Overrides the cloud's Graph API base URL
 
 	// OAuth environment variables
 	OAuthRedirectURIEnvVar = "OAUTH_REDIRECT_URI"
 	OAuthScopesEnvVar      = "OAUTH_SCOPES" // Comma-separated list of scopes
 
+	// OAuth token storage environment variables
+	OAuthTokenEncryptionKeyEnvVar = "OAUTH_TOKEN_ENCRYPTION_KEY" // 32-byte key as base64 or hex, enables server-side token storage
+	OAuthTokenDirEnvVar           = "OAUTH_TOKEN_DIR"            // Directory for encrypted tokens (default: MongoDB)
+	OAuthSecureCookiesEnvVar      = "OAUTH_SECURE_COOKIES"       // Always mark the session cookie Secure (default: only on direct TLS)
+
 	// OneNote performance tuning environment variables
-	OneNoteSectionWorkersEnvVar = "ONENOTE_SECTION_WORKERS" // Max concurrent section workers (default: 5)
-	OneNoteContentWorkersEnvVar = "ONENOTE_CONTENT_WORKERS" // Max concurrent content workers (default: 10)
+	OneNoteSectionWorkersEnvVar    = "ONENOTE_SECTION_WORKERS"     // Max concurrent section workers (default: 5)
+	OneNoteContentWorkersEnvVar    = "ONENOTE_CONTENT_WORKERS"     // Max concurrent content workers (default: 10)
+	OneNoteResourceWorkersEnvVar   = "ONENOTE_RESOURCE_WORKERS"    // Max concurrent attachment downloads (default: 5, negative disables)
+	OneNoteMaxAttachmentSizeEnvVar = "ONENOTE_MAX_ATTACHMENT_SIZE" // Largest attachment downloaded in bytes (default: 25 MiB)
+	OneNotePageSizeEnvVar          = "ONENOTE_PAGE_SIZE"           // Items requested per Graph page (default: 100)
+	OneNoteMaxItemsEnvVar          = "ONENOTE_MAX_ITEMS"           // Max notebooks, sections and pages listed per run (default: 0 = unlimited)
+	OneNoteSyncStateDirEnvVar      = "ONENOTE_SYNC_STATE_DIR"      // Directory for sync checkpoints (default: in memory)
+
+	// OneNote crawl filter environment variables
+	OneNoteIncludeNotebooksEnvVar = "ONENOTE_INCLUDE_NOTEBOOKS"  // Comma-separated notebook names or IDs to crawl
+	OneNoteExcludeNotebooksEnvVar = "ONENOTE_EXCLUDE_NOTEBOOKS"  // Comma-separated notebook names or IDs to skip
+	OneNoteIncludeSectionsEnvVar  = "ONENOTE_INCLUDE_SECTIONS"   // Comma-separated section names or IDs to crawl
+	OneNoteExcludeSectionsEnvVar  = "ONENOTE_EXCLUDE_SECTIONS"   // Comma-separated section names or IDs to skip
+	OneNotePageTitlePatternEnvVar = "ONENOTE_PAGE_TITLE_PATTERN" // Regular expression page titles must match
+
+	// Filesystem source environment variables
+	FilesystemRootsEnvVar         = "FILESYSTEM_ROOTS"          // Comma-separated list of host directories
+	FilesystemIncludeEnvVar       = "FILESYSTEM_INCLUDE"        // Comma-separated include globs
+	FilesystemExcludeEnvVar       = "FILESYSTEM_EXCLUDE"        // Comma-separated exclude globs
+	FilesystemMaxDepthEnvVar      = "FILESYSTEM_MAX_DEPTH"      // Max directory depth (default: 0, unlimited)
+	FilesystemSymlinkPolicyEnvVar = "FILESYSTEM_SYMLINK_POLICY" // "skip" (default) or "follow"
 
 	// MongoDB environment variables
-	MongoDBURIEnvVar        = "MONGODB_URI"
-	MongoDBDatabaseEnvVar   = "MONGODB_DATABASE"
-	MongoDBUsernameEnvVar   = "MONGODB_USERNAME"
-	MongoDBPasswordEnvVar   = "MONGODB_PASSWORD"
-	MongoDBAuthSourceEnvVar = "MONGODB_AUTH_SOURCE"
+	MongoDBURIEnvVar         = "MONGODB_URI"
+	MongoDBDatabaseEnvVar    = "MONGODB_DATABASE"
+	MongoDBUsernameEnvVar    = "MONGODB_USERNAME"
+	MongoDBPasswordEnvVar    = "MONGODB_PASSWORD"
+	MongoDBAuthSourceEnvVar  = "MONGODB_AUTH_SOURCE"
+	MongoDBStoreModeEnvVar   = "MONGODB_STORE_MODE"   // "upsert" (default) or "insert"
+	MongoDBDocumentTTLEnvVar = "MONGODB_DOCUMENT_TTL" // Go duration, e.g. "2160h" (default: no expiry)
+
+	// Retention environment variables
+	RetentionPoliciesEnvVar = "RETENTION_POLICIES" // Comma-separated source=age pairs, e.g. "onenote=90d,static=7d,*=30d"
+	RetentionIntervalEnvVar = "RETENTION_INTERVAL" // Go duration between background runs (default: 0, disabled)
 )
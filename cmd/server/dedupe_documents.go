@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+// GetDedupeDocumentsCmd returns the command removing duplicate stored documents that keep the
+// server from creating its unique documents indexes
+func GetDedupeDocumentsCmd() *cobra.Command {
+	var cfg Config
+	var dryRun bool
+
+	command := &cobra.Command{
+		Use:   "dedupe-documents",
+		Short: "Remove duplicate stored documents blocking the unique indexes",
+		Long: "Remove documents stored before the unique documents indexes existed that share their keys. " +
+			"The most recently fetched document of every duplicated key is kept; older revisions are archived " +
+			"to the version history first. Every removed document is logged. Use --dry-run to only list them.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			setCmdFlagsFromEnv(cmd, &cfg)
+
+			if cfg.MongoDB.URI == "" {
+				return fmt.Errorf("%s is required", MongoDBURIEnvVar)
+			}
+			mongoClient, documentService, err := createMongoDBClient(&cfg)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := mongoClient.Disconnect(context.Background()); err != nil {
+					log.Errorf("Error disconnecting from MongoDB: %v", err)
+				}
+			}()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			report, err := documentService.RemoveDuplicateDocuments(ctx, dryRun)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return errors.New("deduplication cancelled")
+				}
+				return err
+			}
+
+			verb := "Removed"
+			if report.DryRun {
+				verb = "Would remove"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d duplicate documents for %d keys, archiving %d older revisions\n",
+				verb, report.RemovedDocuments, len(report.DuplicateKeys), report.ArchivedVersions)
+			if report.RemovedDocuments > 0 && !report.DryRun {
+				fmt.Fprintln(cmd.OutOrStdout(), "The unique indexes are created the next time the server starts")
+			}
+			return nil
+		},
+	}
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "list the duplicate documents without removing them")
+
+	return command
+}
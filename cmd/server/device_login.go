@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ishank09/data-extraction-service/pkg/mongodb"
+	"github.com/ishank09/data-extraction-service/pkg/msgraph"
+	"github.com/spf13/cobra"
+)
+
+// GetDeviceLoginCmd returns the command signing a user in with the device code flow and
+// storing the token for the pipeline
+func GetDeviceLoginCmd() *cobra.Command {
+	var cfg Config
+
+	return &cobra.Command{
+		Use:   "device-login",
+		Short: "Sign in to Microsoft Graph with a device code",
+		Long: "Sign in to Microsoft Graph on a host without a browser. Prints a code to enter on any device, " +
+			"waits for sign-in and stores the token so pipeline requests can run with ?user_key=... and the printed user secret",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			setCmdFlagsFromEnv(cmd, &cfg)
+
+			if cfg.MSGraph.ClientID == "" {
+				return fmt.Errorf("%s is required", MSGraphClientIDEnvVar)
+			}
+			if cfg.OAuth.TokenEncryptionKey == "" {
+				return fmt.Errorf("%s is required to store the token", OAuthTokenEncryptionKeyEnvVar)
+			}
+
+			// Tokens go to the same store the server reads them from
+			var mongoClient mongodb.Interface
+			if cfg.OAuth.TokenDir == "" {
+				client, _, err := createMongoDBClient(&cfg)
+				if err != nil {
+					return err
+				}
+				if client != nil {
+					mongoClient = client
+					defer func() {
+						if err := mongoClient.Disconnect(context.Background()); err != nil {
+							log.Errorf("Error disconnecting from MongoDB: %v", err)
+						}
+					}()
+				}
+			}
+
+			tokenVault, err := createTokenVault(&cfg, mongoClient)
+			if err != nil {
+				return err
+			}
+			oauthConfig, err := createOAuthConfig(&cfg)
+			if err != nil {
+				return err
+			}
+			oauthClient := msgraph.NewOAuthClient(oauthConfig)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			deviceCode, err := oauthClient.StartDeviceAuthorization(ctx)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return errors.New("device login cancelled")
+				}
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), deviceCode.Message)
+
+			tokens, err := oauthClient.WaitForDeviceAuthorization(ctx, deviceCode)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return errors.New("device login cancelled")
+				}
+				return err
+			}
+
+			userKey, err := oauthClient.GetUserID(tokens.AccessToken)
+			if err != nil {
+				return err
+			}
+			secret, err := tokenVault.Store(ctx, userKey, tokens)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Signed in. Token stored for user_key %s\n", userKey)
+			fmt.Fprintf(cmd.OutOrStdout(), "Pass this user secret in the %s header; it is not shown again:\n%s\n", msgraph.UserSecretHeader, secret)
+			if tokens.RefreshToken == "" {
+				log.Warnf("No refresh token issued; add offline_access to %s so the token can be refreshed", OAuthScopesEnvVar)
+			}
+			return nil
+		},
+	}
+}
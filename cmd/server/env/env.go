@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/ishank09/data-extraction-service/pkg/logging"
 )
@@ -53,6 +54,18 @@ func ParseInt(envVar string, defaultValue int64) int64 {
 	return result
 }
 
+func ParseDuration(envVar string, defaultValue time.Duration) time.Duration {
+	if os.Getenv(envVar) == "" {
+		return defaultValue
+	}
+
+	result, err := time.ParseDuration(os.Getenv(envVar))
+	if err != nil {
+		log.Fatal("error parsing duration from environment", "err", err)
+	}
+	return result
+}
+
 func Bas64DecodeOrDie(s string) []byte {
 	bytes, err := base64.StdEncoding.DecodeString(s)
 	if err != nil {
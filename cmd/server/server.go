@@ -2,11 +2,13 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
@@ -17,6 +19,7 @@ import (
 	"github.com/ishank09/data-extraction-service/pkg/api/v1/health"
 	"github.com/ishank09/data-extraction-service/pkg/api/v1/msgraphhandler"
 	"github.com/ishank09/data-extraction-service/pkg/api/v1/pipelinehandler"
+	"github.com/ishank09/data-extraction-service/pkg/filesystem"
 	"github.com/ishank09/data-extraction-service/pkg/logging"
 	"github.com/ishank09/data-extraction-service/pkg/mongodb"
 	"github.com/ishank09/data-extraction-service/pkg/msgraph"
@@ -69,13 +72,7 @@ func GetServerCmd() *cobra.Command {
 				},
 			)
 
-			// Custom CORS configuration to allow all origins
-			// Apply the custom CORS configuration to the engine
-			engine.Use(cors.New(cors.Config{
-				AllowOrigins: []string{"*"},
-				AllowHeaders: []string{"*"},
-				AllowMethods: []string{"*"},
-			}))
+			engine.Use(cors.New(createCORSConfig(&cfg)))
 			engine.Use(requestid.New())
 			engine.Use(logging.GetGinRequestLogDecoratorMiddleware())
 
@@ -86,15 +83,22 @@ func GetServerCmd() *cobra.Command {
 				getMetricsMiddlewareHandler("GET /ping", httpMetricsMiddlewareInstance),
 			)
 
+			// Share OneNote sync checkpoints between all Graph clients, persisted across restarts if a directory is configured
+			checkpointStore, err := createCheckpointStore(&cfg)
+			if err != nil {
+				log.Errorf("Failed to create OneNote sync checkpoint store: %v", err)
+				return err
+			}
+
 			// Create ETL pipeline handler
-			handler, err := createPipelineHandler(&cfg)
+			handler, err := createPipelineHandler(&cfg, checkpointStore)
 			if err != nil {
 				log.Errorf("Failed to create pipeline handler: %v", err)
 				return err
 			}
 
 			// Create OAuth-enabled msgraph handler
-			msgraphHandler, err := createMSGraphHandler(&cfg)
+			msgraphHandler, err := createMSGraphHandler(&cfg, checkpointStore)
 			if err != nil {
 				log.Errorf("Failed to create msgraph handler: %v", err)
 			}
@@ -108,9 +112,15 @@ func GetServerCmd() *cobra.Command {
 
 			// Update pipeline handler with document service if MongoDB is available
 			if documentService != nil {
+				if err := ensureDocumentStorage(&cfg, documentService); err != nil {
+					mongoClient.Disconnect(context.Background())
+					log.Errorf("Failed to set up MongoDB document storage: %v", err)
+					return err
+				}
+
 				log.Infof("MongoDB integration enabled")
 				// Recreate pipeline handler with document service
-				handler, err = createPipelineHandlerWithMongoDB(&cfg, documentService)
+				handler, err = createPipelineHandlerWithMongoDB(&cfg, documentService, checkpointStore)
 				if err != nil {
 					log.Errorf("Failed to recreate pipeline handler with MongoDB: %v", err)
 					return err
@@ -123,9 +133,43 @@ func GetServerCmd() *cobra.Command {
 			var documentHandler *documenthandler.Handler
 			if documentService != nil {
 				documentConfig := &documenthandler.Config{
-					DocumentService: documentService,
+					DocumentService:   documentService,
+					RetentionPolicies: cfg.Retention.Policies,
 				}
 				documentHandler = documenthandler.New(documentConfig)
+
+				// Apply retention policies in the background if a schedule is configured
+				if cfg.Retention.Interval > 0 && len(cfg.Retention.Policies) > 0 {
+					log.Infof("Retention scheduled every %s for %d policies", cfg.Retention.Interval, len(cfg.Retention.Policies))
+					retentionScheduler := mongodb.NewRetentionScheduler(documentService, cfg.Retention.Policies, cfg.Retention.Interval)
+					retentionScheduler.Start()
+					defer retentionScheduler.Stop()
+				}
+			}
+
+			// Persist issued OAuth states so sign-in survives restarts and works across replicas
+			if msgraphHandler != nil && mongoClient != nil {
+				stateService := mongodb.NewOAuthStateService(mongoClient)
+				if err := stateService.EnsureIndexes(context.Background()); err != nil {
+					log.Errorf("Failed to ensure OAuth state indexes: %v", err)
+				}
+				msgraphHandler.SetOAuthStateStore(stateService)
+			}
+			if msgraphHandler != nil {
+				msgraphHandler.SetSecureCookies(cfg.OAuth.SecureCookies)
+			}
+
+			// Store OAuth tokens server-side so pipelines can run for a stored user
+			if cfg.OAuth.TokenEncryptionKey != "" {
+				tokenVault, err := createTokenVault(&cfg, mongoClient)
+				if err != nil {
+					log.Errorf("Failed to create OAuth token vault: %v", err)
+					return err
+				}
+				handler.SetTokenVault(tokenVault)
+				if msgraphHandler != nil {
+					msgraphHandler.SetTokenVault(tokenVault)
+				}
 			}
 
 			// ETL Pipeline routes
@@ -133,6 +177,8 @@ func GetServerCmd() *cobra.Command {
 			v1.GET("/pipeline", handler.ExtractAllData, getMetricsMiddlewareHandler("GET /api/v1/pipeline", httpMetricsMiddlewareInstance))
 			v1.GET("/pipeline/:source", handler.ExtractDataBySource, getMetricsMiddlewareHandler("GET /api/v1/pipeline/:source", httpMetricsMiddlewareInstance))
 			v1.GET("/pipeline/type/:type", handler.ExtractDataByType, getMetricsMiddlewareHandler("GET /api/v1/pipeline/type/:type", httpMetricsMiddlewareInstance))
+			v1.POST("/pipeline/upload", handler.UploadDocuments, getMetricsMiddlewareHandler("POST /api/v1/pipeline/upload", httpMetricsMiddlewareInstance))
+			v1.POST("/pipeline/sync/onenote", handler.SyncOneNote, getMetricsMiddlewareHandler("POST /api/v1/pipeline/sync/onenote", httpMetricsMiddlewareInstance))
 			v1.GET("/sources", handler.GetSources, getMetricsMiddlewareHandler("GET /api/v1/sources", httpMetricsMiddlewareInstance))
 			v1.GET("/health", handler.GetHealth, getMetricsMiddlewareHandler("GET /api/v1/health", httpMetricsMiddlewareInstance))
 
@@ -141,18 +187,27 @@ func GetServerCmd() *cobra.Command {
 				documents := v1.Group("/documents")
 				documents.GET("", documentHandler.GetDocuments, getMetricsMiddlewareHandler("GET /api/v1/documents", httpMetricsMiddlewareInstance))
 				documents.GET("/collections", documentHandler.GetDocumentCollections, getMetricsMiddlewareHandler("GET /api/v1/documents/collections", httpMetricsMiddlewareInstance))
+				documents.GET("/search", documentHandler.SearchDocuments, getMetricsMiddlewareHandler("GET /api/v1/documents/search", httpMetricsMiddlewareInstance))
 				documents.GET("/stats", documentHandler.GetDocumentStats, getMetricsMiddlewareHandler("GET /api/v1/documents/stats", httpMetricsMiddlewareInstance))
 				documents.DELETE("/cleanup", documentHandler.DeleteOldDocuments, getMetricsMiddlewareHandler("DELETE /api/v1/documents/cleanup", httpMetricsMiddlewareInstance))
+				documents.POST("/retention/run", documentHandler.RunRetention, getMetricsMiddlewareHandler("POST /api/v1/documents/retention/run", httpMetricsMiddlewareInstance))
+				documents.GET("/collections/:id", documentHandler.GetDocumentCollection, getMetricsMiddlewareHandler("GET /api/v1/documents/collections/:id", httpMetricsMiddlewareInstance))
+				documents.GET("/:id", documentHandler.GetDocument, getMetricsMiddlewareHandler("GET /api/v1/documents/:id", httpMetricsMiddlewareInstance))
+				documents.DELETE("/:id", documentHandler.DeleteDocument, getMetricsMiddlewareHandler("DELETE /api/v1/documents/:id", httpMetricsMiddlewareInstance))
+				documents.GET("/:id/versions", documentHandler.GetDocumentVersions, getMetricsMiddlewareHandler("GET /api/v1/documents/:id/versions", httpMetricsMiddlewareInstance))
+				documents.GET("/:id/versions/diff", documentHandler.DiffDocumentVersions, getMetricsMiddlewareHandler("GET /api/v1/documents/:id/versions/diff", httpMetricsMiddlewareInstance))
 				documents.GET("/health", documentHandler.GetHealth, getMetricsMiddlewareHandler("GET /api/v1/documents/health", httpMetricsMiddlewareInstance))
 			}
 
 			// OAuth routes for Microsoft Graph
 			if msgraphHandler != nil {
 				oauth := v1.Group("/oauth")
+				oauth.GET("/authorize", msgraphHandler.AuthorizeRedirect, getMetricsMiddlewareHandler("GET /api/v1/oauth/authorize", httpMetricsMiddlewareInstance))
 				oauth.POST("/authorize", msgraphHandler.Authorize, getMetricsMiddlewareHandler("POST /api/v1/oauth/authorize", httpMetricsMiddlewareInstance))
 				oauth.GET("/callback", msgraphHandler.Callback, getMetricsMiddlewareHandler("GET /api/v1/oauth/callback", httpMetricsMiddlewareInstance))
 				oauth.POST("/refresh", msgraphHandler.RefreshToken, getMetricsMiddlewareHandler("POST /api/v1/oauth/refresh", httpMetricsMiddlewareInstance))
 				oauth.POST("/test", msgraphHandler.TestToken, getMetricsMiddlewareHandler("POST /api/v1/oauth/test", httpMetricsMiddlewareInstance))
+				oauth.POST("/device", msgraphHandler.Device, getMetricsMiddlewareHandler("POST /api/v1/oauth/device", httpMetricsMiddlewareInstance))
 
 				// MSGraph routes
 				msgraph := v1.Group("/msgraph")
@@ -199,74 +254,198 @@ func GetServerCmd() *cobra.Command {
 }
 
 // createPipelineHandler creates a pipeline handler with MSGraph configuration from environment variables
-func createPipelineHandler(cfg *Config) (*pipelinehandler.Handler, error) {
+func createPipelineHandler(cfg *Config, checkpointStore msgraph.CheckpointStore) (*pipelinehandler.Handler, error) {
 	// Check if MSGraph configuration is available
-	if cfg.MSGraph.ClientID != "" && cfg.MSGraph.ClientSecret != "" && cfg.MSGraph.TenantID != "" {
+	if hasApplicationCredentials(cfg) {
 		log.Infof("Creating pipeline handler with MSGraph integration")
-		log.Infof("OneNote concurrency: %d section workers, %d content workers", cfg.OneNote.MaxSectionWorkers, cfg.OneNote.MaxContentWorkers)
+		log.Infof("OneNote concurrency: %d section workers, %d content workers, %d resource workers", cfg.OneNote.MaxSectionWorkers, cfg.OneNote.MaxContentWorkers, cfg.OneNote.MaxResourceWorkers)
 
 		config := &pipelinehandler.Config{
-			MSGraphConfig: &msgraph.Config{
-				ClientID:     cfg.MSGraph.ClientID,
-				ClientSecret: cfg.MSGraph.ClientSecret,
-				TenantID:     cfg.MSGraph.TenantID,
-				OneNoteConcurrency: &msgraph.ConcurrencyConfig{
-					MaxSectionWorkers: cfg.OneNote.MaxSectionWorkers,
-					MaxContentWorkers: cfg.OneNote.MaxContentWorkers,
-				},
-			},
-			UserID: cfg.MSGraph.UserID, // Pass user ID for application flow
+			MSGraphConfig:    createMSGraphConfig(cfg, checkpointStore),
+			UserID:           cfg.MSGraph.UserID, // Pass user ID for application flow
+			FilesystemConfig: createFilesystemConfig(cfg),
+			Upload:           createUploadConfig(cfg),
 		}
 		return pipelinehandler.New(config)
 	}
 
 	// Fallback to static files only
 	log.Infof("Creating pipeline handler with static files only (MSGraph not configured)")
-	return pipelinehandler.New(nil)
+	return pipelinehandler.New(&pipelinehandler.Config{
+		FilesystemConfig: createFilesystemConfig(cfg),
+		Upload:           createUploadConfig(cfg),
+		CheckpointStore:  checkpointStore, // Used by clients created from request tokens
+	})
+}
+
+// createMSGraphConfig creates the Graph client configuration shared by the pipeline, msgraph
+// and OAuth setup: app registration, credentials, cloud, concurrency, paging, retries, filter
+// and the store for sync checkpoints
+func createMSGraphConfig(cfg *Config, checkpointStore msgraph.CheckpointStore) *msgraph.Config {
+	return &msgraph.Config{
+		ClientID:     cfg.MSGraph.ClientID,
+		ClientSecret: cfg.MSGraph.ClientSecret,
+		TenantID:     cfg.MSGraph.TenantID,
+		// Certificate or workload identity instead of the client secret
+		CertificatePath:     cfg.MSGraph.CertificatePath,
+		CertificatePassword: cfg.MSGraph.CertificatePassword,
+		FederatedTokenFile:  cfg.MSGraph.FederatedTokenFile,
+		OneNoteConcurrency: &msgraph.ConcurrencyConfig{
+			MaxSectionWorkers:  cfg.OneNote.MaxSectionWorkers,
+			MaxContentWorkers:  cfg.OneNote.MaxContentWorkers,
+			MaxResourceWorkers: cfg.OneNote.MaxResourceWorkers,
+			MaxAttachmentSize:  cfg.OneNote.MaxAttachmentSize,
+		},
+		Pagination: &msgraph.PaginationConfig{
+			PageSize: cfg.OneNote.PageSize,
+			MaxItems: cfg.OneNote.MaxItems,
+		},
+		Retry: &msgraph.RetryConfig{
+			MaxRetries: cfg.MSGraph.MaxRetries,
+			BaseDelay:  cfg.MSGraph.RetryBase,
+			MaxDelay:   cfg.MSGraph.RetryMax,
+		},
+		Cloud:           cfg.MSGraph.Cloud,
+		LoginEndpoint:   cfg.MSGraph.LoginEndpoint,
+		GraphEndpoint:   cfg.MSGraph.GraphEndpoint,
+		OneNoteFilter:   createOneNoteFilter(cfg),
+		CheckpointStore: checkpointStore,
+	}
+}
+
+// createCheckpointStore creates the OneNote sync checkpoint store, kept in the configured
+// directory or in memory if no directory is configured
+func createCheckpointStore(cfg *Config) (msgraph.CheckpointStore, error) {
+	if cfg.OneNote.SyncStateDir == "" {
+		return msgraph.NewMemoryCheckpointStore(), nil
+	}
+
+	store, err := msgraph.NewFileCheckpointStore(cfg.OneNote.SyncStateDir)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("OneNote sync checkpoints stored in %s", cfg.OneNote.SyncStateDir)
+	return store, nil
+}
+
+// hasApplicationCredentials returns whether an app registration with a client secret,
+// certificate or federated token file is configured for application auth
+func hasApplicationCredentials(cfg *Config) bool {
+	return cfg.MSGraph.ClientID != "" && cfg.MSGraph.TenantID != "" &&
+		(cfg.MSGraph.ClientSecret != "" || cfg.MSGraph.CertificatePath != "" || cfg.MSGraph.FederatedTokenFile != "")
+}
+
+// createOneNoteFilter creates the OneNote crawl filter, or nil if everything is crawled
+func createOneNoteFilter(cfg *Config) *msgraph.FilterConfig {
+	filter := msgraph.FilterConfig{
+		IncludeNotebooks: cfg.OneNote.IncludeNotebooks,
+		ExcludeNotebooks: cfg.OneNote.ExcludeNotebooks,
+		IncludeSections:  cfg.OneNote.IncludeSections,
+		ExcludeSections:  cfg.OneNote.ExcludeSections,
+		PageTitlePattern: cfg.OneNote.PageTitlePattern,
+	}
+	if filter.IsEmpty() {
+		return nil
+	}
+	return &filter
+}
+
+// createUploadConfig creates the request and per-file size limits of document uploads
+func createUploadConfig(cfg *Config) *pipelinehandler.UploadConfig {
+	return &pipelinehandler.UploadConfig{
+		MaxRequestSize: cfg.Server.MaxUploadSize,
+		MaxFileSize:    cfg.Server.MaxUploadFileSize,
+	}
+}
+
+// createFilesystemConfig creates the filesystem source configuration, or nil if no directories are configured
+func createFilesystemConfig(cfg *Config) *filesystem.Config {
+	if len(cfg.Filesystem.Roots) == 0 {
+		return nil
+	}
+
+	log.Infof("Filesystem source enabled for %d directories", len(cfg.Filesystem.Roots))
+	return &filesystem.Config{
+		Roots:         cfg.Filesystem.Roots,
+		Include:       cfg.Filesystem.Include,
+		Exclude:       cfg.Filesystem.Exclude,
+		MaxDepth:      cfg.Filesystem.MaxDepth,
+		SymlinkPolicy: filesystem.SymlinkPolicy(cfg.Filesystem.SymlinkPolicy),
+	}
+}
+
+// createTokenVault creates the encrypted OAuth token vault, storing tokens in the configured
+// directory or else in MongoDB
+func createTokenVault(cfg *Config, mongoClient mongodb.Interface) (*msgraph.TokenVault, error) {
+	key, err := msgraph.ParseTokenKey(cfg.OAuth.TokenEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var store msgraph.TokenStore
+	switch {
+	case cfg.OAuth.TokenDir != "":
+		fileStore, err := msgraph.NewFileTokenStore(cfg.OAuth.TokenDir)
+		if err != nil {
+			return nil, err
+		}
+		store = fileStore
+		log.Infof("OAuth tokens stored in %s", cfg.OAuth.TokenDir)
+	case mongoClient != nil:
+		tokenService := mongodb.NewTokenService(mongoClient)
+		if err := tokenService.EnsureIndexes(context.Background()); err != nil {
+			log.Errorf("Failed to ensure OAuth token indexes: %v", err)
+		}
+		store = tokenService
+		log.Infof("OAuth tokens stored in MongoDB collection %s", mongodb.OAuthTokensCollectionName)
+	default:
+		return nil, fmt.Errorf("%s requires %s or MongoDB", OAuthTokenEncryptionKeyEnvVar, OAuthTokenDirEnvVar)
+	}
+
+	// Stored tokens are refreshed with the same app registration and cloud as the Graph client
+	oauthConfig, err := createOAuthConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return msgraph.NewTokenVault(store, key, msgraph.NewOAuthClient(oauthConfig))
+}
+
+// createOAuthConfig creates the OAuth configuration for the app registration and cloud of the Graph client
+func createOAuthConfig(cfg *Config) (msgraph.OAuthConfig, error) {
+	oauthConfig, err := createMSGraphConfig(cfg, nil).OAuthConfig(cfg.OAuth.RedirectURI, cfg.OAuth.Scopes)
+	if err != nil {
+		return msgraph.OAuthConfig{}, fmt.Errorf("invalid MSGraph cloud configuration: %w", err)
+	}
+	return oauthConfig, nil
 }
 
 // createMSGraphHandler creates a msgraph handler with OAuth configuration
-func createMSGraphHandler(cfg *Config) (*msgraphhandler.Handler, error) {
+func createMSGraphHandler(cfg *Config, checkpointStore msgraph.CheckpointStore) (*msgraphhandler.Handler, error) {
 	// Check if OAuth configuration is available
-	if cfg.MSGraph.ClientID != "" && cfg.MSGraph.ClientSecret != "" && cfg.MSGraph.TenantID != "" && cfg.OAuth.RedirectURI != "" {
+	if hasApplicationCredentials(cfg) && cfg.OAuth.RedirectURI != "" {
 		log.Infof("Creating msgraph handler with OAuth integration")
 
 		config := &msgraphhandler.Config{
-			MSGraphConfig: &msgraph.Config{
-				ClientID:     cfg.MSGraph.ClientID,
-				ClientSecret: cfg.MSGraph.ClientSecret,
-				TenantID:     cfg.MSGraph.TenantID,
-				OneNoteConcurrency: &msgraph.ConcurrencyConfig{
-					MaxSectionWorkers: cfg.OneNote.MaxSectionWorkers,
-					MaxContentWorkers: cfg.OneNote.MaxContentWorkers,
-				},
-			},
-			UserID: cfg.MSGraph.UserID,
-			OAuthConfig: &msgraph.OAuthConfig{
-				ClientID:     cfg.MSGraph.ClientID,
-				ClientSecret: cfg.MSGraph.ClientSecret,
-				TenantID:     cfg.MSGraph.TenantID,
-				RedirectURI:  cfg.OAuth.RedirectURI,
-				Scopes:       cfg.OAuth.Scopes,
-			},
+			MSGraphConfig: createMSGraphConfig(cfg, checkpointStore),
+			UserID:        cfg.MSGraph.UserID,
+		}
+
+		// OAuth uses the same cloud endpoints as the Graph client
+		oauthConfig, err := config.MSGraphConfig.OAuthConfig(cfg.OAuth.RedirectURI, cfg.OAuth.Scopes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MSGraph cloud configuration: %w", err)
 		}
+		config.OAuthConfig = &oauthConfig
 		return msgraphhandler.New(config)
 	}
 
 	// Check if basic MSGraph configuration is available (without OAuth)
-	if cfg.MSGraph.ClientID != "" && cfg.MSGraph.ClientSecret != "" && cfg.MSGraph.TenantID != "" {
+	if hasApplicationCredentials(cfg) {
 		log.Infof("Creating msgraph handler with basic MSGraph integration (no OAuth)")
 		config := &msgraphhandler.Config{
-			MSGraphConfig: &msgraph.Config{
-				ClientID:     cfg.MSGraph.ClientID,
-				ClientSecret: cfg.MSGraph.ClientSecret,
-				TenantID:     cfg.MSGraph.TenantID,
-				OneNoteConcurrency: &msgraph.ConcurrencyConfig{
-					MaxSectionWorkers: cfg.OneNote.MaxSectionWorkers,
-					MaxContentWorkers: cfg.OneNote.MaxContentWorkers,
-				},
-			},
-			UserID: cfg.MSGraph.UserID,
+			MSGraphConfig: createMSGraphConfig(cfg, checkpointStore),
+			UserID:        cfg.MSGraph.UserID,
 		}
 		return msgraphhandler.New(config)
 	}
@@ -322,29 +501,46 @@ func createMongoDBClient(cfg *Config) (mongodb.Interface, *mongodb.DocumentServi
 
 	// Create document service
 	documentService := mongodb.NewDocumentService(mongoClient)
+	if err := documentService.SetStoreMode(mongodb.StoreMode(cfg.MongoDB.StoreMode)); err != nil {
+		mongoClient.Disconnect(ctx)
+		return nil, nil, err
+	}
 
 	return mongoClient, documentService, nil
 }
 
+// ensureDocumentStorage bootstraps the MongoDB indexes and schema validator. Failures are logged
+// but not fatal - storage still works without them - except for unique indexes blocked by
+// duplicate documents, which index setup does not remove.
+func ensureDocumentStorage(cfg *Config, documentService *mongodb.DocumentService) error {
+	ctx := context.Background()
+
+	if err := documentService.EnsureIndexes(ctx, cfg.MongoDB.DocumentTTL); err != nil {
+		var duplicates *mongodb.DuplicateKeysError
+		if errors.As(err, &duplicates) {
+			return fmt.Errorf("%w (run the dedupe-documents command to remove them)", err)
+		}
+		log.Errorf("Failed to ensure MongoDB indexes: %v", err)
+	}
+	if err := documentService.EnsureSchema(ctx); err != nil {
+		log.Errorf("Failed to ensure MongoDB schema validator: %v", err)
+	}
+	return nil
+}
+
 // createPipelineHandlerWithMongoDB creates a pipeline handler with MongoDB integration
-func createPipelineHandlerWithMongoDB(cfg *Config, documentService *mongodb.DocumentService) (*pipelinehandler.Handler, error) {
+func createPipelineHandlerWithMongoDB(cfg *Config, documentService *mongodb.DocumentService, checkpointStore msgraph.CheckpointStore) (*pipelinehandler.Handler, error) {
 	// Check if MSGraph configuration is available
-	if cfg.MSGraph.ClientID != "" && cfg.MSGraph.ClientSecret != "" && cfg.MSGraph.TenantID != "" {
+	if hasApplicationCredentials(cfg) {
 		log.Infof("Creating pipeline handler with MSGraph and MongoDB integration")
-		log.Infof("OneNote concurrency: %d section workers, %d content workers", cfg.OneNote.MaxSectionWorkers, cfg.OneNote.MaxContentWorkers)
+		log.Infof("OneNote concurrency: %d section workers, %d content workers, %d resource workers", cfg.OneNote.MaxSectionWorkers, cfg.OneNote.MaxContentWorkers, cfg.OneNote.MaxResourceWorkers)
 
 		config := &pipelinehandler.Config{
-			MSGraphConfig: &msgraph.Config{
-				ClientID:     cfg.MSGraph.ClientID,
-				ClientSecret: cfg.MSGraph.ClientSecret,
-				TenantID:     cfg.MSGraph.TenantID,
-				OneNoteConcurrency: &msgraph.ConcurrencyConfig{
-					MaxSectionWorkers: cfg.OneNote.MaxSectionWorkers,
-					MaxContentWorkers: cfg.OneNote.MaxContentWorkers,
-				},
-			},
-			UserID:          cfg.MSGraph.UserID, // Pass user ID for application flow
-			DocumentService: documentService,    // Add MongoDB document service
+			MSGraphConfig:    createMSGraphConfig(cfg, checkpointStore),
+			UserID:           cfg.MSGraph.UserID, // Pass user ID for application flow
+			FilesystemConfig: createFilesystemConfig(cfg),
+			DocumentService:  documentService, // Add MongoDB document service
+			Upload:           createUploadConfig(cfg),
 		}
 		return pipelinehandler.New(config)
 	}
@@ -352,11 +548,34 @@ func createPipelineHandlerWithMongoDB(cfg *Config, documentService *mongodb.Docu
 	// Fallback to static files with MongoDB
 	log.Infof("Creating pipeline handler with static files and MongoDB integration")
 	config := &pipelinehandler.Config{
-		DocumentService: documentService,
+		FilesystemConfig: createFilesystemConfig(cfg),
+		DocumentService:  documentService,
+		Upload:           createUploadConfig(cfg),
+		CheckpointStore:  checkpointStore, // Used by clients created from request tokens
 	}
 	return pipelinehandler.New(config)
 }
 
+// createCORSConfig allows any origin without credentials, or only the configured origins with
+// credentials, which browsers need to send the OAuth session cookie from another origin
+func createCORSConfig(cfg *Config) cors.Config {
+	if len(cfg.Server.CORSAllowOrigins) == 0 {
+		return cors.Config{
+			AllowOrigins: []string{"*"},
+			AllowHeaders: []string{"*"},
+			AllowMethods: []string{"*"},
+		}
+	}
+
+	// Browsers ignore wildcards in credentialed responses, so headers and methods are listed
+	return cors.Config{
+		AllowOrigins:     cfg.Server.CORSAllowOrigins,
+		AllowHeaders:     []string{"Authorization", "Content-Type", msgraph.UserSecretHeader},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+		AllowCredentials: true,
+	}
+}
+
 func getMetricsMiddlewareHandler(
 	handlerID string,
 	httpMetricsMiddlewareInstance httpMetricsMiddleware.Middleware,
@@ -378,6 +597,15 @@ func setCmdFlagsFromEnv(command *cobra.Command, cfg *Config) {
 	cfg.MSGraph.ClientSecret = os.Getenv(MSGraphClientSecretEnvVar)
 	cfg.MSGraph.TenantID = os.Getenv(MSGraphTenantIDEnvVar)
 	cfg.MSGraph.UserID = os.Getenv(MSGraphUserIDEnvVar)
+	cfg.MSGraph.MaxRetries = int(env.ParseInt(MSGraphMaxRetriesEnvVar, 5))         // Default: 5 retries
+	cfg.MSGraph.RetryBase = env.ParseDuration(MSGraphRetryBaseEnvVar, time.Second) // Default: 1s
+	cfg.MSGraph.RetryMax = env.ParseDuration(MSGraphRetryMaxEnvVar, time.Minute)   // Default: 1m
+	cfg.MSGraph.Cloud = os.Getenv(MSGraphCloudEnvVar)                              // Default: global
+	cfg.MSGraph.LoginEndpoint = os.Getenv(MSGraphLoginEndpointEnvVar)
+	cfg.MSGraph.GraphEndpoint = os.Getenv(MSGraphGraphEndpointEnvVar)
+	cfg.MSGraph.CertificatePath = os.Getenv(MSGraphCertificatePathEnvVar)
+	cfg.MSGraph.CertificatePassword = os.Getenv(MSGraphCertificatePasswordEnvVar)
+	cfg.MSGraph.FederatedTokenFile = os.Getenv(MSGraphFederatedTokenFileEnvVar)
 
 	// Set OAuth configuration from environment variables
 	cfg.OAuth.RedirectURI = os.Getenv(OAuthRedirectURIEnvVar)
@@ -391,10 +619,33 @@ func setCmdFlagsFromEnv(command *cobra.Command, cfg *Config) {
 			cfg.OAuth.Scopes[i] = strings.TrimSpace(scope)
 		}
 	}
+	cfg.OAuth.TokenEncryptionKey = os.Getenv(OAuthTokenEncryptionKeyEnvVar)
+	cfg.OAuth.TokenDir = os.Getenv(OAuthTokenDirEnvVar) // Default: MongoDB
+	cfg.OAuth.SecureCookies = env.GetOrDefaultBool(OAuthSecureCookiesEnvVar, false)
+	cfg.Server.CORSAllowOrigins = splitCommaSeparated(os.Getenv(CORSAllowOriginsEnvVar))
+	cfg.Server.MaxUploadSize = env.ParseInt(MaxUploadSizeEnvVar, pipelinehandler.DefaultMaxUploadSize)
+	cfg.Server.MaxUploadFileSize = env.ParseInt(MaxUploadFileSizeEnvVar, pipelinehandler.DefaultMaxUploadFileSize)
 
 	// Set OneNote concurrency configuration
-	cfg.OneNote.MaxSectionWorkers = int(env.ParseInt(OneNoteSectionWorkersEnvVar, 5))  // Default: 5 workers
-	cfg.OneNote.MaxContentWorkers = int(env.ParseInt(OneNoteContentWorkersEnvVar, 10)) // Default: 10 workers
+	cfg.OneNote.MaxSectionWorkers = int(env.ParseInt(OneNoteSectionWorkersEnvVar, 5))   // Default: 5 workers
+	cfg.OneNote.MaxContentWorkers = int(env.ParseInt(OneNoteContentWorkersEnvVar, 10))  // Default: 10 workers
+	cfg.OneNote.MaxResourceWorkers = int(env.ParseInt(OneNoteResourceWorkersEnvVar, 5)) // Default: 5 workers, negative disables attachments
+	cfg.OneNote.MaxAttachmentSize = env.ParseInt(OneNoteMaxAttachmentSizeEnvVar, msgraph.DefaultMaxAttachmentSize)
+	cfg.OneNote.PageSize = int(env.ParseInt(OneNotePageSizeEnvVar, 100)) // Default: 100 items per Graph page
+	cfg.OneNote.MaxItems = int(env.ParseInt(OneNoteMaxItemsEnvVar, 0))   // Default: unlimited
+	cfg.OneNote.SyncStateDir = os.Getenv(OneNoteSyncStateDirEnvVar)      // Default: checkpoints kept in memory
+	cfg.OneNote.IncludeNotebooks = splitCommaSeparated(os.Getenv(OneNoteIncludeNotebooksEnvVar))
+	cfg.OneNote.ExcludeNotebooks = splitCommaSeparated(os.Getenv(OneNoteExcludeNotebooksEnvVar))
+	cfg.OneNote.IncludeSections = splitCommaSeparated(os.Getenv(OneNoteIncludeSectionsEnvVar))
+	cfg.OneNote.ExcludeSections = splitCommaSeparated(os.Getenv(OneNoteExcludeSectionsEnvVar))
+	cfg.OneNote.PageTitlePattern = os.Getenv(OneNotePageTitlePatternEnvVar)
+
+	// Set filesystem source configuration from environment variables
+	cfg.Filesystem.Roots = splitCommaSeparated(os.Getenv(FilesystemRootsEnvVar))
+	cfg.Filesystem.Include = splitCommaSeparated(os.Getenv(FilesystemIncludeEnvVar))
+	cfg.Filesystem.Exclude = splitCommaSeparated(os.Getenv(FilesystemExcludeEnvVar))
+	cfg.Filesystem.MaxDepth = int(env.ParseInt(FilesystemMaxDepthEnvVar, 0)) // Default: unlimited
+	cfg.Filesystem.SymlinkPolicy = env.GetOrDefault(FilesystemSymlinkPolicyEnvVar, string(filesystem.SymlinkPolicySkip))
 
 	// Set MongoDB configuration from environment variables
 	// No default values - all MongoDB configuration must be explicitly provided
@@ -403,6 +654,27 @@ func setCmdFlagsFromEnv(command *cobra.Command, cfg *Config) {
 	cfg.MongoDB.Username = os.Getenv(MongoDBUsernameEnvVar)
 	cfg.MongoDB.Password = os.Getenv(MongoDBPasswordEnvVar)
 	cfg.MongoDB.AuthSource = os.Getenv(MongoDBAuthSourceEnvVar)
+	cfg.MongoDB.StoreMode = env.GetOrDefault(MongoDBStoreModeEnvVar, string(mongodb.StoreModeUpsert))
+	cfg.MongoDB.DocumentTTL = env.ParseDuration(MongoDBDocumentTTLEnvVar, 0) // Default: documents never expire
+
+	// Set retention configuration from environment variables
+	policies, err := mongodb.ParseRetentionPolicies(os.Getenv(RetentionPoliciesEnvVar))
+	if err != nil {
+		log.Fatal("error parsing retention policies from environment", "err", err)
+	}
+	cfg.Retention.Policies = policies
+	cfg.Retention.Interval = env.ParseDuration(RetentionIntervalEnvVar, 0) // Default: retention only runs on demand
+}
+
+// splitCommaSeparated splits a comma-separated value into trimmed, non-empty entries
+func splitCommaSeparated(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func testStatusCodeAlertHandler(c *gin.Context) {
@@ -10,6 +10,25 @@ type DocumentCollection struct {
 	FetchedAt     time.Time  `json:"fetched_at"`
 	SchemaVersion string     `json:"schema_version"`
 	Documents     []Document `json:"documents"`
+	Errors        []Issue    `json:"errors"`   // Items that could not be extracted
+	Warnings      []Issue    `json:"warnings"` // Items that were skipped or extracted incompletely
+}
+
+// Collection statuses derived from the errors of a collection
+const (
+	CollectionStatusComplete = "complete" // Every item was extracted
+	CollectionStatusPartial  = "partial"  // Some items could not be extracted
+	CollectionStatusFailed   = "failed"   // No documents were extracted because of errors
+)
+
+// Issue describes a problem with a single item during extraction
+type Issue struct {
+	ItemID     string `json:"item_id,omitempty"`
+	ItemType   string `json:"item_type"`   // "section", "page", "attachment", etc.
+	Stage      string `json:"stage"`       // Extraction step, e.g. "list_pages" or "fetch_content"
+	ErrorClass string `json:"error_class"` // "throttled", "not_found", "unauthorized", "timeout", etc.
+	Retryable  bool   `json:"retryable"`   // True if running the extraction again may succeed
+	Message    string `json:"message"`
 }
 
 // Document represents a single document from any source
@@ -35,6 +54,8 @@ func NewDocumentCollection(source string) *DocumentCollection {
 		FetchedAt:     time.Now(),
 		SchemaVersion: "v1",
 		Documents:     make([]Document, 0),
+		Errors:        make([]Issue, 0),
+		Warnings:      make([]Issue, 0),
 	}
 }
 
@@ -43,6 +64,28 @@ func (dc *DocumentCollection) AddDocument(doc Document) {
 	dc.Documents = append(dc.Documents, doc)
 }
 
+// AddError records an item that could not be extracted
+func (dc *DocumentCollection) AddError(issue Issue) {
+	dc.Errors = append(dc.Errors, issue)
+}
+
+// AddWarning records an item that was skipped or extracted incompletely
+func (dc *DocumentCollection) AddWarning(issue Issue) {
+	dc.Warnings = append(dc.Warnings, issue)
+}
+
+// Status returns whether the collection is complete, partial or failed
+func (dc *DocumentCollection) Status() string {
+	switch {
+	case len(dc.Errors) == 0:
+		return CollectionStatusComplete
+	case len(dc.Documents) == 0:
+		return CollectionStatusFailed
+	default:
+		return CollectionStatusPartial
+	}
+}
+
 // GetDocumentCount returns the number of documents in the collection
 func (dc *DocumentCollection) GetDocumentCount() int {
 	return len(dc.Documents)
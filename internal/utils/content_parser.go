@@ -2,7 +2,6 @@ package utils
 
 import (
 	"encoding/json"
-	"html"
 	"regexp"
 	"strings"
 )
@@ -48,8 +47,9 @@ func OneNoteHTMLToJSON(htmlContent string) (map[string]interface{}, error) {
 		}, nil
 	}
 
-	// Extract text content from HTML
-	textContent := HTMLToText(htmlContent)
+	// Extract text content and links from HTML
+	parsed := ParseHTML(htmlContent)
+	textContent := parsed.Text
 
 	// Extract structured data from OneNote HTML
 	sections := extractOneNoteSections(htmlContent)
@@ -60,7 +60,8 @@ func OneNoteHTMLToJSON(htmlContent string) (map[string]interface{}, error) {
 		"sections":        sections,
 		"has_images":      hasImages(htmlContent),
 		"has_tables":      hasTables(htmlContent),
-		"has_links":       hasLinks(htmlContent),
+		"has_links":       len(parsed.Links) > 0,
+		"links":           parsed.Links,
 		"word_count":      countWords(textContent),
 		"character_count": len(textContent),
 	}
@@ -147,24 +148,9 @@ func MarkdownToJSON(markdownContent string) (map[string]interface{}, error) {
 	return result, nil
 }
 
-// HTMLToText extracts clean text from HTML content
+// HTMLToText extracts clean text from HTML content, keeping block structure as line breaks
 func HTMLToText(htmlContent string) string {
-	if htmlContent == "" {
-		return ""
-	}
-
-	// Remove HTML tags
-	htmlTagRe := regexp.MustCompile(`<[^>]*>`)
-	plainText := htmlTagRe.ReplaceAllString(htmlContent, "")
-
-	// Decode HTML entities
-	plainText = html.UnescapeString(plainText)
-
-	// Clean up whitespace
-	plainText = strings.TrimSpace(plainText)
-	plainText = regexp.MustCompile(`\s+`).ReplaceAllString(plainText, " ")
-
-	return plainText
+	return ParseHTML(htmlContent).Text
 }
 
 // XMLToText extracts clean text from XML content
@@ -243,25 +229,28 @@ func isJSON(content string) bool {
 func extractOneNoteSections(htmlContent string) []map[string]interface{} {
 	sections := []map[string]interface{}{}
 
-	// Extract sections based on common OneNote patterns
-	sectionRe := regexp.MustCompile(`<div[^>]*>(.*?)</div>`)
-	matches := sectionRe.FindAllStringSubmatch(htmlContent, -1)
-
-	for i, match := range matches {
-		if len(match) > 1 {
-			sectionText := HTMLToText(match[1])
-			if strings.TrimSpace(sectionText) != "" {
-				sections = append(sections, map[string]interface{}{
-					"index":   i,
-					"content": sectionText,
-				})
-			}
-		}
+	// Each OneNote outline (a positioned top-level div) forms a section
+	for i, outline := range ParseOneNotePage(htmlContent).Outlines {
+		var lines []string
+		collectOutlineText(outline.Items, &lines)
+		sections = append(sections, map[string]interface{}{
+			"index":   i,
+			"content": strings.Join(lines, "\n"),
+		})
 	}
 
 	return sections
 }
 
+func collectOutlineText(items []OneNoteOutlineItem, lines *[]string) {
+	for _, item := range items {
+		if item.Text != "" {
+			*lines = append(*lines, item.Text)
+		}
+		collectOutlineText(item.Children, lines)
+	}
+}
+
 func extractParagraphs(textContent string) []string {
 	paragraphs := []string{}
 
@@ -388,10 +377,6 @@ func hasTables(htmlContent string) bool {
 	return regexp.MustCompile(`<table[^>]*>`).MatchString(htmlContent)
 }
 
-func hasLinks(htmlContent string) bool {
-	return regexp.MustCompile(`<a[^>]*>`).MatchString(htmlContent)
-}
-
 func countWords(text string) int {
 	words := strings.Fields(text)
 	return len(words)
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// GenerateDocumentID builds a stable document ID from the document type, source and location.
+// The same file at the same location always yields the same ID across pipeline runs. The
+// source is length-prefixed so no split of the same bytes between source and location collides.
+func GenerateDocumentID(docType, source, location string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s%s", len(source), source, location)))
+	return fmt.Sprintf("%s_%x", docType, hash[:12])
+}
+
+// GenerateVersionHash returns a content hash used to detect changes between document versions
+func GenerateVersionHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return fmt.Sprintf("sha256:%x", hash)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateDocumentID(t *testing.T) {
+	id := GenerateDocumentID("txt", "filesystem", "/data/notes.txt")
+
+	if again := GenerateDocumentID("txt", "filesystem", "/data/notes.txt"); again != id {
+		t.Errorf("Expected a deterministic ID, got '%s' and '%s'", id, again)
+	}
+	if !strings.HasPrefix(id, "txt_") || len(id) != len("txt_")+24 {
+		t.Errorf("Expected 'txt_' followed by 24 hex characters, got '%s'", id)
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		location string
+	}{
+		{name: "different location", source: "filesystem", location: "/data/other.txt"},
+		{name: "different source", source: "upload", location: "/data/notes.txt"},
+		{name: "separator moved into source", source: "filesystem\x00", location: "/data/notes.txt"},
+		{name: "separator moved into location", source: "filesystem", location: "\x00/data/notes.txt"},
+		{name: "bytes shifted between source and location", source: "filesystem/data", location: "/notes.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if other := GenerateDocumentID("txt", tt.source, tt.location); other == id {
+				t.Errorf("Expected a different ID for source %q and location %q", tt.source, tt.location)
+			}
+		})
+	}
+
+	if GenerateDocumentID("a", "s\x00", "l") == GenerateDocumentID("a", "s", "\x00l") {
+		t.Error("Expected the source/location split to be part of the ID")
+	}
+}
+
+func TestGenerateVersionHash(t *testing.T) {
+	hash := GenerateVersionHash([]byte("content"))
+	if hash != GenerateVersionHash([]byte("content")) {
+		t.Error("Expected a deterministic version hash")
+	}
+	if hash == GenerateVersionHash([]byte("content changed")) {
+		t.Error("Expected changed content to change the version hash")
+	}
+	if !strings.HasPrefix(hash, "sha256:") {
+		t.Errorf("Expected a 'sha256:' prefix, got '%s'", hash)
+	}
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Highlight markers wrapped around matched terms in snippets
+const (
+	HighlightStart = "<em>"
+	HighlightEnd   = "</em>"
+)
+
+// SearchTerms splits a MongoDB $text query into the plain terms worth highlighting.
+// Quoted phrases are kept together and negated terms ("-term") are dropped.
+func SearchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for i, part := range strings.Split(query, "\"") {
+		// Odd-indexed parts sit between quotes and form a phrase
+		if i%2 == 1 {
+			add(part)
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			if !strings.HasPrefix(word, "-") {
+				add(word)
+			}
+		}
+	}
+
+	return terms
+}
+
+// HighlightSnippets returns up to maxSnippets excerpts of text around occurrences of terms,
+// each extending radius runes either side of the match, with matches wrapped in highlight markers.
+// The text is HTML-escaped, so the markers are the only markup in a snippet and it is safe to render.
+func HighlightSnippets(text string, terms []string, maxSnippets, radius int) []string {
+	if text == "" || len(terms) == 0 || maxSnippets <= 0 {
+		return nil
+	}
+
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lower-casing changed the rune count, so offsets would not line up
+		lower = runes
+	}
+
+	type match struct{ start, end int }
+	var matches []match
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			end := i + len(termRunes)
+			if string(lower[i:end]) == term && isWordBoundary(lower, i-1) && isWordBoundary(lower, end) {
+				matches = append(matches, match{i, i + len(termRunes)})
+				i += len(termRunes) - 1
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var snippets []string
+	for i := 0; i < len(matches) && len(snippets) < maxSnippets; {
+		start := max(matches[i].start-radius, 0)
+		end := min(matches[i].end+radius, len(runes))
+
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("…")
+		}
+		cursor := start
+		for ; i < len(matches) && matches[i].start < end; i++ {
+			if matches[i].start < cursor {
+				continue
+			}
+			b.WriteString(html.EscapeString(string(runes[cursor:matches[i].start])))
+			b.WriteString(HighlightStart)
+			b.WriteString(html.EscapeString(string(runes[matches[i].start:matches[i].end])))
+			b.WriteString(HighlightEnd)
+			cursor = matches[i].end
+			end = min(max(end, cursor), len(runes))
+		}
+		b.WriteString(html.EscapeString(string(runes[cursor:end])))
+		if end < len(runes) {
+			b.WriteString("…")
+		}
+
+		snippets = append(snippets, strings.Join(strings.Fields(b.String()), " "))
+	}
+
+	return snippets
+}
+
+// isWordBoundary reports whether the rune at index is outside the text or not part of a word
+func isWordBoundary(runes []rune, index int) bool {
+	if index < 0 || index >= len(runes) {
+		return true
+	}
+	return !unicode.IsLetter(runes[index]) && !unicode.IsDigit(runes[index])
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	terms := SearchTerms(`Quarterly "due date" -draft report quarterly`)
+	expected := []string{"quarterly", "due date", "report"}
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("SearchTerms() = %v, expected %v", terms, expected)
+	}
+}
+
+func TestHighlightSnippets(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		expected []string
+	}{
+		{
+			name:     "highlights whole words",
+			text:     "The quarterly report is due",
+			terms:    []string{"report"},
+			expected: []string{"The quarterly <em>report</em> is due"},
+		},
+		{
+			name:     "skips matches inside longer words",
+			text:     "reporting on the report",
+			terms:    []string{"report"},
+			expected: []string{"reporting on the <em>report</em>"},
+		},
+		{
+			name:     "skips matches at the start of longer words",
+			text:     "reports",
+			terms:    []string{"report"},
+			expected: nil,
+		},
+		{
+			name:     "escapes markup in the text",
+			text:     `<img src=x onerror="alert(1)"> report & <b>notes</b>`,
+			terms:    []string{"report"},
+			expected: []string{`&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <em>report</em> &amp; &lt;b&gt;notes&lt;/b&gt;`},
+		},
+		{
+			name:     "escapes markup in the match",
+			text:     "a <b> b",
+			terms:    []string{"<b>"},
+			expected: []string{"a <em>&lt;b&gt;</em> b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if snippets := HighlightSnippets(tt.text, tt.terms, 3, 40); !reflect.DeepEqual(snippets, tt.expected) {
+				t.Errorf("HighlightSnippets() = %q, expected %q", snippets, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHighlightSnippets_Radius(t *testing.T) {
+	snippets := HighlightSnippets("one two three four five six seven", []string{"four"}, 1, 6)
+	expected := []string{"…three <em>four</em> five …"}
+	if !reflect.DeepEqual(snippets, expected) {
+		t.Errorf("HighlightSnippets() = %q, expected %q", snippets, expected)
+	}
+}
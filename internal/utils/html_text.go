@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLLink is a hyperlink found in HTML content
+type HTMLLink struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// HTMLContent is the readable text and link targets extracted from HTML
+type HTMLContent struct {
+	Text  string     `json:"text"`
+	Links []HTMLLink `json:"links,omitempty"`
+}
+
+// Elements whose content is never visible text
+var skippedHTMLElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+}
+
+// Elements rendered as separate paragraphs, followed by a blank line
+var paragraphHTMLElements = map[atom.Atom]bool{
+	atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Dl: true, atom.Figure: true, atom.Hr: true,
+}
+
+// Elements rendered on lines of their own
+var blockHTMLElements = map[atom.Atom]bool{
+	atom.Div: true, atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+	atom.Nav: true, atom.Aside: true, atom.Main: true, atom.Address: true, atom.Details: true,
+	atom.Summary: true, atom.Figcaption: true, atom.Fieldset: true, atom.Legend: true, atom.Form: true,
+	atom.Dt: true, atom.Dd: true, atom.Caption: true,
+}
+
+// ParseHTML parses HTML and returns its readable text along with the links it contains.
+// Head, script and style content is dropped, paragraphs and list items are kept on their
+// own lines, and table rows are rendered as lines of cells separated by " | ".
+func ParseHTML(htmlContent string) HTMLContent {
+	if strings.TrimSpace(htmlContent) == "" {
+		return HTMLContent{}
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		// html.Parse only fails on reader errors, which a strings.Reader never returns
+		return HTMLContent{Text: strings.Join(strings.Fields(htmlContent), " ")}
+	}
+
+	r := &htmlRenderer{w: &textWriter{}, seenLinks: make(map[string]bool)}
+	r.render(doc)
+
+	return HTMLContent{Text: r.w.String(), Links: r.links}
+}
+
+// htmlRenderer walks a parsed HTML tree and writes its text
+type htmlRenderer struct {
+	w         *textWriter
+	lists     []*listState
+	pre       int
+	links     []HTMLLink
+	seenLinks map[string]bool
+}
+
+// listState numbers the items of an open list
+type listState struct {
+	ordered bool
+	next    int
+}
+
+func (r *htmlRenderer) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if r.pre > 0 {
+			r.w.write(n.Data)
+		} else {
+			r.w.text(n.Data)
+		}
+		return
+	case html.DocumentNode:
+		r.renderChildren(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if skippedHTMLElements[n.DataAtom] {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		r.w.breakLine(1)
+	case atom.Table:
+		r.renderTable(n)
+	case atom.Ul, atom.Ol:
+		r.renderList(n)
+	case atom.Li:
+		r.renderListItem(n)
+	case atom.A:
+		r.collectLink(n)
+		r.renderChildren(n)
+	case atom.Pre:
+		r.w.breakLine(2)
+		r.pre++
+		r.renderChildren(n)
+		r.pre--
+		r.w.breakLine(2)
+	default:
+		switch {
+		case paragraphHTMLElements[n.DataAtom]:
+			r.w.breakLine(2)
+			r.renderChildren(n)
+			r.w.breakLine(2)
+		case blockHTMLElements[n.DataAtom]:
+			r.w.breakLine(1)
+			r.renderChildren(n)
+			r.w.breakLine(1)
+		default:
+			r.renderChildren(n)
+		}
+	}
+}
+
+func (r *htmlRenderer) renderChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		r.render(child)
+	}
+}
+
+// renderList renders a list as a paragraph, or as part of the enclosing item when nested
+func (r *htmlRenderer) renderList(n *html.Node) {
+	spacing := 2
+	if len(r.lists) > 0 {
+		spacing = 1
+	}
+
+	list := &listState{ordered: n.DataAtom == atom.Ol, next: 1}
+	if start, err := strconv.Atoi(htmlAttr(n, "start")); err == nil {
+		list.next = start
+	}
+
+	r.w.breakLine(spacing)
+	r.lists = append(r.lists, list)
+	r.renderChildren(n)
+	r.lists = r.lists[:len(r.lists)-1]
+	r.w.breakLine(spacing)
+}
+
+// renderListItem renders a list item on its own line, indented by nesting depth
+func (r *htmlRenderer) renderListItem(n *html.Node) {
+	marker := "- "
+	depth := 0
+	if len(r.lists) > 0 {
+		list := r.lists[len(r.lists)-1]
+		depth = len(r.lists) - 1
+		if list.ordered {
+			marker = strconv.Itoa(list.next) + ". "
+			list.next++
+		}
+	}
+
+	r.w.breakLine(1)
+	r.w.write(strings.Repeat("  ", depth) + marker)
+	r.renderChildren(n)
+	r.w.breakLine(1)
+}
+
+// renderTable renders each table row on its own line with cells separated by " | "
+func (r *htmlRenderer) renderTable(n *html.Node) {
+	r.w.breakLine(2)
+
+	for _, row := range tableRows(n) {
+		var cells []string
+		hasText := false
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+				continue
+			}
+
+			cellRenderer := &htmlRenderer{w: &textWriter{}, seenLinks: r.seenLinks}
+			cellRenderer.renderChildren(cell)
+			r.links = append(r.links, cellRenderer.links...)
+
+			text := strings.Join(strings.Fields(cellRenderer.w.String()), " ")
+			hasText = hasText || text != ""
+			cells = append(cells, text)
+		}
+
+		if hasText {
+			r.w.breakLine(1)
+			r.w.write(strings.Join(cells, " | "))
+			r.w.breakLine(1)
+		}
+	}
+
+	r.w.breakLine(2)
+}
+
+// tableRows returns the rows of a table, including those in thead, tbody and tfoot sections
+func tableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+	for child := table.FirstChild; child != nil; child = child.NextSibling {
+		switch child.DataAtom {
+		case atom.Tr:
+			rows = append(rows, child)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			rows = append(rows, tableRows(child)...)
+		}
+	}
+	return rows
+}
+
+// collectLink records the target of an anchor, ignoring in-page and script links
+func (r *htmlRenderer) collectLink(n *html.Node) {
+	href := strings.TrimSpace(htmlAttr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return
+	}
+	if r.seenLinks[href] {
+		return
+	}
+	r.seenLinks[href] = true
+
+	r.links = append(r.links, HTMLLink{
+		Text: strings.Join(strings.Fields(nodeText(n)), " "),
+		URL:  href,
+	})
+}
+
+// htmlAttr returns the value of an attribute, or "" when it is not set
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// nodeText returns the concatenated text below a node
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(nodeText(child))
+	}
+	return b.String()
+}
+
+// textWriter accumulates text, collapsing whitespace and deferring line breaks
+// until more text follows so that blocks never produce leading or repeated blank lines
+type textWriter struct {
+	b        strings.Builder
+	newlines int  // line breaks owed before the next text
+	space    bool // a space is owed before the next text on the current line
+}
+
+// breakLine ends the current line, leaving n-1 blank lines before the next text
+func (w *textWriter) breakLine(n int) {
+	w.newlines = max(w.newlines, n)
+	w.space = false
+}
+
+// write appends s verbatim after any owed line break or space
+func (w *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+
+	if w.b.Len() > 0 {
+		if w.newlines > 0 {
+			w.b.WriteString(strings.Repeat("\n", w.newlines))
+		} else if w.space && !strings.HasSuffix(w.b.String(), " ") {
+			w.b.WriteByte(' ')
+		}
+	}
+
+	w.newlines = 0
+	w.space = false
+	w.b.WriteString(s)
+}
+
+// text appends s with runs of whitespace collapsed to single spaces
+func (w *textWriter) text(s string) {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" && w.newlines == 0 {
+			w.space = true
+		}
+		return
+	}
+
+	leading := strings.TrimLeftFunc(s, unicode.IsSpace) != s
+	if leading && w.newlines == 0 {
+		w.space = true
+	}
+	w.write(strings.Join(words, " "))
+
+	if strings.TrimRightFunc(s, unicode.IsSpace) != s {
+		w.space = true
+	}
+}
+
+// String returns the accumulated text without trailing whitespace
+func (w *textWriter) String() string {
+	return strings.TrimRightFunc(w.b.String(), unicode.IsSpace)
+}
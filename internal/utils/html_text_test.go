@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		name          string
+		html          string
+		expectedText  string
+		expectedLinks []HTMLLink
+	}{
+		{
+			name:         "empty",
+			html:         "  \n ",
+			expectedText: "",
+		},
+		{
+			name:         "paragraphs and whitespace",
+			html:         "<h1>Title</h1><p>First   paragraph\n with <b>bold</b> text.</p><p>Second</p>",
+			expectedText: "Title\n\nFirst paragraph with bold text.\n\nSecond",
+		},
+		{
+			name:         "blocks and line breaks",
+			html:         "<div>One</div><div>Two<br>Three</div>",
+			expectedText: "One\nTwo\nThree",
+		},
+		{
+			name:         "skipped elements",
+			html:         "<html><head><title>Hidden</title><style>p { color: red }</style></head><body><script>alert(1)</script><p>Visible</p><noscript>Enable scripts</noscript></body></html>",
+			expectedText: "Visible",
+		},
+		{
+			name:         "unordered list",
+			html:         "<p>Items:</p><ul><li>Apples</li><li>Pears</li></ul><p>Done</p>",
+			expectedText: "Items:\n\n- Apples\n- Pears\n\nDone",
+		},
+		{
+			name:         "ordered list with start",
+			html:         `<ol start="3"><li>Third</li><li>Fourth</li></ol>`,
+			expectedText: "3. Third\n4. Fourth",
+		},
+		{
+			name:         "nested lists",
+			html:         "<ul><li>Fruit<ol><li>Apples</li><li>Pears</li></ol></li><li>Vegetables</li></ul>",
+			expectedText: "- Fruit\n  1. Apples\n  2. Pears\n- Vegetables",
+		},
+		{
+			name:         "table",
+			html:         "<p>Totals</p><table><thead><tr><th>Name</th><th>Count</th></tr></thead><tbody><tr><td>Apples</td><td> 3 </td></tr><tr><td></td><td></td></tr><tr><td>Pears</td><td>5</td></tr></tbody></table><p>End</p>",
+			expectedText: "Totals\n\nName | Count\nApples | 3\nPears | 5\n\nEnd",
+		},
+		{
+			name:         "preformatted text",
+			html:         "<p>Code:</p><pre>if x {\n    y()\n}</pre><p>After</p>",
+			expectedText: "Code:\n\nif x {\n    y()\n}\n\nAfter",
+		},
+		{
+			name:         "links",
+			html:         `<p>See <a href="https://example.com/a">the  docs</a>, <a href="#top">top</a>, <a href="javascript:void(0)">script</a> and <a href="https://example.com/a">again</a>.</p>`,
+			expectedText: "See the docs, top, script and again.",
+			expectedLinks: []HTMLLink{
+				{Text: "the docs", URL: "https://example.com/a"},
+			},
+		},
+		{
+			name:         "links in table cells",
+			html:         `<table><tr><td><a href="https://example.com/b">B</a></td></tr></table>`,
+			expectedText: "B",
+			expectedLinks: []HTMLLink{
+				{Text: "B", URL: "https://example.com/b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := ParseHTML(tt.html)
+			if content.Text != tt.expectedText {
+				t.Errorf("ParseHTML() text = %q, expected %q", content.Text, tt.expectedText)
+			}
+			if !reflect.DeepEqual(content.Links, tt.expectedLinks) {
+				t.Errorf("ParseHTML() links = %v, expected %v", content.Links, tt.expectedLinks)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// OneNote data-tag values marking checkable to-do items; a ":completed" suffix marks them checked
+const (
+	OneNoteToDoTag         = "to-do"
+	oneNoteCompletedSuffix = ":completed"
+)
+
+var oneNoteResourceIDRe = regexp.MustCompile(`/resources/([^/?#]+)`)
+
+// OneNoteToDo is a checkbox item on a OneNote page
+type OneNoteToDo struct {
+	Text      string `json:"text"`
+	Completed bool   `json:"completed"`
+	Tag       string `json:"tag"` // e.g. "to-do" or "to-do-priority-1"
+}
+
+// OneNoteTag is a non to-do note tag such as "important" or "question" and the text it marks
+type OneNoteTag struct {
+	Tag       string `json:"tag"`
+	Text      string `json:"text"`
+	Completed bool   `json:"completed,omitempty"`
+}
+
+// OneNoteTable is a table on a OneNote page as rows of cell text
+type OneNoteTable struct {
+	Rows [][]string `json:"rows"`
+}
+
+// OneNoteOutlineItem is a paragraph, heading or list item of a page outline.
+// Nested list items are kept as children.
+type OneNoteOutlineItem struct {
+	Text     string               `json:"text"`
+	Heading  int                  `json:"heading,omitempty"` // 1-6 for h1-h6
+	Children []OneNoteOutlineItem `json:"children,omitempty"`
+}
+
+// OneNoteOutline is a positioned block of content on a OneNote page
+type OneNoteOutline struct {
+	ID    string               `json:"id,omitempty"`
+	Items []OneNoteOutlineItem `json:"items"`
+}
+
+// OneNoteResource references an image or file attachment stored as a Graph resource
+type OneNoteResource struct {
+	Kind        string `json:"kind"` // "image" or "attachment"
+	ResourceID  string `json:"resource_id,omitempty"`
+	URL         string `json:"url"`
+	FullResURL  string `json:"full_res_url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Alt         string `json:"alt,omitempty"`
+}
+
+// OneNotePage is the structured content of a OneNote page
+type OneNotePage struct {
+	ToDos       []OneNoteToDo     `json:"todos"`
+	Tags        []OneNoteTag      `json:"tags"`
+	Tables      []OneNoteTable    `json:"tables"`
+	Outlines    []OneNoteOutline  `json:"outlines"`
+	Images      []OneNoteResource `json:"images"`
+	Attachments []OneNoteResource `json:"attachments"`
+}
+
+// ParseOneNotePage extracts to-dos, tags, tables, outlines and embedded resources from
+// the HTML returned by the Graph OneNote page content endpoint
+func ParseOneNotePage(htmlContent string) OneNotePage {
+	page := OneNotePage{
+		ToDos:       []OneNoteToDo{},
+		Tags:        []OneNoteTag{},
+		Tables:      []OneNoteTable{},
+		Outlines:    []OneNoteOutline{},
+		Images:      []OneNoteResource{},
+		Attachments: []OneNoteResource{},
+	}
+	if strings.TrimSpace(htmlContent) == "" {
+		return page
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return page
+	}
+
+	walkElements(doc, func(n *html.Node) {
+		if tags := htmlAttr(n, "data-tag"); tags != "" {
+			page.addTags(tags, strings.Join(strings.Fields(nodeText(n)), " "))
+		}
+
+		switch n.DataAtom {
+		case atom.Table:
+			page.Tables = append(page.Tables, oneNoteTable(n))
+		case atom.Img:
+			page.Images = append(page.Images, oneNoteImage(n))
+		case atom.Object:
+			if data := htmlAttr(n, "data"); data != "" {
+				page.Attachments = append(page.Attachments, OneNoteResource{
+					Kind:        "attachment",
+					ResourceID:  oneNoteResourceID(data),
+					URL:         data,
+					ContentType: htmlAttr(n, "type"),
+					Name:        htmlAttr(n, "data-attachment"),
+				})
+			}
+		}
+	})
+
+	if body := findElement(doc, atom.Body); body != nil {
+		page.Outlines = oneNoteOutlines(body)
+	}
+
+	return page
+}
+
+// addTags records the comma-separated data-tag values of an element
+func (p *OneNotePage) addTags(tags, text string) {
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		completed := strings.HasSuffix(tag, oneNoteCompletedSuffix)
+		tag = strings.TrimSuffix(tag, oneNoteCompletedSuffix)
+
+		if strings.HasPrefix(tag, OneNoteToDoTag) {
+			p.ToDos = append(p.ToDos, OneNoteToDo{Text: text, Completed: completed, Tag: tag})
+		} else {
+			p.Tags = append(p.Tags, OneNoteTag{Tag: tag, Text: text, Completed: completed})
+		}
+	}
+}
+
+// oneNoteTable returns the cell text of each row of a table
+func oneNoteTable(table *html.Node) OneNoteTable {
+	result := OneNoteTable{Rows: [][]string{}}
+	for _, row := range tableRows(table) {
+		var cells []string
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				cells = append(cells, strings.Join(strings.Fields(nodeText(cell)), " "))
+			}
+		}
+		result.Rows = append(result.Rows, cells)
+	}
+	return result
+}
+
+// oneNoteImage describes an img element, preferring the full resolution resource when available
+func oneNoteImage(n *html.Node) OneNoteResource {
+	image := OneNoteResource{
+		Kind:        "image",
+		URL:         htmlAttr(n, "src"),
+		FullResURL:  htmlAttr(n, "data-fullres-src"),
+		ContentType: htmlAttr(n, "data-src-type"),
+		Alt:         htmlAttr(n, "alt"),
+	}
+
+	if image.FullResURL != "" {
+		image.ResourceID = oneNoteResourceID(image.FullResURL)
+		if contentType := htmlAttr(n, "data-fullres-src-type"); contentType != "" {
+			image.ContentType = contentType
+		}
+	}
+	if image.ResourceID == "" {
+		image.ResourceID = oneNoteResourceID(image.URL)
+	}
+
+	return image
+}
+
+// oneNoteResourceID returns the resource ID from a Graph OneNote resource URL
+func oneNoteResourceID(resourceURL string) string {
+	if match := oneNoteResourceIDRe.FindStringSubmatch(resourceURL); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// oneNoteOutlines groups the page body into outlines. Graph renders each outline as a
+// positioned div directly below body; content outside any div is collected in its own outline.
+func oneNoteOutlines(body *html.Node) []OneNoteOutline {
+	outlines := []OneNoteOutline{}
+	loose := -1
+
+	for child := body.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+
+		if child.DataAtom == atom.Div {
+			if items := outlineItems(child); len(items) > 0 {
+				outlines = append(outlines, OneNoteOutline{ID: htmlAttr(child, "data-id"), Items: items})
+			}
+			continue
+		}
+
+		items := outlineNodeItems(child)
+		if len(items) == 0 {
+			continue
+		}
+		if loose < 0 {
+			outlines = append(outlines, OneNoteOutline{})
+			loose = len(outlines) - 1
+		}
+		outlines[loose].Items = append(outlines[loose].Items, items...)
+	}
+
+	return outlines
+}
+
+// outlineItems returns the paragraphs, headings and list items below n
+func outlineItems(n *html.Node) []OneNoteOutlineItem {
+	var items []OneNoteOutlineItem
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		items = append(items, outlineNodeItems(child)...)
+	}
+	return items
+}
+
+// outlineNodeItems returns the outline items of a single element, nesting list items
+func outlineNodeItems(n *html.Node) []OneNoteOutlineItem {
+	if n.Type != html.ElementNode || skippedHTMLElements[n.DataAtom] {
+		return nil
+	}
+
+	switch n.DataAtom {
+	case atom.P:
+		if text := strings.Join(strings.Fields(nodeText(n)), " "); text != "" {
+			return []OneNoteOutlineItem{{Text: text}}
+		}
+		return nil
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := strings.Join(strings.Fields(nodeText(n)), " "); text != "" {
+			return []OneNoteOutlineItem{{Text: text, Heading: int(n.Data[1] - '0')}}
+		}
+		return nil
+	case atom.Li:
+		return []OneNoteOutlineItem{outlineListItem(n)}
+	case atom.Table, atom.Img, atom.Object:
+		// Reported separately as tables and resources
+		return nil
+	default:
+		return outlineItems(n)
+	}
+}
+
+// outlineListItem returns a list item with its nested lists as children
+func outlineListItem(li *html.Node) OneNoteOutlineItem {
+	var text strings.Builder
+	var children []OneNoteOutlineItem
+
+	for child := li.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.Ul || child.DataAtom == atom.Ol {
+			children = append(children, outlineItems(child)...)
+			continue
+		}
+		text.WriteString(nodeText(child))
+		text.WriteString(" ")
+	}
+
+	return OneNoteOutlineItem{
+		Text:     strings.Join(strings.Fields(text.String()), " "),
+		Children: children,
+	}
+}
+
+// walkElements calls fn for every element below n in document order
+func walkElements(n *html.Node, fn func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if skippedHTMLElements[child.DataAtom] {
+			continue
+		}
+		fn(child)
+		walkElements(child, fn)
+	}
+}
+
+// findElement returns the first element of the given type below n
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == a {
+			return child
+		}
+		if found := findElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"strings"
+)
+
+// Diff operations reported by DiffLines
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffCells bounds the work of diffing the changed region (lines removed times lines added);
+// larger inputs fall back to a full replace. Memory stays linear in the number of lines.
+const maxDiffCells = 4_000_000
+
+// DiffLine is a single line of a line-based diff
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines computes a line-based diff turning oldText into newText
+func DiffLines(oldText, newText string) []DiffLine {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	// Trim the common prefix and suffix so the LCS table only covers the changed region
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for _, line := range oldLines[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	diff = append(diff, diffMiddle(oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix])...)
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+
+	return diff
+}
+
+// diffMiddle diffs the changed region with Hirschberg's linear-space longest common subsequence
+func diffMiddle(oldLines, newLines []string) []DiffLine {
+	var diff []DiffLine
+
+	if len(oldLines)*len(newLines) > maxDiffCells {
+		for _, line := range oldLines {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range newLines {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// Compare lines by number rather than string
+	ids := make(map[string]int)
+	lineIDs := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+
+	d := &lineDiff{oldLines: oldLines, newLines: newLines}
+	d.diff(lineIDs(oldLines), lineIDs(newLines), 0, 0)
+	return d.result
+}
+
+// lineDiff collects the operations of a Hirschberg diff
+type lineDiff struct {
+	oldLines []string
+	newLines []string
+	result   []DiffLine
+}
+
+// diff appends the operations turning a into b, where a and b start at oldStart and newStart
+func (d *lineDiff) diff(a, b []int, oldStart, newStart int) {
+	switch {
+	case len(a) == 0:
+		d.insert(newStart, newStart+len(b))
+		return
+	case len(b) == 0:
+		d.delete(oldStart, oldStart+len(a))
+		return
+	case len(a) == 1:
+		for j, id := range b {
+			if id == a[0] {
+				d.insert(newStart, newStart+j)
+				d.result = append(d.result, DiffLine{Op: DiffEqual, Text: d.oldLines[oldStart]})
+				d.insert(newStart+j+1, newStart+len(b))
+				return
+			}
+		}
+		d.delete(oldStart, oldStart+1)
+		d.insert(newStart, newStart+len(b))
+		return
+	}
+
+	// Split a in half and b where the LCS of the two halves is longest
+	mid := len(a) / 2
+	forward := lcsPrefixLengths(a[:mid], b)
+	backward := lcsSuffixLengths(a[mid:], b)
+	split, best := 0, -1
+	for j := 0; j <= len(b); j++ {
+		if length := forward[j] + backward[j]; length > best {
+			split, best = j, length
+		}
+	}
+
+	d.diff(a[:mid], b[:split], oldStart, newStart)
+	d.diff(a[mid:], b[split:], oldStart+mid, newStart+split)
+}
+
+func (d *lineDiff) insert(from, to int) {
+	for _, line := range d.newLines[from:to] {
+		d.result = append(d.result, DiffLine{Op: DiffInsert, Text: line})
+	}
+}
+
+func (d *lineDiff) delete(from, to int) {
+	for _, line := range d.oldLines[from:to] {
+		d.result = append(d.result, DiffLine{Op: DiffDelete, Text: line})
+	}
+}
+
+// lcsPrefixLengths returns, for every j, the LCS length of a and b[:j] using two rows
+func lcsPrefixLengths(a, b []int) []int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for _, id := range a {
+		for j := range b {
+			if id == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// lcsSuffixLengths returns, for every j, the LCS length of a and b[j:] using two rows
+func lcsSuffixLengths(a, b []int) []int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				curr[j] = prev[j+1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j+1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// splitLines splits text into lines, treating empty text as having no lines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
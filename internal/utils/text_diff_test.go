@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyDiff rebuilds the old and new text from a diff
+func applyDiff(diff []DiffLine) (string, string) {
+	var oldLines, newLines []string
+	for _, line := range diff {
+		if line.Op != DiffInsert {
+			oldLines = append(oldLines, line.Text)
+		}
+		if line.Op != DiffDelete {
+			newLines = append(newLines, line.Text)
+		}
+	}
+	return strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+}
+
+// lcsLength is the quadratic reference the diff must match
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected []DiffLine
+	}{
+		{
+			name:     "identical",
+			oldText:  "a\nb",
+			newText:  "a\nb",
+			expected: []DiffLine{{DiffEqual, "a"}, {DiffEqual, "b"}},
+		},
+		{
+			name:     "changed line",
+			oldText:  "a\nb\nc",
+			newText:  "a\nx\nc",
+			expected: []DiffLine{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "x"}, {DiffEqual, "c"}},
+		},
+		{
+			name:     "inserted and deleted lines",
+			oldText:  "a\nb\nc\nd",
+			newText:  "b\nc\ne\nd",
+			expected: []DiffLine{{DiffDelete, "a"}, {DiffEqual, "b"}, {DiffEqual, "c"}, {DiffInsert, "e"}, {DiffEqual, "d"}},
+		},
+		{
+			name:     "from empty",
+			oldText:  "",
+			newText:  "a",
+			expected: []DiffLine{{DiffInsert, "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := DiffLines(tt.oldText, tt.newText); !reflect.DeepEqual(diff, tt.expected) {
+				t.Errorf("DiffLines() = %v, expected %v", diff, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffLines_Minimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		lines := make([]string, random.Intn(40))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for i := 0; i < 200; i++ {
+		oldText, newText := randomText(), randomText()
+		diff := DiffLines(oldText, newText)
+
+		gotOld, gotNew := applyDiff(diff)
+		if gotOld != oldText || gotNew != newText {
+			t.Fatalf("Diff of %q and %q does not rebuild the inputs", oldText, newText)
+		}
+
+		equal := 0
+		for _, line := range diff {
+			if line.Op == DiffEqual {
+				equal++
+			}
+		}
+		if expected := lcsLength(splitLines(oldText), splitLines(newText)); equal != expected {
+			t.Fatalf("Diff of %q and %q keeps %d lines, expected %d", oldText, newText, equal, expected)
+		}
+	}
+}
+
+func TestDiffLines_LargeInputFallsBack(t *testing.T) {
+	oldLines := make([]string, 3000)
+	newLines := make([]string, 3000)
+	for i := range oldLines {
+		oldLines[i] = "old " + string(rune('a'+i%26))
+		newLines[i] = "new " + string(rune('a'+i%26))
+	}
+
+	diff := DiffLines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+	if len(diff) != 6000 || diff[0].Op != DiffDelete || diff[5999].Op != DiffInsert {
+		t.Errorf("Expected a full replace beyond the diff limit, got %d lines", len(diff))
+	}
+}
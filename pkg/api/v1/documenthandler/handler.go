@@ -1,8 +1,10 @@
 package documenthandler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,12 +13,14 @@ import (
 
 // Handler handles document operations from MongoDB
 type Handler struct {
-	documentService *mongodb.DocumentService
+	documentService   *mongodb.DocumentService
+	retentionPolicies []mongodb.RetentionPolicy
 }
 
 // Config represents the configuration for the document handler
 type Config struct {
-	DocumentService *mongodb.DocumentService `json:"document_service,omitempty"`
+	DocumentService   *mongodb.DocumentService  `json:"document_service,omitempty"`
+	RetentionPolicies []mongodb.RetentionPolicy `json:"retention_policies,omitempty"`
 }
 
 // New creates a new document handler
@@ -26,7 +30,8 @@ func New(config *Config) *Handler {
 	}
 
 	return &Handler{
-		documentService: config.DocumentService,
+		documentService:   config.DocumentService,
+		retentionPolicies: config.RetentionPolicies,
 	}
 }
 
@@ -35,6 +40,90 @@ func (h *Handler) GetDocuments(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Parse query parameters
+	filter := parseDocumentFilter(c)
+	filter.Cursor = c.Query("cursor")
+
+	// Parse projection
+	if fields := c.Query("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				filter.Fields = append(filter.Fields, field)
+			}
+		}
+	}
+
+	// Set default limit if not specified
+	if filter.Limit == 0 {
+		filter.Limit = 50 // Default to 50 documents
+	}
+
+	page, err := h.documentService.GetDocumentsPage(ctx, filter)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid cursor",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve documents",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"documents": page.Documents,
+		"count":     len(page.Documents),
+		"filter":    filter,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchDocuments runs a ranked full-text search over stored documents
+func (h *Handler) SearchDocuments(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Missing required parameter 'q'",
+			"example": "?q=quarterly report&source=onenote",
+		})
+		return
+	}
+
+	filter := parseDocumentFilter(c)
+
+	// Set default limit if not specified
+	if filter.Limit == 0 {
+		filter.Limit = 20 // Default to 20 results
+	}
+
+	results, err := h.documentService.SearchDocuments(ctx, query, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search documents",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"results": results,
+		"count":   len(results),
+		"filter":  filter,
+	})
+}
+
+// parseDocumentFilter reads the shared document filter and offset pagination query parameters
+func parseDocumentFilter(c *gin.Context) mongodb.DocumentFilter {
 	filter := mongodb.DocumentFilter{}
 
 	if source := c.Query("source"); source != "" {
@@ -71,25 +160,7 @@ func (h *Handler) GetDocuments(c *gin.Context) {
 		}
 	}
 
-	// Set default limit if not specified
-	if filter.Limit == 0 {
-		filter.Limit = 50 // Default to 50 documents
-	}
-
-	documents, err := h.documentService.GetDocuments(ctx, filter)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve documents",
-			"details": err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"documents": documents,
-		"count":     len(documents),
-		"filter":    filter,
-	})
+	return filter
 }
 
 // GetDocumentCollections retrieves stored document collections metadata
@@ -164,7 +235,8 @@ func (h *Handler) GetDocumentStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// DeleteOldDocuments deletes documents older than specified duration
+// DeleteOldDocuments deletes documents older than specified duration, optionally limited to one source.
+// Collection records are pruned of the deleted documents; dry_run=true only reports what would be deleted.
 func (h *Handler) DeleteOldDocuments(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -178,7 +250,7 @@ func (h *Handler) DeleteOldDocuments(c *gin.Context) {
 		return
 	}
 
-	duration, err := time.ParseDuration(durationStr)
+	duration, err := mongodb.ParseRetentionAge(durationStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid duration format",
@@ -188,7 +260,18 @@ func (h *Handler) DeleteOldDocuments(c *gin.Context) {
 		return
 	}
 
-	result, err := h.documentService.DeleteOldDocuments(ctx, duration)
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	source := c.Query("source")
+	if source == "" {
+		source = mongodb.RetentionDefaultSource
+	}
+
+	policies := []mongodb.RetentionPolicy{{Source: source, MaxAge: duration}}
+	report, err := h.documentService.ApplyRetention(ctx, policies, dryRun)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete old documents",
@@ -197,13 +280,206 @@ func (h *Handler) DeleteOldDocuments(c *gin.Context) {
 		return
 	}
 
+	message := "Successfully deleted old documents"
+	if dryRun {
+		message = "Dry run - no documents were deleted"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Successfully deleted old documents",
-		"deleted_count": result.DeletedCount,
+		"message":       message,
+		"deleted_count": report.DeletedDocuments,
 		"older_than":    durationStr,
+		"report":        report,
+	})
+}
+
+// RunRetention applies the configured per-source retention policies, or the policies given in
+// the 'policies' query parameter (e.g. "onenote=90d,static=7d"); dry_run=true only reports
+func (h *Handler) RunRetention(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	policies := h.retentionPolicies
+	if spec := c.Query("policies"); spec != "" {
+		parsed, err := mongodb.ParseRetentionPolicies(spec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid retention policies",
+				"details": err.Error(),
+				"example": "?policies=onenote=90d,static=7d",
+			})
+			return
+		}
+		policies = parsed
+	}
+
+	if len(policies) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No retention policies configured",
+			"example": "?policies=onenote=90d,static=7d",
+		})
+		return
+	}
+
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.documentService.ApplyRetention(ctx, policies, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to apply retention policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDocument returns a single stored document by its document ID
+func (h *Handler) GetDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+	documentID := c.Param("id")
+
+	document, err := h.documentService.GetDocument(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":       "Document not found",
+				"document_id": documentID,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// DeleteDocument removes a single stored document, its version history and its collection references
+func (h *Handler) DeleteDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+	documentID := c.Param("id")
+
+	result, err := h.documentService.DeleteDocument(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":       "Document not found",
+				"document_id": documentID,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully deleted document",
+		"result":  result,
 	})
 }
 
+// GetDocumentCollection returns a stored collection with its documents expanded
+func (h *Handler) GetDocumentCollection(c *gin.Context) {
+	ctx := c.Request.Context()
+	collectionID := c.Param("id")
+
+	collection, err := h.documentService.GetDocumentCollection(ctx, collectionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongodb.ErrInvalidCollectionID):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":         "Invalid collection ID",
+				"collection_id": collectionID,
+			})
+		case errors.Is(err, mongodb.ErrCollectionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":         "Document collection not found",
+				"collection_id": collectionID,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to retrieve document collection",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// GetDocumentVersions lists the current and prior revisions of a stored document
+func (h *Handler) GetDocumentVersions(c *gin.Context) {
+	ctx := c.Request.Context()
+	documentID := c.Param("id")
+
+	versions, err := h.documentService.GetDocumentVersions(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, mongodb.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":       "Document not found",
+				"document_id": documentID,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve document versions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"document_id": documentID,
+		"versions":    versions,
+		"count":       len(versions),
+	})
+}
+
+// DiffDocumentVersions returns a line-based diff between two revisions of a stored document
+func (h *Handler) DiffDocumentVersions(c *gin.Context) {
+	ctx := c.Request.Context()
+	documentID := c.Param("id")
+
+	fromVersion := c.Query("from")
+	if fromVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Missing required parameter 'from'",
+			"example": "?from=sha256:abc...&to=sha256:def... (omit 'to' to compare against the current version)",
+		})
+		return
+	}
+
+	diff, err := h.documentService.DiffDocumentVersions(ctx, documentID, fromVersion, c.Query("to"))
+	if err != nil {
+		if errors.Is(err, mongodb.ErrDocumentNotFound) || errors.Is(err, mongodb.ErrVersionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":       "Document version not found",
+				"document_id": documentID,
+				"details":     err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to diff document versions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
 // GetHealth returns health status of document handler
 func (h *Handler) GetHealth(c *gin.Context) {
 	if h.documentService == nil {
@@ -224,3 +500,21 @@ func (h *Handler) GetHealth(c *gin.Context) {
 func (h *Handler) IsConfigured() bool {
 	return h != nil && h.documentService != nil
 }
+
+// parseDryRun reads the optional dry_run query parameter, writing a 400 response when it is invalid
+func parseDryRun(c *gin.Context) (bool, bool) {
+	value := c.Query("dry_run")
+	if value == "" {
+		return false, true
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid dry_run value",
+			"details": err.Error(),
+		})
+		return false, false
+	}
+	return dryRun, true
+}
@@ -2,6 +2,7 @@ package documenthandler
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +11,9 @@ import (
 	"github.com/ishank09/data-extraction-service/pkg/mongodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // MockDocumentService is a mock for mongodb.DocumentService
@@ -32,6 +36,51 @@ func (m *MockDocumentService) GetDocumentStats(ctx context.Context) (*mongodb.Do
 	return args.Get(0).(*mongodb.DocumentStats), args.Error(1)
 }
 
+// fakeClient serves stored documents from memory for the document lookup and delete routes.
+// Methods not overridden panic through the nil embedded interface.
+type fakeClient struct {
+	mongodb.Interface
+	documents     []bson.M
+	deletedIDs    []string
+	updatedFilter interface{}
+}
+
+func (f *fakeClient) Find(ctx context.Context, collection string, filter interface{}, opts ...*options.FindOptions) (*mongodb.Cursor, error) {
+	documentID := filter.(bson.M)["document_id"]
+	var matches []interface{}
+	for _, doc := range f.documents {
+		if doc["document_id"] == documentID {
+			matches = append(matches, doc)
+		}
+	}
+	return mongodb.NewCursorFromDocuments(matches)
+}
+
+func (f *fakeClient) DeleteMany(ctx context.Context, collection string, filter interface{}) (*mongodb.DeleteResult, error) {
+	documentID := filter.(bson.M)["document_id"].(string)
+	var deleted int64
+	if collection == mongodb.DocumentsCollectionName {
+		remaining := f.documents[:0]
+		for _, doc := range f.documents {
+			if doc["document_id"] == documentID {
+				deleted++
+				continue
+			}
+			remaining = append(remaining, doc)
+		}
+		f.documents = remaining
+		if deleted > 0 {
+			f.deletedIDs = append(f.deletedIDs, documentID)
+		}
+	}
+	return &mongodb.DeleteResult{DeletedCount: deleted}, nil
+}
+
+func (f *fakeClient) UpdateMany(ctx context.Context, collection string, filter interface{}, update interface{}) (*mongodb.UpdateResult, error) {
+	f.updatedFilter = filter
+	return &mongodb.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -149,3 +198,142 @@ func TestHandler_IsConfigured(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetDocument(t *testing.T) {
+	client := &fakeClient{documents: []bson.M{{"document_id": "doc_1", "title": "Notes", "source": "onenote"}}}
+	handler := New(&Config{DocumentService: mongodb.NewDocumentService(client)})
+
+	router := setupRouter()
+	router.GET("/documents/:id", handler.GetDocument)
+
+	tests := []struct {
+		name           string
+		documentID     string
+		expectedStatus int
+	}{
+		{name: "returns a stored document", documentID: "doc_1", expectedStatus: http.StatusOK},
+		{name: "returns not found for an unknown document", documentID: "missing", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/documents/"+tt.documentID, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var document mongodb.StoredDocument
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+				assert.Equal(t, "doc_1", document.DocumentID)
+				assert.Equal(t, "Notes", document.Title)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteDocument(t *testing.T) {
+	client := &fakeClient{documents: []bson.M{{"document_id": "doc_1"}, {"document_id": "doc_2"}}}
+	handler := New(&Config{DocumentService: mongodb.NewDocumentService(client)})
+
+	router := setupRouter()
+	router.DELETE("/documents/:id", handler.DeleteDocument)
+
+	req := httptest.NewRequest(http.MethodDelete, "/documents/doc_1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Result mongodb.DeleteDocumentResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "doc_1", response.Result.DocumentID)
+	assert.Equal(t, int64(1), response.Result.DeletedCount)
+	assert.Equal(t, []string{"doc_1"}, client.deletedIDs)
+	assert.Equal(t, bson.M{"document_ids": "doc_1"}, client.updatedFilter)
+
+	// Deleting it again finds nothing
+	req = httptest.NewRequest(http.MethodDelete, "/documents/doc_1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Len(t, client.documents, 1)
+}
+
+func TestHandler_DiffDocumentVersions_RequiresFrom(t *testing.T) {
+	handler := New(&Config{DocumentService: &mongodb.DocumentService{}})
+
+	router := setupRouter()
+	router.GET("/documents/:id/versions/diff", handler.DiffDocumentVersions)
+
+	req := httptest.NewRequest(http.MethodGet, "/documents/doc_1/versions/diff", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "from")
+}
+
+func TestHandler_GetDocuments_RejectsSkipWithCursor(t *testing.T) {
+	handler := New(&Config{DocumentService: &mongodb.DocumentService{}})
+
+	router := setupRouter()
+	router.GET("/documents", handler.GetDocuments)
+
+	req := httptest.NewRequest(http.MethodGet, "/documents?skip=20&cursor=eyJmIjoiMjAyNC0w", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "skip")
+}
+
+func TestHandler_SearchDocuments_RequiresQuery(t *testing.T) {
+	handler := New(&Config{DocumentService: &mongodb.DocumentService{}})
+
+	router := setupRouter()
+	router.GET("/documents/search", handler.SearchDocuments)
+
+	req := httptest.NewRequest(http.MethodGet, "/documents/search?q=%20", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandler_RunRetention_Validation(t *testing.T) {
+	handler := New(&Config{DocumentService: &mongodb.DocumentService{}})
+
+	router := setupRouter()
+	router.POST("/documents/retention/run", handler.RunRetention)
+	router.DELETE("/documents/cleanup", handler.DeleteOldDocuments)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+	}{
+		{name: "no configured policies", method: http.MethodPost, target: "/documents/retention/run"},
+		{name: "invalid policies", method: http.MethodPost, target: "/documents/retention/run?policies=onenote"},
+		{name: "invalid dry_run", method: http.MethodPost, target: "/documents/retention/run?policies=onenote=90d&dry_run=maybe"},
+		{name: "invalid cleanup duration", method: http.MethodDelete, target: "/documents/cleanup?older_than=soon"},
+		{name: "invalid cleanup dry_run", method: http.MethodDelete, target: "/documents/cleanup?older_than=7d&dry_run=maybe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
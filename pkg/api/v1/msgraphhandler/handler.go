@@ -2,6 +2,11 @@ package msgraphhandler
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -14,8 +19,15 @@ import (
 type Handler struct {
 	msgraphClient msgraph.Interface
 	oauthClient   *msgraph.OAuthClient
+	graphConfig   *msgraph.Config // Cloud and crawl settings for clients created from request tokens
+	tokenVault    *msgraph.TokenVault
+	oauthStates   *msgraph.OAuthStateManager
+	secureCookies bool // Marks the session cookie Secure even when TLS terminates at a proxy
 }
 
+// oauthSessionCookie identifies the browser session that started sign-in
+const oauthSessionCookie = "oauth_session"
+
 // Config represents the configuration for the msgraph handler
 type Config struct {
 	MSGraphConfig *msgraph.Config      `json:"msgraph_config,omitempty"`
@@ -41,6 +53,12 @@ type CallbackRequest struct {
 	State string `json:"state"`
 }
 
+// DeviceAuthorizationRequest polls a started device authorization when DeviceCode is set,
+// and starts a new one otherwise
+type DeviceAuthorizationRequest struct {
+	DeviceCode string `json:"device_code,omitempty"`
+}
+
 // RefreshTokenRequest represents the refresh token request
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token"`
@@ -74,23 +92,38 @@ func New(config *Config) (*Handler, error) {
 
 	// Create OAuth client if OAuth config is provided
 	var oauthClient *msgraph.OAuthClient
+	var oauthStates *msgraph.OAuthStateManager
 	if config.OAuthConfig != nil {
 		oauthClient = msgraph.NewOAuthClient(*config.OAuthConfig)
+		oauthStates = msgraph.NewOAuthStateManager(nil)
 	}
 
 	return &Handler{
 		msgraphClient: graphClient,
 		oauthClient:   oauthClient,
+		graphConfig:   config.MSGraphConfig,
+		oauthStates:   oauthStates,
 	}, nil
 }
 
 // NewWithToken creates a new msgraph handler with delegated authentication (access token flow)
 func NewWithToken(accessToken string) (*Handler, error) {
+	return NewWithTokenAndConfig(accessToken, nil)
+}
+
+// NewWithTokenAndConfig creates a new msgraph handler with delegated authentication, using the
+// cloud and crawl settings of graphConfig (nil uses the global cloud and defaults)
+func NewWithTokenAndConfig(accessToken string, graphConfig *msgraph.Config) (*Handler, error) {
 	if accessToken == "" {
 		return nil, nil
 	}
 
-	graphClient, err := msgraph.NewClientWithToken(accessToken)
+	var config msgraph.Config
+	if graphConfig != nil {
+		config = *graphConfig
+	}
+
+	graphClient, err := msgraph.NewClientWithTokenAndConfig(accessToken, config)
 	if err != nil {
 		return nil, err
 	}
@@ -111,9 +144,26 @@ func NewWithClient(client msgraph.Interface) *Handler {
 func NewWithOAuth(oauthConfig msgraph.OAuthConfig) *Handler {
 	return &Handler{
 		oauthClient: msgraph.NewOAuthClient(oauthConfig),
+		oauthStates: msgraph.NewOAuthStateManager(nil),
 	}
 }
 
+// SetTokenVault makes the OAuth callback store tokens server-side instead of returning them
+func (h *Handler) SetTokenVault(vault *msgraph.TokenVault) {
+	h.tokenVault = vault
+}
+
+// SetSecureCookies marks the OAuth session cookie Secure on every request, for servers behind
+// a TLS-terminating proxy. Without it the cookie is only Secure on direct TLS connections.
+func (h *Handler) SetSecureCookies(secure bool) {
+	h.secureCookies = secure
+}
+
+// SetOAuthStateStore persists issued OAuth states in store instead of process memory
+func (h *Handler) SetOAuthStateStore(store msgraph.OAuthStateStore) {
+	h.oauthStates = msgraph.NewOAuthStateManager(store)
+}
+
 // GetDocuments retrieves documents from Microsoft Graph
 func (h *Handler) GetDocuments(ctx context.Context) (*types.DocumentCollection, error) {
 	if h.msgraphClient == nil {
@@ -123,6 +173,24 @@ func (h *Handler) GetDocuments(ctx context.Context) (*types.DocumentCollection,
 	return h.msgraphClient.GetOneNoteDataAsJSON(ctx)
 }
 
+// SyncDocuments retrieves the OneNote pages changed since the previous sync
+func (h *Handler) SyncDocuments(ctx context.Context, full bool) (*msgraph.OneNoteSyncResult, error) {
+	if h.msgraphClient == nil {
+		return nil, nil
+	}
+
+	return h.msgraphClient.SyncOneNoteData(ctx, full)
+}
+
+// CommitSync saves the checkpoint of a sync once its changes have been stored
+func (h *Handler) CommitSync(ctx context.Context, result *msgraph.OneNoteSyncResult) error {
+	if h.msgraphClient == nil {
+		return nil
+	}
+
+	return h.msgraphClient.CommitSync(ctx, result)
+}
+
 // ExtractAllData returns all OneNote documents
 func (h *Handler) ExtractAllData(c *gin.Context) {
 	// Check for Authorization header with Bearer token
@@ -132,7 +200,7 @@ func (h *Handler) ExtractAllData(c *gin.Context) {
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Create a temporary client with the provided token
-		tempHandler, err := NewWithToken(token)
+		tempHandler, err := NewWithTokenAndConfig(token, h.graphConfig)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Invalid access token",
@@ -202,7 +270,8 @@ func (h *Handler) IsConfigured() bool {
 
 // OAuth Endpoints
 
-// Authorize generates authorization URL for OAuth 2.0 flow
+// Authorize generates authorization URL for OAuth 2.0 flow. Browser apps on another origin must
+// call it with credentials so the session cookie is stored, or navigate to AuthorizeRedirect.
 func (h *Handler) Authorize(c *gin.Context) {
 	if h.oauthClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -220,30 +289,74 @@ func (h *Handler) Authorize(c *gin.Context) {
 		return
 	}
 
-	// Generate state parameter
-	state, err := msgraph.GenerateStateParameter()
+	authURL, state, ok := h.beginAuthorization(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, AuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+	})
+}
+
+// AuthorizeRedirect starts sign-in as a top-level navigation and redirects the browser to the
+// authorization URL. Unlike a cross-origin Authorize call, a navigation always stores the
+// session cookie that Callback checks.
+func (h *Handler) AuthorizeRedirect(c *gin.Context) {
+	if h.oauthClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "OAuth client not configured",
+		})
+		return
+	}
+
+	authURL, _, ok := h.beginAuthorization(c)
+	if !ok {
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// beginAuthorization issues a state bound to the caller's session, sets the session cookie and
+// returns the authorization URL and state. On failure it responds with the error and returns false.
+func (h *Handler) beginAuthorization(c *gin.Context) (string, string, bool) {
+	// Bind the state to the caller's session, starting one if needed
+	sessionID, err := c.Cookie(oauthSessionCookie)
+	if err != nil || sessionID == "" {
+		sessionID, err = newSessionID()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to start OAuth session",
+				"details": err.Error(),
+			})
+			return "", "", false
+		}
+	}
+
+	// Generate state parameter and PKCE code verifier
+	authRequest, err := h.oauthStates.Issue(c.Request.Context(), sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate state parameter",
 			"details": err.Error(),
 		})
-		return
+		return "", "", false
 	}
 
 	// Generate authorization URL
-	authURL, err := h.oauthClient.GetAuthorizationURL(state)
+	authURL, err := h.oauthClient.GetAuthorizationURLWithPKCE(authRequest.State, authRequest.CodeChallenge)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate authorization URL",
 			"details": err.Error(),
 		})
-		return
+		return "", "", false
 	}
 
-	c.JSON(http.StatusOK, AuthorizeResponse{
-		AuthorizationURL: authURL,
-		State:            state,
-	})
+	secure := h.secureCookies || c.Request.TLS != nil
+	c.SetSameSite(http.SameSiteLaxMode) // Sent on the top-level redirect back to the callback
+	c.SetCookie(oauthSessionCookie, sessionID, int(h.oauthStates.TTL().Seconds()), "/", "", secure, true)
+	return authURL, authRequest.State, true
 }
 
 // Callback handles the OAuth callback and exchanges code for tokens
@@ -266,10 +379,26 @@ func (h *Handler) Callback(c *gin.Context) {
 		return
 	}
 
+	// The state must have been issued to this session and not used before (CSRF protection)
+	sessionID, _ := c.Cookie(oauthSessionCookie)
+	codeVerifier, err := h.oauthStates.Validate(c.Request.Context(), c.Query("state"), sessionID)
+	if err != nil {
+		if errors.Is(err, msgraph.ErrInvalidOAuthState) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid OAuth state",
+				"details": "The state is unknown, expired, already used or was issued to another session",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to validate OAuth state",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// Get authorization code from query parameters
 	code := c.Query("code")
-	_ = c.Query("state") // TODO: Validate state parameter for CSRF protection
-
 	if code == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Authorization code is required",
@@ -277,8 +406,8 @@ func (h *Handler) Callback(c *gin.Context) {
 		return
 	}
 
-	// Exchange code for tokens
-	tokenResponse, err := h.oauthClient.ExchangeCode(code)
+	// Exchange code for tokens, proving the PKCE code verifier
+	tokenResponse, err := h.oauthClient.ExchangeCodeContext(c.Request.Context(), code, codeVerifier)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to exchange authorization code for tokens",
@@ -287,7 +416,107 @@ func (h *Handler) Callback(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, tokenResponse)
+	h.respondWithTokens(c, tokenResponse)
+}
+
+// Device starts a device authorization for signing in without a browser on this host, or
+// polls a started one. Once the user has signed in it responds like Callback.
+func (h *Handler) Device(c *gin.Context) {
+	if h.oauthClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "OAuth client not configured",
+		})
+		return
+	}
+
+	// The body is optional: an empty request starts a device authorization
+	var req DeviceAuthorizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.DeviceCode == "" {
+		deviceCode, err := h.oauthClient.StartDeviceAuthorization(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to start device authorization",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, deviceCode)
+		return
+	}
+
+	tokenResponse, err := h.oauthClient.PollDeviceAuthorization(c.Request.Context(), req.DeviceCode)
+	switch {
+	case err == nil:
+		h.respondWithTokens(c, tokenResponse)
+	case errors.Is(err, msgraph.ErrAuthorizationPending):
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  "authorization_pending",
+			"message": "The user has not signed in yet; poll again after the interval",
+		})
+	case errors.Is(err, msgraph.ErrSlowDown):
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  "slow_down",
+			"message": "Polling too frequently; add 5 seconds to the interval",
+		})
+	case errors.Is(err, msgraph.ErrAuthorizationDeclined):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Device authorization declined by the user",
+		})
+	case errors.Is(err, msgraph.ErrDeviceCodeExpired):
+		c.JSON(http.StatusGone, gin.H{
+			"error": "Device code expired, start a new device authorization",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to complete device authorization",
+			"details": err.Error(),
+		})
+	}
+}
+
+// respondWithTokens returns the tokens of a completed sign-in, or stores them and returns
+// the user key and the user secret that unlocks them when a token vault is configured
+func (h *Handler) respondWithTokens(c *gin.Context, tokenResponse *msgraph.TokenResponse) {
+	if h.tokenVault == nil {
+		c.JSON(http.StatusOK, tokenResponse)
+		return
+	}
+
+	// Keep the tokens server-side under the signed-in user's ID, which callers pass as user_key
+	userKey, err := h.oauthClient.GetUserID(tokenResponse.AccessToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to identify the signed-in user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	secret, err := h.tokenVault.Store(c.Request.Context(), userKey, tokenResponse)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to store tokens",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_key":    userKey,
+		"user_secret": secret,
+		"token_type":  tokenResponse.TokenType,
+		"scope":       tokenResponse.Scope,
+		"expires_in":  tokenResponse.ExpiresIn,
+		"stored":      true,
+	})
 }
 
 // RefreshToken refreshes an expired access token
@@ -316,7 +545,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	}
 
 	// Refresh access token
-	tokenResponse, err := h.oauthClient.RefreshAccessToken(req.RefreshToken)
+	tokenResponse, err := h.oauthClient.RefreshAccessTokenContext(c.Request.Context(), req.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to refresh access token",
@@ -368,3 +597,12 @@ func (h *Handler) TestToken(c *gin.Context) {
 		"message": "Access token is valid",
 	})
 }
+
+// newSessionID generates a random OAuth session ID
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
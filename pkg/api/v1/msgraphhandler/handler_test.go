@@ -2,15 +2,19 @@ package msgraphhandler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ishank09/data-extraction-service/internal/types"
 	"github.com/ishank09/data-extraction-service/pkg/msgraph"
+	"github.com/ishank09/data-extraction-service/pkg/msgraph/msgraphtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,6 +29,16 @@ func (m *MockMSGraphClient) GetOneNoteDataAsJSON(ctx context.Context) (*types.Do
 	return args.Get(0).(*types.DocumentCollection), args.Error(1)
 }
 
+func (m *MockMSGraphClient) SyncOneNoteData(ctx context.Context, full bool) (*msgraph.OneNoteSyncResult, error) {
+	args := m.Called(ctx, full)
+	return args.Get(0).(*msgraph.OneNoteSyncResult), args.Error(1)
+}
+
+func (m *MockMSGraphClient) CommitSync(ctx context.Context, result *msgraph.OneNoteSyncResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -293,3 +307,153 @@ func TestHandler_IsConfigured(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_OAuthStateAndPKCE(t *testing.T) {
+	server := msgraphtest.NewServer()
+	defer server.Close()
+
+	handler := NewWithOAuth(msgraph.OAuthConfig{
+		ClientID:      msgraphtest.ClientID,
+		ClientSecret:  msgraphtest.ClientSecret,
+		RedirectURI:   "http://localhost/callback",
+		LoginEndpoint: server.LoginURL(),
+		GraphEndpoint: server.GraphURL(),
+	})
+	router := setupRouter()
+	router.POST("/oauth/authorize", handler.Authorize)
+	router.GET("/oauth/callback", handler.Callback)
+
+	// Authorize starts a session and sends a code challenge
+	req := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, oauthSessionCookie, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+	}
+
+	var response AuthorizeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	authURL, err := url.Parse(response.AuthorizationURL)
+	assert.NoError(t, err)
+	assert.Equal(t, response.State, authURL.Query().Get("state"))
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	server.RequireCodeChallenge(authURL.Query().Get("code_challenge"))
+
+	callback := func(state string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		query := url.Values{"code": {msgraphtest.AuthorizationCode}, "state": {state}}
+		req := httptest.NewRequest(http.MethodGet, "/oauth/callback?"+query.Encode(), nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Callbacks without session or with an unknown state are rejected
+	assert.Equal(t, http.StatusBadRequest, callback(response.State, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, callback("forged-state", cookies[0]).Code)
+
+	// A state presented by another session is rejected and consumed, so sign in again
+	otherSession := &http.Cookie{Name: oauthSessionCookie, Value: "other-session"}
+	assert.Equal(t, http.StatusBadRequest, callback(response.State, otherSession).Code)
+	assert.Equal(t, http.StatusBadRequest, callback(response.State, cookies[0]).Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader("{}"))
+	req.AddCookie(cookies[0])
+	router.ServeHTTP(w, req)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	authURL, _ = url.Parse(response.AuthorizationURL)
+	server.RequireCodeChallenge(authURL.Query().Get("code_challenge"))
+
+	w = callback(response.State, cookies[0])
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "access_token")
+
+	// A state can only be used once
+	assert.Equal(t, http.StatusBadRequest, callback(response.State, cookies[0]).Code)
+}
+
+func TestHandler_AuthorizeRedirect(t *testing.T) {
+	tests := []struct {
+		name           string
+		secureCookies  bool
+		expectedSecure bool
+	}{
+		{name: "plain HTTP without secure cookies", secureCookies: false, expectedSecure: false},
+		{name: "secure cookies behind a TLS proxy", secureCookies: true, expectedSecure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewWithOAuth(msgraph.OAuthConfig{
+				ClientID:    msgraphtest.ClientID,
+				RedirectURI: "http://localhost/callback",
+			})
+			handler.SetSecureCookies(tt.secureCookies)
+			router := setupRouter()
+			router.GET("/oauth/authorize", handler.AuthorizeRedirect)
+
+			req := httptest.NewRequest(http.MethodGet, "/oauth/authorize", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusFound, w.Code)
+			authURL, err := url.Parse(w.Header().Get("Location"))
+			assert.NoError(t, err)
+			assert.NotEmpty(t, authURL.Query().Get("state"))
+			assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+
+			cookies := w.Result().Cookies()
+			if assert.Len(t, cookies, 1) {
+				assert.Equal(t, oauthSessionCookie, cookies[0].Name)
+				assert.Equal(t, tt.expectedSecure, cookies[0].Secure)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			}
+		})
+	}
+}
+
+func TestHandler_Device(t *testing.T) {
+	server := msgraphtest.NewServer()
+	defer server.Close()
+
+	handler := NewWithOAuth(msgraph.OAuthConfig{
+		ClientID:      msgraphtest.ClientID,
+		ClientSecret:  msgraphtest.ClientSecret,
+		LoginEndpoint: server.LoginURL(),
+		GraphEndpoint: server.GraphURL(),
+	})
+	router := setupRouter()
+	router.POST("/oauth/device", handler.Device)
+
+	device := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/oauth/device", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// An empty request starts a device authorization
+	w := device("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var deviceCode msgraph.DeviceCodeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deviceCode))
+	assert.Equal(t, msgraphtest.UserCode, deviceCode.UserCode)
+
+	poll := `{"device_code":"` + deviceCode.DeviceCode + `"}`
+	server.SetDeviceCodePending(1)
+	assert.Equal(t, http.StatusAccepted, device(poll).Code)
+
+	w = device(poll)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "access_token")
+
+	server.DeclineDeviceCode()
+	assert.Equal(t, http.StatusForbidden, device(poll).Code)
+}
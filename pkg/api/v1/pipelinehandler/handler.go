@@ -2,31 +2,63 @@ package pipelinehandler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/internal/utils"
 	"github.com/ishank09/data-extraction-service/pkg/api/v1/msgraphhandler"
 	"github.com/ishank09/data-extraction-service/pkg/api/v1/statichandler"
+	"github.com/ishank09/data-extraction-service/pkg/filesystem"
 	"github.com/ishank09/data-extraction-service/pkg/mongodb"
 	"github.com/ishank09/data-extraction-service/pkg/msgraph"
 	"github.com/ishank09/data-extraction-service/pkg/static"
 )
 
+const (
+	uploadFormField       = "files" // Multipart field accepting one or more files
+	uploadFormFieldSingle = "file"  // Alias accepted for single-file uploads
+
+	// uploadFormFieldNamespace optionally scopes upload document IDs, e.g. to a team or project
+	uploadFormFieldNamespace = "namespace"
+)
+
+const (
+	DefaultMaxUploadSize     int64 = 100 << 20 // Largest upload request in bytes
+	DefaultMaxUploadFileSize int64 = 25 << 20  // Largest single uploaded file in bytes
+)
+
+// UploadConfig limits the size of multipart uploads
+type UploadConfig struct {
+	MaxRequestSize int64 `json:"max_request_size,omitempty"` // Largest upload request in bytes (0 uses DefaultMaxUploadSize)
+	MaxFileSize    int64 `json:"max_file_size,omitempty"`    // Largest single uploaded file in bytes (0 uses DefaultMaxUploadFileSize)
+}
+
 // Handler handles ETL pipeline operations from multiple sources
 type Handler struct {
-	staticHandler   *statichandler.Handler
-	msgraphHandler  *msgraphhandler.Handler
-	documentService *mongodb.DocumentService
+	staticHandler    *statichandler.Handler
+	msgraphHandler   *msgraphhandler.Handler
+	filesystemClient *filesystem.Client
+	documentService  *mongodb.DocumentService
+	msgraphConfig    *msgraph.Config // Cloud and crawl settings for clients created from request tokens
+	tokenVault       *msgraph.TokenVault
+	upload           UploadConfig
 }
 
 // Config represents the configuration for the pipeline handler
 type Config struct {
-	MSGraphConfig   *msgraph.Config          `json:"msgraph_config,omitempty"`
-	UserID          string                   `json:"user_id,omitempty"` // Required for application flow when accessing user data
-	DocumentService *mongodb.DocumentService `json:"document_service,omitempty"`
+	MSGraphConfig    *msgraph.Config          `json:"msgraph_config,omitempty"`
+	UserID           string                   `json:"user_id,omitempty"` // Required for application flow when accessing user data
+	FilesystemConfig *filesystem.Config       `json:"filesystem_config,omitempty"`
+	DocumentService  *mongodb.DocumentService `json:"document_service,omitempty"`
+	Upload           *UploadConfig            `json:"upload,omitempty"`
+	CheckpointStore  msgraph.CheckpointStore  `json:"-"` // Sync checkpoints of clients created from request tokens, unless MSGraphConfig sets one
 }
 
 // New creates a new pipeline handler
@@ -39,11 +71,28 @@ func New(config *Config) (*Handler, error) {
 	if config != nil && config.DocumentService != nil {
 		handler.documentService = config.DocumentService
 	}
+	if config != nil && config.Upload != nil {
+		handler.upload = *config.Upload
+	}
+
+	// Clients created from request tokens share one checkpoint store, so that an incremental
+	// sync continues from the checkpoint saved by the previous request
+	var graphConfig msgraph.Config
+	if config != nil && config.MSGraphConfig != nil {
+		graphConfig = *config.MSGraphConfig
+	}
+	if graphConfig.CheckpointStore == nil && config != nil {
+		graphConfig.CheckpointStore = config.CheckpointStore
+	}
+	if graphConfig.CheckpointStore == nil {
+		graphConfig.CheckpointStore = msgraph.NewMemoryCheckpointStore()
+	}
+	handler.msgraphConfig = &graphConfig
 
 	// Initialize msgraph handler if config is provided
 	if config != nil && config.MSGraphConfig != nil {
 		msgraphConfig := &msgraphhandler.Config{
-			MSGraphConfig: config.MSGraphConfig,
+			MSGraphConfig: &graphConfig,
 			UserID:        config.UserID, // Pass user ID for application flow
 		}
 
@@ -54,6 +103,15 @@ func New(config *Config) (*Handler, error) {
 		handler.msgraphHandler = msgraphHandler
 	}
 
+	// Initialize filesystem source if directories are configured
+	if config != nil && config.FilesystemConfig.IsConfigured() {
+		filesystemClient, err := filesystem.NewClient(*config.FilesystemConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create filesystem client: %w", err)
+		}
+		handler.filesystemClient = filesystemClient
+	}
+
 	return handler, nil
 }
 
@@ -73,12 +131,70 @@ func NewWithDocumentService(documentService *mongodb.DocumentService) *Handler {
 	}
 }
 
+// SetTokenVault lets requests run against the stored token of a user passed as user_key
+func (h *Handler) SetTokenVault(vault *msgraph.TokenVault) {
+	h.tokenVault = vault
+}
+
+// requestToken returns the delegated access token of a request: the Bearer token of the
+// Authorization header, or the stored token of the user_key query parameter, which is only
+// released for the user secret issued at sign-in. An empty token means the configured client
+// credentials are used.
+func (h *Handler) requestToken(c *gin.Context) (string, error) {
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer "), nil
+	}
+
+	userKey := c.Query("user_key")
+	if userKey == "" {
+		return "", nil
+	}
+	if h.tokenVault == nil {
+		return "", errTokenVaultNotConfigured
+	}
+	return h.tokenVault.AccessToken(c.Request.Context(), userKey, c.GetHeader(msgraph.UserSecretHeader))
+}
+
+// errTokenVaultNotConfigured is returned for user_key requests when tokens are not stored server-side
+var errTokenVaultNotConfigured = errors.New("token vault not configured")
+
+// writeTokenError responds to a request whose stored token could not be used
+func writeTokenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errTokenVaultNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Token vault not configured",
+			"message": "Configure a token encryption key to store tokens server-side, or provide an Authorization header with Bearer token",
+		})
+	case errors.Is(err, msgraph.ErrTokenNotFound), errors.Is(err, msgraph.ErrInvalidUserSecret):
+		// Unknown users and wrong secrets look the same, so stored users cannot be enumerated
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Invalid user_key or user secret",
+			"message": "Pass the user secret issued at sign-in in the " + msgraph.UserSecretHeader + " header",
+		})
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Failed to get stored token",
+			"details": err.Error(),
+		})
+	}
+}
+
 // extractStaticData retrieves data from static handler
 func (h *Handler) extractStaticData(ctx context.Context) (*types.DocumentCollection, error) {
 	staticClient := static.NewClient()
 	return staticClient.GetAllDataAsJSON(ctx)
 }
 
+// extractFilesystemData retrieves data from the configured host directories
+func (h *Handler) extractFilesystemData(ctx context.Context) (*types.DocumentCollection, error) {
+	if h.filesystemClient == nil {
+		return nil, fmt.Errorf("filesystem source not configured")
+	}
+
+	return h.filesystemClient.GetAllDataAsJSON(ctx)
+}
+
 // extractMsgraphData retrieves data from msgraph handler
 func (h *Handler) extractMsgraphData(ctx context.Context) (*types.DocumentCollection, error) {
 	if h.msgraphHandler == nil || !h.msgraphHandler.IsConfigured() {
@@ -90,7 +206,7 @@ func (h *Handler) extractMsgraphData(ctx context.Context) (*types.DocumentCollec
 
 // extractMsgraphDataWithToken retrieves data using an access token
 func (h *Handler) extractMsgraphDataWithToken(ctx context.Context, token string) (*types.DocumentCollection, error) {
-	tempHandler, err := msgraphhandler.NewWithToken(token)
+	tempHandler, err := msgraphhandler.NewWithTokenAndConfig(token, h.msgraphConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create msgraph client with token: %w", err)
 	}
@@ -99,26 +215,38 @@ func (h *Handler) extractMsgraphDataWithToken(ctx context.Context, token string)
 }
 
 // mergeDataCollections merges data from different sources into a single collection
-func (h *Handler) mergeDataCollections(staticData, msgraphData *types.DocumentCollection) *types.DocumentCollection {
+func (h *Handler) mergeDataCollections(collections ...*types.DocumentCollection) *types.DocumentCollection {
 	masterCollection := types.NewDocumentCollection("etl_pipeline")
 
-	// Add static data
-	if staticData != nil {
-		for _, doc := range staticData.Documents {
-			masterCollection.AddDocument(doc)
+	for _, collection := range collections {
+		if collection == nil {
+			continue
 		}
-	}
-
-	// Add msgraph data
-	if msgraphData != nil {
-		for _, doc := range msgraphData.Documents {
+		for _, doc := range collection.Documents {
 			masterCollection.AddDocument(doc)
 		}
+		masterCollection.Errors = append(masterCollection.Errors, collection.Errors...)
+		masterCollection.Warnings = append(masterCollection.Warnings, collection.Warnings...)
 	}
 
 	return masterCollection
 }
 
+// collectionResponse describes an extracted collection for inclusion in pipeline responses.
+// The status tells consumers whether items failed, in which case errors lists them.
+func collectionResponse(collection *types.DocumentCollection) gin.H {
+	return gin.H{
+		"source":         collection.Source,
+		"fetched_at":     collection.FetchedAt,
+		"schema_version": collection.SchemaVersion,
+		"documents":      collection.Documents,
+		"document_count": len(collection.Documents),
+		"status":         collection.Status(),
+		"errors":         collection.Errors,
+		"warnings":       collection.Warnings,
+	}
+}
+
 // storeDocuments stores documents to MongoDB if document service is available
 func (h *Handler) storeDocuments(ctx context.Context, collection *types.DocumentCollection) (*mongodb.StoreCollectionResult, error) {
 	if h.documentService == nil {
@@ -128,6 +256,32 @@ func (h *Handler) storeDocuments(ctx context.Context, collection *types.Document
 	return h.documentService.StoreDocumentCollection(ctx, collection)
 }
 
+// storageInfo describes the outcome of storing a collection for inclusion in pipeline responses
+func (h *Handler) storageInfo(storeResult *mongodb.StoreCollectionResult) gin.H {
+	if storeResult != nil {
+		return gin.H{
+			"stored":           true,
+			"collection_id":    storeResult.CollectionID,
+			"stored_documents": storeResult.DocumentCount,
+			"inserted":         storeResult.InsertedCount,
+			"updated":          storeResult.UpdatedCount,
+			"unchanged":        storeResult.UnchangedCount,
+		}
+	}
+
+	if h.documentService != nil {
+		return gin.H{
+			"stored": false,
+			"error":  "Failed to store documents",
+		}
+	}
+
+	return gin.H{
+		"stored": false,
+		"reason": "Document storage not configured",
+	}
+}
+
 // ExtractAllData returns data from all available sources and stores to MongoDB
 func (h *Handler) ExtractAllData(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -145,12 +299,13 @@ func (h *Handler) ExtractAllData(c *gin.Context) {
 	// Extract msgraph data
 	var msgraphData *types.DocumentCollection
 
-	// Check for Authorization header with Bearer token
-	authHeader := c.GetHeader("Authorization")
-	if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-		// Extract token from Authorization header
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-
+	// Use the request's Bearer token or stored user token if given
+	token, err := h.requestToken(c)
+	if err != nil {
+		writeTokenError(c, err)
+		return
+	}
+	if token != "" {
 		msgraphData, err = h.extractMsgraphDataWithToken(ctx, token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -171,8 +326,21 @@ func (h *Handler) ExtractAllData(c *gin.Context) {
 		}
 	}
 
-	// Merge data from both sources
-	mergedCollection := h.mergeDataCollections(staticData, msgraphData)
+	// Extract filesystem data if host directories are configured
+	var filesystemData *types.DocumentCollection
+	if h.filesystemClient != nil {
+		filesystemData, err = h.extractFilesystemData(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to extract filesystem data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Merge data from all sources
+	mergedCollection := h.mergeDataCollections(staticData, filesystemData, msgraphData)
 
 	// Store documents to MongoDB
 	var storeResult *mongodb.StoreCollectionResult
@@ -186,32 +354,10 @@ func (h *Handler) ExtractAllData(c *gin.Context) {
 	}
 
 	// Prepare response
-	response := gin.H{
-		"source":         mergedCollection.Source,
-		"fetched_at":     mergedCollection.FetchedAt,
-		"schema_version": mergedCollection.SchemaVersion,
-		"documents":      mergedCollection.Documents,
-		"document_count": len(mergedCollection.Documents),
-	}
+	response := collectionResponse(mergedCollection)
 
 	// Add storage information if available
-	if storeResult != nil {
-		response["storage"] = gin.H{
-			"stored":           true,
-			"collection_id":    storeResult.CollectionID,
-			"stored_documents": storeResult.DocumentCount,
-		}
-	} else if h.documentService != nil {
-		response["storage"] = gin.H{
-			"stored": false,
-			"error":  "Failed to store documents",
-		}
-	} else {
-		response["storage"] = gin.H{
-			"stored": false,
-			"reason": "Document storage not configured",
-		}
-	}
+	response["storage"] = h.storageInfo(storeResult)
 
 	c.JSON(http.StatusOK, response)
 }
@@ -235,13 +381,45 @@ func (h *Handler) ExtractDataBySource(c *gin.Context) {
 			return
 		}
 
+	case "filesystem":
+		if h.filesystemClient == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Filesystem source not configured",
+				"message": "Configure one or more host directories to enable the filesystem source",
+			})
+			return
+		}
+
+		collection, err = h.extractFilesystemData(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to extract filesystem data",
+				"details": err.Error(),
+			})
+			return
+		}
+
 	case "msgraph", "onenote":
-		// Check for Authorization header with Bearer token
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-			// Extract token from Authorization header
-			token := strings.TrimPrefix(authHeader, "Bearer ")
+		// Restrict the crawl to the requested notebooks, sections and page titles
+		if filter := parseOneNoteFilter(c); !filter.IsEmpty() {
+			ctx, err = msgraph.WithFilter(ctx, filter)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid OneNote filter",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
 
+		// Use the request's Bearer token or stored user token if given
+		var token string
+		token, err = h.requestToken(c)
+		if err != nil {
+			writeTokenError(c, err)
+			return
+		}
+		if token != "" {
 			collection, err = h.extractMsgraphDataWithToken(ctx, token)
 			if err != nil {
 				c.JSON(http.StatusUnauthorized, gin.H{
@@ -255,7 +433,7 @@ func (h *Handler) ExtractDataBySource(c *gin.Context) {
 			if h.msgraphHandler == nil || !h.msgraphHandler.IsConfigured() {
 				c.JSON(http.StatusServiceUnavailable, gin.H{
 					"error":   "Microsoft Graph client not configured and no access token provided",
-					"message": "Either configure the service with client credentials or provide an Authorization header with Bearer token",
+					"message": "Either configure the service with client credentials, provide an Authorization header with Bearer token, or pass the user_key of a stored token",
 				})
 				return
 			}
@@ -273,7 +451,7 @@ func (h *Handler) ExtractDataBySource(c *gin.Context) {
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":             "Invalid source",
-			"supported_sources": []string{"static", "msgraph", "onenote"},
+			"supported_sources": []string{"static", "filesystem", "msgraph", "onenote"},
 		})
 		return
 	}
@@ -289,36 +467,182 @@ func (h *Handler) ExtractDataBySource(c *gin.Context) {
 	}
 
 	// Prepare response
-	response := gin.H{
-		"source":         collection.Source,
-		"fetched_at":     collection.FetchedAt,
-		"schema_version": collection.SchemaVersion,
-		"documents":      collection.Documents,
-		"document_count": len(collection.Documents),
-	}
+	response := collectionResponse(collection)
 
 	// Add storage information if available
-	if storeResult != nil {
-		response["storage"] = gin.H{
-			"stored":           true,
-			"collection_id":    storeResult.CollectionID,
-			"stored_documents": storeResult.DocumentCount,
+	response["storage"] = h.storageInfo(storeResult)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseOneNoteFilter reads the OneNote crawl filter from the query parameters. List parameters
+// accept comma-separated values and may be repeated.
+func parseOneNoteFilter(c *gin.Context) msgraph.FilterConfig {
+	return msgraph.FilterConfig{
+		IncludeNotebooks: queryList(c, "include_notebooks"),
+		ExcludeNotebooks: queryList(c, "exclude_notebooks"),
+		IncludeSections:  queryList(c, "include_sections"),
+		ExcludeSections:  queryList(c, "exclude_sections"),
+		PageTitlePattern: c.Query("page_title"),
+	}
+}
+
+// queryList returns the trimmed, non-empty comma-separated values of a repeatable query parameter
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
 		}
-	} else if h.documentService != nil {
-		response["storage"] = gin.H{
-			"stored": false,
-			"error":  "Failed to store documents",
+	}
+	return values
+}
+
+// SyncOneNote downloads the OneNote pages changed since the previous sync, stores them to MongoDB
+// and removes the documents of deleted pages and attachments. Pass full=true to download every page.
+// The sync checkpoint only advances once storing and removing succeeded, so a failed write reports
+// the same changes again on the next sync.
+func (h *Handler) SyncOneNote(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	full := false
+	if value := c.Query("full"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid full parameter",
+				"details": "full must be true or false",
+			})
+			return
+		}
+		full = parsed
+	}
+
+	var result *msgraph.OneNoteSyncResult
+	var syncHandler *msgraphhandler.Handler
+	var err error
+
+	// Use the request's Bearer token or stored user token if given
+	token, err := h.requestToken(c)
+	if err != nil {
+		writeTokenError(c, err)
+		return
+	}
+	if token != "" {
+		tempHandler, err := msgraphhandler.NewWithTokenAndConfig(token, h.msgraphConfig)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Failed to create msgraph client with provided token",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		syncHandler = tempHandler
+		result, err = tempHandler.SyncDocuments(ctx, full)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Failed to sync OneNote data with provided token",
+				"details": err.Error(),
+			})
+			return
 		}
 	} else {
-		response["storage"] = gin.H{
-			"stored": false,
-			"reason": "Document storage not configured",
+		if h.msgraphHandler == nil || !h.msgraphHandler.IsConfigured() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Microsoft Graph client not configured and no access token provided",
+				"message": "Either configure the service with client credentials, provide an Authorization header with Bearer token, or pass the user_key of a stored token",
+			})
+			return
+		}
+
+		syncHandler = h.msgraphHandler
+		result, err = h.msgraphHandler.SyncDocuments(ctx, full)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to sync OneNote data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Store added and changed documents to MongoDB
+	var storeResult *mongodb.StoreCollectionResult
+	var storeErr error
+	if h.documentService != nil && len(result.Collection.Documents) > 0 {
+		storeResult, storeErr = h.storeDocuments(ctx, result.Collection)
+		if storeErr != nil {
+			// Log the error but don't fail the request
+			c.Header("X-Storage-Warning", fmt.Sprintf("Failed to store documents: %v", storeErr))
+		}
+	}
+
+	// Remove documents of deleted pages and attachments
+	removed, deleteErr := h.deleteDocuments(ctx, result.DeletedDocumentIDs)
+	if deleteErr != nil {
+		c.Header("X-Storage-Warning", fmt.Sprintf("Failed to delete documents: %v", deleteErr))
+	}
+
+	// Advance the checkpoint only once every change was applied
+	checkpointSaved := false
+	if storeErr == nil && deleteErr == nil {
+		if err := syncHandler.CommitSync(ctx, result); err != nil {
+			c.Header("X-Sync-Warning", fmt.Sprintf("Failed to save sync checkpoint: %v", err))
+		} else {
+			checkpointSaved = true
+		}
+	}
+
+	response := collectionResponse(result.Collection)
+	response["full_sync"] = result.FullSync
+	response["since"] = result.Since
+	response["added_page_ids"] = result.AddedPageIDs
+	response["changed_page_ids"] = result.ChangedPageIDs
+	response["deleted_page_ids"] = result.DeletedPageIDs
+	response["deleted_document_ids"] = result.DeletedDocumentIDs
+	response["failed_page_ids"] = result.FailedPageIDs
+	response["unchanged_pages"] = result.UnchangedPages
+	response["deletions_detected"] = result.DeletionsDetected
+	response["checkpoint_saved"] = checkpointSaved
+
+	storage := h.storageInfo(storeResult)
+	if h.documentService != nil {
+		if len(result.Collection.Documents) == 0 {
+			// Nothing changed, so there was nothing to store
+			storage = gin.H{"stored": true, "stored_documents": 0}
 		}
+		storage["removed_documents"] = removed
 	}
+	response["storage"] = storage
 
 	c.JSON(http.StatusOK, response)
 }
 
+// deleteDocuments removes stored documents by ID, ignoring documents that were never stored,
+// and returns how many were removed along with the errors of documents that could not be removed
+func (h *Handler) deleteDocuments(ctx context.Context, documentIDs []string) (int, error) {
+	if h.documentService == nil {
+		return 0, nil
+	}
+
+	removed := 0
+	var errs []error
+	for _, documentID := range documentIDs {
+		if _, err := h.documentService.DeleteDocument(ctx, documentID); err != nil {
+			if errors.Is(err, mongodb.ErrDocumentNotFound) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("document %s: %w", documentID, err))
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
 // ExtractDataByType returns data filtered by type from static source and stores to MongoDB
 func (h *Handler) ExtractDataByType(c *gin.Context) {
 	fileType := c.Param("type")
@@ -351,37 +675,166 @@ func (h *Handler) ExtractDataByType(c *gin.Context) {
 	}
 
 	// Prepare response
-	response := gin.H{
-		"source":         collection.Source,
-		"fetched_at":     collection.FetchedAt,
-		"schema_version": collection.SchemaVersion,
-		"documents":      collection.Documents,
-		"document_count": len(collection.Documents),
-		"type_filter":    fileType,
-	}
+	response := collectionResponse(collection)
+	response["type_filter"] = fileType
 
 	// Add storage information if available
-	if storeResult != nil {
-		response["storage"] = gin.H{
-			"stored":           true,
-			"collection_id":    storeResult.CollectionID,
-			"stored_documents": storeResult.DocumentCount,
+	response["storage"] = h.storageInfo(storeResult)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadDocuments processes files uploaded as multipart form data and stores them to MongoDB
+func (h *Handler) UploadDocuments(c *gin.Context) {
+	ctx := c.Request.Context()
+	maxRequestSize, maxFileSize := h.uploadLimits()
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestSize)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Upload too large",
+				"message": fmt.Sprintf("Upload requests are limited to %d bytes", maxRequestSize),
+			})
+			return
 		}
-	} else if h.documentService != nil {
-		response["storage"] = gin.H{
-			"stored": false,
-			"error":  "Failed to store documents",
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid multipart form",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var fileHeaders []*multipart.FileHeader
+	fileHeaders = append(fileHeaders, form.File[uploadFormField]...)
+	fileHeaders = append(fileHeaders, form.File[uploadFormFieldSingle]...)
+	if len(fileHeaders) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No files uploaded",
+			"message": fmt.Sprintf("Attach one or more files using the '%s' form field", uploadFormField),
+		})
+		return
+	}
+
+	var namespace string
+	if values := form.Value[uploadFormFieldNamespace]; len(values) > 0 {
+		namespace = strings.Trim(strings.TrimSpace(values[0]), "/")
+	}
+
+	staticClient := static.NewClient()
+	collection := types.NewDocumentCollection("upload")
+
+	for _, fileHeader := range fileHeaders {
+		content, err := readUploadedFile(fileHeader, maxFileSize)
+		if errors.Is(err, errUploadedFileTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":    "Uploaded file too large",
+				"filename": fileHeader.Filename,
+				"message":  fmt.Sprintf("Uploaded files are limited to %d bytes", maxFileSize),
+			})
+			return
 		}
-	} else {
-		response["storage"] = gin.H{
-			"stored": false,
-			"reason": "Document storage not configured",
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    "Failed to read uploaded file",
+				"filename": fileHeader.Filename,
+				"details":  err.Error(),
+			})
+			return
 		}
+
+		doc, err := staticClient.ProcessFile(ctx, fileHeader.Filename, fileHeader.Header.Get("Content-Type"), content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":           "Failed to process uploaded file",
+				"filename":        fileHeader.Filename,
+				"details":         err.Error(),
+				"supported_types": staticClient.GetSupportedFileTypes(),
+			})
+			return
+		}
+
+		// Re-derive the ID so it reflects the upload source rather than the embedded one
+		doc.Source = "upload"
+		doc.ID = utils.GenerateDocumentID(doc.Type, doc.Source, uploadLocation(namespace, fileHeader.Filename, content))
+		if namespace != "" {
+			doc.Location = namespace + "/" + fileHeader.Filename
+			if doc.Metadata == nil {
+				doc.Metadata = map[string]interface{}{}
+			}
+			doc.Metadata["namespace"] = namespace
+		}
+		collection.AddDocument(*doc)
 	}
 
+	// Store documents to MongoDB
+	var storeResult *mongodb.StoreCollectionResult
+	if h.documentService != nil {
+		storeResult, err = h.storeDocuments(ctx, collection)
+		if err != nil {
+			// Log the error but don't fail the request
+			c.Header("X-Storage-Warning", fmt.Sprintf("Failed to store documents: %v", err))
+		}
+	}
+
+	// Prepare response
+	response := collectionResponse(collection)
+
+	// Add storage information if available
+	response["storage"] = h.storageInfo(storeResult)
+
 	c.JSON(http.StatusOK, response)
 }
 
+// uploadLocation returns the location an uploaded file's ID is derived from. Within a namespace
+// the filename identifies the document, so uploading new content adds a version; without one
+// the content hash is included so unrelated files with the same name never share an ID.
+func uploadLocation(namespace, filename string, content []byte) string {
+	if namespace != "" {
+		return namespace + "/" + filename
+	}
+	return utils.GenerateVersionHash(content) + "/" + filename
+}
+
+// errUploadedFileTooLarge is returned by readUploadedFile for files above the size limit
+var errUploadedFileTooLarge = errors.New("uploaded file too large")
+
+// uploadLimits returns the upload request and file size limits, applying the defaults
+func (h *Handler) uploadLimits() (int64, int64) {
+	maxRequestSize, maxFileSize := h.upload.MaxRequestSize, h.upload.MaxFileSize
+	if maxRequestSize <= 0 {
+		maxRequestSize = DefaultMaxUploadSize
+	}
+	if maxFileSize <= 0 {
+		maxFileSize = DefaultMaxUploadFileSize
+	}
+	return maxRequestSize, maxFileSize
+}
+
+// readUploadedFile reads the full content of an uploaded multipart file of at most maxSize bytes
+func readUploadedFile(fileHeader *multipart.FileHeader, maxSize int64) ([]byte, error) {
+	if fileHeader.Size > maxSize {
+		return nil, errUploadedFileTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, errUploadedFileTooLarge
+	}
+	return content, nil
+}
+
 // GetSources returns information about available data sources
 func (h *Handler) GetSources(c *gin.Context) {
 	staticClient := static.NewClient()
@@ -394,6 +847,14 @@ func (h *Handler) GetSources(c *gin.Context) {
 		},
 	}
 
+	// Add filesystem source
+	sources = append(sources, map[string]interface{}{
+		"name":        "filesystem",
+		"description": "Files read from configured host directories",
+		"types":       staticClient.GetSupportedFileTypes(),
+		"available":   h.filesystemClient != nil,
+	})
+
 	// Add msgraph source if available
 	if h.msgraphHandler != nil && h.msgraphHandler.IsConfigured() {
 		sources = append(sources, map[string]interface{}{
@@ -426,6 +887,13 @@ func (h *Handler) GetHealth(c *gin.Context) {
 		},
 	}
 
+	// Check filesystem source availability
+	if h.filesystemClient != nil {
+		health["components"].(gin.H)["filesystem_source"] = "healthy"
+	} else {
+		health["components"].(gin.H)["filesystem_source"] = "not_configured"
+	}
+
 	// Check msgraph handler availability
 	if h.msgraphHandler != nil && h.msgraphHandler.IsConfigured() {
 		health["components"].(gin.H)["msgraph_handler"] = "healthy"
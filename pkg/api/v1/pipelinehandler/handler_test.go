@@ -1,15 +1,22 @@
 package pipelinehandler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/pkg/filesystem"
+	"github.com/ishank09/data-extraction-service/pkg/mongodb"
 	"github.com/ishank09/data-extraction-service/pkg/msgraph"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -25,6 +32,16 @@ func (m *MockMSGraphClient) GetOneNoteDataAsJSON(ctx context.Context) (*types.Do
 	return args.Get(0).(*types.DocumentCollection), args.Error(1)
 }
 
+func (m *MockMSGraphClient) SyncOneNoteData(ctx context.Context, full bool) (*msgraph.OneNoteSyncResult, error) {
+	args := m.Called(ctx, full)
+	return args.Get(0).(*msgraph.OneNoteSyncResult), args.Error(1)
+}
+
+func (m *MockMSGraphClient) CommitSync(ctx context.Context, result *msgraph.OneNoteSyncResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -74,6 +91,50 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestNew_CheckpointStore tests which checkpoint store the clients created from request tokens use
+func TestNew_CheckpointStore(t *testing.T) {
+	configured := msgraph.NewMemoryCheckpointStore()
+	graphStore := msgraph.NewMemoryCheckpointStore()
+
+	tests := []struct {
+		name     string
+		config   *Config
+		expected msgraph.CheckpointStore
+	}{
+		{
+			name:     "uses the configured store without msgraph config",
+			config:   &Config{CheckpointStore: configured},
+			expected: configured,
+		},
+		{
+			name: "prefers the store of the msgraph config",
+			config: &Config{
+				MSGraphConfig: &msgraph.Config{
+					ClientID:        "test-client-id",
+					ClientSecret:    "test-client-secret",
+					TenantID:        "test-tenant-id",
+					CheckpointStore: graphStore,
+				},
+				CheckpointStore: configured,
+			},
+			expected: graphStore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := New(tt.config)
+			assert.NoError(t, err)
+			assert.Same(t, tt.expected, handler.msgraphConfig.CheckpointStore)
+		})
+	}
+
+	// Without a configured store, all requests of a handler share one in-memory store
+	handler, err := New(nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, handler.msgraphConfig.CheckpointStore)
+}
+
 func TestNewWithMSGraphClient(t *testing.T) {
 	mockClient := &MockMSGraphClient{}
 	handler := NewWithMSGraphClient(mockClient)
@@ -230,6 +291,446 @@ func TestHandler_ExtractDataBySource(t *testing.T) {
 	}
 }
 
+func TestHandler_SyncOneNote(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		setupMock        func(*MockMSGraphClient)
+		useMSGraphClient bool
+		expectedStatus   int
+		expectedFull     bool
+	}{
+		{
+			name:  "returns changed pages from an incremental sync",
+			query: "",
+			setupMock: func(m *MockMSGraphClient) {
+				collection := types.NewDocumentCollection("OneNote")
+				collection.AddDocument(types.Document{ID: "page-1", Type: "page"})
+				m.On("SyncOneNoteData", mock.Anything, false).Return(&msgraph.OneNoteSyncResult{
+					Collection:         collection,
+					AddedPageIDs:       []string{"page-1"},
+					ChangedPageIDs:     []string{},
+					DeletedPageIDs:     []string{"page-2"},
+					DeletedDocumentIDs: []string{"page-2"},
+					UnchangedPages:     3,
+					DeletionsDetected:  true,
+				}, nil)
+				m.On("CommitSync", mock.Anything, mock.Anything).Return(nil)
+			},
+			useMSGraphClient: true,
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:  "forces a full sync",
+			query: "?full=true",
+			setupMock: func(m *MockMSGraphClient) {
+				m.On("SyncOneNoteData", mock.Anything, true).Return(&msgraph.OneNoteSyncResult{
+					Collection: types.NewDocumentCollection("OneNote"),
+					FullSync:   true,
+				}, nil)
+				m.On("CommitSync", mock.Anything, mock.Anything).Return(nil)
+			},
+			useMSGraphClient: true,
+			expectedStatus:   http.StatusOK,
+			expectedFull:     true,
+		},
+		{
+			name:  "returns internal server error when sync fails",
+			query: "",
+			setupMock: func(m *MockMSGraphClient) {
+				m.On("SyncOneNoteData", mock.Anything, false).Return((*msgraph.OneNoteSyncResult)(nil), errors.New("sync error"))
+			},
+			useMSGraphClient: true,
+			expectedStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:             "returns bad request for invalid full parameter",
+			query:            "?full=maybe",
+			useMSGraphClient: true,
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name:           "returns service unavailable when msgraph not configured",
+			query:          "",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handler *Handler
+			mockClient := &MockMSGraphClient{}
+			if tt.useMSGraphClient {
+				if tt.setupMock != nil {
+					tt.setupMock(mockClient)
+				}
+				handler = NewWithMSGraphClient(mockClient)
+			} else {
+				handler, _ = New(nil)
+			}
+
+			router := setupRouter()
+			router.POST("/pipeline/sync/onenote", handler.SyncOneNote)
+
+			req := httptest.NewRequest(http.MethodPost, "/pipeline/sync/onenote"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockClient.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedFull, response["full_sync"])
+				assert.Contains(t, response, "deleted_document_ids")
+				assert.Equal(t, true, response["checkpoint_saved"])
+			}
+		})
+	}
+}
+
+// deleteFailingClient fails every delete. Methods not overridden panic through the nil
+// embedded interface.
+type deleteFailingClient struct {
+	mongodb.Interface
+	err error
+}
+
+func (f *deleteFailingClient) DeleteMany(ctx context.Context, collection string, filter interface{}) (*mongodb.DeleteResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &mongodb.DeleteResult{}, nil
+}
+
+func TestHandler_SyncOneNote_CommitsCheckpoint(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleteErr      error
+		expectedCommit bool
+	}{
+		{
+			name:           "commits when deleted pages were never stored",
+			expectedCommit: true,
+		},
+		{
+			name:           "keeps the checkpoint when deleting fails",
+			deleteErr:      errors.New("connection reset"),
+			expectedCommit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockMSGraphClient{}
+			mockClient.On("SyncOneNoteData", mock.Anything, false).Return(&msgraph.OneNoteSyncResult{
+				Collection:         types.NewDocumentCollection("OneNote"),
+				DeletedPageIDs:     []string{"page-2"},
+				DeletedDocumentIDs: []string{"page-2"},
+				DeletionsDetected:  true,
+			}, nil)
+			if tt.expectedCommit {
+				mockClient.On("CommitSync", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			handler := NewWithMSGraphClient(mockClient)
+			handler.documentService = mongodb.NewDocumentService(&deleteFailingClient{err: tt.deleteErr})
+
+			router := setupRouter()
+			router.POST("/pipeline/sync/onenote", handler.SyncOneNote)
+
+			req := httptest.NewRequest(http.MethodPost, "/pipeline/sync/onenote", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockClient.AssertExpectations(t)
+			if !tt.expectedCommit {
+				mockClient.AssertNotCalled(t, "CommitSync", mock.Anything, mock.Anything)
+				assert.Contains(t, w.Header().Get("X-Storage-Warning"), "page-2")
+			}
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCommit, response["checkpoint_saved"])
+		})
+	}
+}
+
+func TestHandler_StoredUserToken(t *testing.T) {
+	vault, err := msgraph.NewTokenVault(msgraph.NewMemoryTokenStore(), bytes.Repeat([]byte{7}, 32), nil)
+	assert.NoError(t, err)
+	secret, err := vault.Store(context.Background(), "user-1", &msgraph.TokenResponse{AccessToken: "access", ExpiresIn: 3600})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		tokenVault     *msgraph.TokenVault
+		method         string
+		path           string
+		secret         string
+		expectedStatus int
+	}{
+		{
+			name:           "returns service unavailable when token vault not configured",
+			method:         http.MethodGet,
+			path:           "/pipeline/msgraph?user_key=user-1",
+			secret:         secret,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "returns unauthorized without the user secret",
+			tokenVault:     vault,
+			method:         http.MethodGet,
+			path:           "/pipeline/msgraph?user_key=user-1",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "returns unauthorized for a wrong user secret",
+			tokenVault:     vault,
+			method:         http.MethodPost,
+			path:           "/pipeline/sync/onenote?user_key=user-1",
+			secret:         "guessed",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "returns unauthorized for a user without stored token",
+			tokenVault:     vault,
+			method:         http.MethodGet,
+			path:           "/pipeline/msgraph?user_key=user-2",
+			secret:         secret,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := New(nil)
+			if tt.tokenVault != nil {
+				handler.SetTokenVault(tt.tokenVault)
+			}
+
+			router := setupRouter()
+			router.GET("/pipeline/:source", handler.ExtractDataBySource)
+			router.POST("/pipeline/sync/onenote", handler.SyncOneNote)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.secret != "" {
+				req.Header.Set(msgraph.UserSecretHeader, tt.secret)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_ExtractDataBySource_Filesystem(t *testing.T) {
+	root := t.TempDir()
+	err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("notes from a shared drive"), 0o644)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		config         *Config
+		expectedStatus int
+		expectedDocs   int
+	}{
+		{
+			name: "returns filesystem documents when directories are configured",
+			config: &Config{
+				FilesystemConfig: &filesystem.Config{Roots: []string{root}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedDocs:   1,
+		},
+		{
+			name:           "returns service unavailable when filesystem not configured",
+			config:         nil,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := New(tt.config)
+			assert.NoError(t, err)
+
+			router := setupRouter()
+			router.GET("/pipeline/:source", handler.ExtractDataBySource)
+
+			req := httptest.NewRequest(http.MethodGet, "/pipeline/filesystem", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response types.DocumentCollection
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "filesystem", response.Source)
+				assert.Len(t, response.Documents, tt.expectedDocs)
+			}
+		})
+	}
+}
+
+func TestHandler_ExtractDataBySource_OneNoteFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectFetch    bool
+	}{
+		{
+			name:           "extracts with notebook and section filters",
+			query:          "?include_notebooks=Work,Personal&exclude_sections=Archive&page_title=%5EMeeting",
+			expectedStatus: http.StatusOK,
+			expectFetch:    true,
+		},
+		{
+			name:           "returns bad request for invalid page title pattern",
+			query:          "?page_title=%28",
+			expectedStatus: http.StatusBadRequest,
+			expectFetch:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockMSGraphClient{}
+			if tt.expectFetch {
+				mockClient.On("GetOneNoteDataAsJSON", mock.Anything).Return(types.NewDocumentCollection("OneNote"), nil)
+			}
+			handler := NewWithMSGraphClient(mockClient)
+
+			router := setupRouter()
+			router.GET("/pipeline/:source", handler.ExtractDataBySource)
+
+			req := httptest.NewRequest(http.MethodGet, "/pipeline/onenote"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectFetch {
+				mockClient.AssertExpectations(t)
+			} else {
+				mockClient.AssertNotCalled(t, "GetOneNoteDataAsJSON", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandler_ExtractDataBySource_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*MockMSGraphClient)
+		expectedStatus string
+		expectedErrors int
+	}{
+		{
+			name: "reports complete extraction",
+			setupMock: func(m *MockMSGraphClient) {
+				collection := types.NewDocumentCollection("OneNote")
+				collection.AddDocument(types.Document{ID: "page_1"})
+				m.On("GetOneNoteDataAsJSON", mock.Anything).Return(collection, nil)
+			},
+			expectedStatus: types.CollectionStatusComplete,
+			expectedErrors: 0,
+		},
+		{
+			name: "reports partial extraction with errors",
+			setupMock: func(m *MockMSGraphClient) {
+				collection := types.NewDocumentCollection("OneNote")
+				collection.AddDocument(types.Document{ID: "page_1"})
+				collection.AddError(types.Issue{ItemID: "page_2", ItemType: "page", Stage: "fetch_content", ErrorClass: "throttled", Retryable: true})
+				m.On("GetOneNoteDataAsJSON", mock.Anything).Return(collection, nil)
+			},
+			expectedStatus: types.CollectionStatusPartial,
+			expectedErrors: 1,
+		},
+		{
+			name: "reports failed extraction without documents",
+			setupMock: func(m *MockMSGraphClient) {
+				collection := types.NewDocumentCollection("OneNote")
+				collection.AddError(types.Issue{ItemType: "section", Stage: "list_sections", ErrorClass: "forbidden"})
+				m.On("GetOneNoteDataAsJSON", mock.Anything).Return(collection, nil)
+			},
+			expectedStatus: types.CollectionStatusFailed,
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockMSGraphClient{}
+			tt.setupMock(mockClient)
+			handler := NewWithMSGraphClient(mockClient)
+
+			router := setupRouter()
+			router.GET("/pipeline/:source", handler.ExtractDataBySource)
+
+			req := httptest.NewRequest(http.MethodGet, "/pipeline/msgraph", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Status   string        `json:"status"`
+				Errors   []types.Issue `json:"errors"`
+				Warnings []types.Issue `json:"warnings"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			assert.Len(t, response.Errors, tt.expectedErrors)
+			assert.NotNil(t, response.Warnings)
+		})
+	}
+}
+
+func TestHandler_MergeDataCollections(t *testing.T) {
+	first := types.NewDocumentCollection("static")
+	first.AddDocument(types.Document{ID: "doc_1"})
+	first.AddWarning(types.Issue{ItemID: "file_1", ItemType: "file"})
+
+	second := types.NewDocumentCollection("OneNote")
+	second.AddError(types.Issue{ItemID: "page_1", ItemType: "page"})
+
+	merged := (&Handler{}).mergeDataCollections(first, nil, second)
+
+	assert.Len(t, merged.Documents, 1)
+	assert.Len(t, merged.Errors, 1)
+	assert.Len(t, merged.Warnings, 1)
+	assert.Equal(t, types.CollectionStatusPartial, merged.Status())
+}
+
+func TestParseOneNoteFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/pipeline/onenote?include_notebooks=Work,%20Personal&include_notebooks=nb-3&exclude_sections=Archive,,&page_title=Meeting", nil)
+
+	filter := parseOneNoteFilter(c)
+
+	assert.Equal(t, []string{"Work", "Personal", "nb-3"}, filter.IncludeNotebooks)
+	assert.Empty(t, filter.ExcludeNotebooks)
+	assert.Empty(t, filter.IncludeSections)
+	assert.Equal(t, []string{"Archive"}, filter.ExcludeSections)
+	assert.Equal(t, "Meeting", filter.PageTitlePattern)
+}
+
 func TestHandler_ExtractDataByType(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -281,6 +782,144 @@ func TestHandler_ExtractDataByType(t *testing.T) {
 	}
 }
 
+func TestHandler_UploadDocuments(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		upload           *UploadConfig
+		expectedStatus   int
+		expectedDocCount int
+	}{
+		{
+			name: "processes uploaded text and csv files",
+			files: map[string]string{
+				"notes.txt": "Meeting notes for the quarterly review",
+				"data.csv":  "name,value\nalpha,1\n",
+			},
+			expectedStatus:   http.StatusOK,
+			expectedDocCount: 2,
+		},
+		{
+			name: "rejects unsupported file types",
+			files: map[string]string{
+				"binary.exe": "MZ",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects requests without files",
+			files:          map[string]string{},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "rejects files above the file size limit",
+			files: map[string]string{
+				"notes.txt": strings.Repeat("a", 65),
+			},
+			upload:         &UploadConfig{MaxFileSize: 64},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "rejects requests above the request size limit",
+			files: map[string]string{
+				"notes.txt": strings.Repeat("a", 2048),
+			},
+			upload:         &UploadConfig{MaxRequestSize: 1024},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "accepts files within the limits",
+			files: map[string]string{
+				"notes.txt": strings.Repeat("a", 64),
+			},
+			upload:           &UploadConfig{MaxRequestSize: 1024, MaxFileSize: 64},
+			expectedStatus:   http.StatusOK,
+			expectedDocCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := New(&Config{Upload: tt.upload})
+
+			router := setupRouter()
+			router.POST("/pipeline/upload", handler.UploadDocuments)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			for filename, content := range tt.files {
+				part, err := writer.CreateFormFile("files", filename)
+				assert.NoError(t, err)
+				_, err = part.Write([]byte(content))
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, writer.Close())
+
+			req := httptest.NewRequest(http.MethodPost, "/pipeline/upload", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response types.DocumentCollection
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "upload", response.Source)
+				assert.Len(t, response.Documents, tt.expectedDocCount)
+				for _, doc := range response.Documents {
+					assert.Equal(t, "upload", doc.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_UploadDocuments_IDs(t *testing.T) {
+	handler, _ := New(nil)
+	router := setupRouter()
+	router.POST("/pipeline/upload", handler.UploadDocuments)
+
+	upload := func(namespace, content string) types.Document {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if namespace != "" {
+			assert.NoError(t, writer.WriteField("namespace", namespace))
+		}
+		part, err := writer.CreateFormFile("files", "report.txt")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/pipeline/upload", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response types.DocumentCollection
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Documents, 1)
+		return response.Documents[0]
+	}
+
+	// Unrelated uploads that share a filename must not overwrite each other
+	first := upload("", "first report")
+	second := upload("", "second report")
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, first.ID, upload("", "first report").ID)
+
+	// Within a namespace the filename identifies the document so new content becomes a version
+	v1 := upload("team-a", "first report")
+	v2 := upload("team-a", "second report")
+	assert.Equal(t, v1.ID, v2.ID)
+	assert.Equal(t, "team-a/report.txt", v1.Location)
+	assert.NotEqual(t, v1.ID, upload("team-b", "first report").ID)
+}
+
 func TestHandler_GetSources(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -291,13 +930,13 @@ func TestHandler_GetSources(t *testing.T) {
 		{
 			name:             "returns sources without msgraph",
 			useMSGraphClient: false,
-			expectedSources:  2,
+			expectedSources:  3,
 			expectMSGraph:    false,
 		},
 		{
 			name:             "returns sources with msgraph",
 			useMSGraphClient: true,
-			expectedSources:  2,
+			expectedSources:  3,
 			expectMSGraph:    true,
 		},
 	}
@@ -0,0 +1,352 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/internal/utils"
+	"github.com/ishank09/data-extraction-service/pkg/static"
+)
+
+// SymlinkPolicy controls how symbolic links are treated during a walk
+type SymlinkPolicy string
+
+const (
+	// SymlinkPolicySkip ignores symbolic links entirely
+	SymlinkPolicySkip SymlinkPolicy = "skip"
+	// SymlinkPolicyFollow resolves symbolic links to files and directories
+	SymlinkPolicyFollow SymlinkPolicy = "follow"
+)
+
+// Config represents the configuration for the filesystem source
+type Config struct {
+	Roots         []string      // Host directories to walk
+	Include       []string      // Glob patterns a file must match (empty = all supported files)
+	Exclude       []string      // Glob patterns that exclude files and directories
+	MaxDepth      int           // Maximum directory depth below each root (0 = unlimited)
+	SymlinkPolicy SymlinkPolicy // How symbolic links are handled
+}
+
+// Validate checks that the configuration is usable
+func (c *Config) Validate() error {
+	switch c.SymlinkPolicy {
+	case "", SymlinkPolicySkip, SymlinkPolicyFollow:
+	default:
+		return fmt.Errorf("unsupported symlink policy: %s", c.SymlinkPolicy)
+	}
+
+	for _, pattern := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// IsConfigured returns true if at least one root directory is configured
+func (c *Config) IsConfigured() bool {
+	return c != nil && len(c.Roots) > 0
+}
+
+// Client walks host directories and converts supported files into documents
+type Client struct {
+	config       Config
+	staticClient *static.Client
+}
+
+// NewClient creates a new filesystem client
+func NewClient(config Config) (*Client, error) {
+	if len(config.Roots) == 0 {
+		return nil, fmt.Errorf("at least one root directory is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.SymlinkPolicy == "" {
+		config.SymlinkPolicy = SymlinkPolicySkip
+	}
+
+	return &Client{
+		config:       config,
+		staticClient: static.NewClient(),
+	}, nil
+}
+
+// Extraction stages reported in collection issues
+const (
+	stageListDirectory = "list_directory"
+	stageReadFile      = "read_file"
+	stageProcessFile   = "process_file"
+)
+
+// GetAllDataAsJSON walks all configured roots and returns the supported files as documents.
+// Only an unusable root fails the walk; unreadable directories and files that cannot be read
+// or processed are reported as collection errors and skipped.
+func (c *Client) GetAllDataAsJSON(ctx context.Context) (*types.DocumentCollection, error) {
+	collection := types.NewDocumentCollection("filesystem")
+
+	for _, root := range c.config.Roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve root %s: %w", root, err)
+		}
+
+		files, issues, err := c.walkRoot(ctx, absRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk root %s: %w", root, err)
+		}
+		for _, issue := range issues {
+			collection.AddError(issue)
+		}
+
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			doc, issue := c.processFile(ctx, absRoot, file)
+			if issue != nil {
+				collection.AddError(*issue)
+				continue
+			}
+			collection.AddDocument(*doc)
+		}
+	}
+
+	return collection, nil
+}
+
+// ListFiles returns the paths of all files that would be processed
+func (c *Client) ListFiles(ctx context.Context) ([]string, error) {
+	var paths []string
+
+	for _, root := range c.config.Roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve root %s: %w", root, err)
+		}
+
+		files, _, err := c.walkRoot(ctx, absRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk root %s: %w", root, err)
+		}
+
+		for _, file := range files {
+			paths = append(paths, file.path)
+		}
+	}
+
+	return paths, nil
+}
+
+// walkedFile is a file selected by the walk along with its path relative to the root
+type walkedFile struct {
+	path    string // Path on the host (as seen through any followed symlinks)
+	relPath string // Slash-separated path relative to the root
+}
+
+// walkRoot recursively collects supported files below root honouring depth, globs and symlink policy.
+// Directories below root that cannot be read are returned as issues rather than failing the walk.
+func (c *Client) walkRoot(ctx context.Context, root string) ([]walkedFile, []types.Issue, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	var files []walkedFile
+	var issues []types.Issue
+	visited := make(map[string]bool)
+
+	var walkDir func(dir, relDir string, depth int) error
+	walkDir = func(dir, relDir string, depth int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Guard against symlink cycles by tracking resolved directories
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			if visited[realDir] {
+				return nil
+			}
+			visited[realDir] = true
+		}
+
+		var entries []os.DirEntry
+		if err == nil {
+			entries, err = os.ReadDir(dir)
+		}
+		if err != nil {
+			if depth == 0 {
+				return fmt.Errorf("failed to read directory %s: %w", dir, err)
+			}
+			issues = append(issues, newIssue("directory", dir, stageListDirectory, err))
+			return nil
+		}
+
+		for _, entry := range entries {
+			entryPath := filepath.Join(dir, entry.Name())
+			relPath := path.Join(relDir, entry.Name())
+
+			mode := entry.Type()
+			if mode&fs.ModeSymlink != 0 {
+				if c.config.SymlinkPolicy != SymlinkPolicyFollow {
+					continue
+				}
+				target, err := os.Stat(entryPath)
+				if err != nil {
+					// Dangling symlinks are skipped rather than failing the walk
+					continue
+				}
+				mode = target.Mode()
+			}
+
+			if c.isExcluded(relPath) {
+				continue
+			}
+
+			if mode.IsDir() {
+				if c.config.MaxDepth > 0 && depth+1 > c.config.MaxDepth {
+					continue
+				}
+				if err := walkDir(entryPath, relPath, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !mode.IsRegular() || !c.isIncluded(relPath) {
+				continue
+			}
+
+			if _, err := c.staticClient.DetectFileType(entry.Name(), ""); err != nil {
+				continue
+			}
+
+			files = append(files, walkedFile{path: entryPath, relPath: relPath})
+		}
+
+		return nil
+	}
+
+	if err := walkDir(root, "", 0); err != nil {
+		return nil, nil, err
+	}
+
+	return files, issues, nil
+}
+
+// processFile reads a walked file and converts it to a document with the static processors,
+// returning an issue instead when the file cannot be read or processed
+func (c *Client) processFile(ctx context.Context, root string, file walkedFile) (*types.Document, *types.Issue) {
+	content, err := os.ReadFile(file.path)
+	if err != nil {
+		issue := newIssue("file", file.path, stageReadFile, fmt.Errorf("failed to read file %s: %w", file.path, err))
+		return nil, &issue
+	}
+
+	doc, err := c.staticClient.ProcessFile(ctx, filepath.Base(file.path), "", content)
+	if err != nil {
+		issue := newIssue("file", file.path, stageProcessFile, fmt.Errorf("failed to process file %s: %w", file.path, err))
+		return nil, &issue
+	}
+
+	doc.Source = "filesystem"
+	doc.Location = file.path
+	doc.ID = utils.GenerateDocumentID(doc.Type, doc.Source, doc.Location)
+	if doc.Metadata == nil {
+		doc.Metadata = map[string]interface{}{}
+	}
+	doc.Metadata["root"] = root
+	doc.Metadata["relative_path"] = file.relPath
+
+	return doc, nil
+}
+
+// newIssue describes a directory or file that could not be extracted
+func newIssue(itemType, itemID, stage string, err error) types.Issue {
+	issue := types.Issue{
+		ItemID:     itemID,
+		ItemType:   itemType,
+		Stage:      stage,
+		ErrorClass: "unknown",
+		Message:    err.Error(),
+	}
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		issue.ErrorClass = "forbidden"
+	case errors.Is(err, fs.ErrNotExist):
+		// Removed while the walk was running
+		issue.ErrorClass = "not_found"
+		issue.Retryable = true
+	case stage == stageProcessFile:
+		issue.ErrorClass = "unsupported"
+	}
+	return issue
+}
+
+// isIncluded reports whether a file matches the include globs (all files when none are configured)
+func (c *Client) isIncluded(relPath string) bool {
+	if len(c.config.Include) == 0 {
+		return true
+	}
+	return matchAny(c.config.Include, relPath)
+}
+
+// isExcluded reports whether a file or directory matches any exclude glob
+func (c *Client) isExcluded(relPath string) bool {
+	return matchAny(c.config.Exclude, relPath)
+}
+
+// matchAny reports whether relPath matches any pattern. Patterns without a slash
+// match against the base name; patterns with a slash match the full relative path
+// and may use "**" to span any number of directories.
+func matchAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(relPath)); ok {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches slash-separated glob segments, treating "**" as zero or more segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,267 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// createTestTree creates a directory tree with supported and unsupported files
+func createTestTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	files := map[string]string{
+		"readme.txt":                 "top level notes",
+		"data.csv":                   "name,value\nalpha,1\n",
+		"image.png":                  "not a supported type",
+		"docs/guide.html":            "<html><body><p>Guide</p></body></html>",
+		"docs/deep/nested/notes.txt": "deeply nested notes",
+		"tmp/scratch.txt":            "scratch file",
+	}
+
+	for relPath, content := range files {
+		fullPath := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	return root
+}
+
+func relativePaths(t *testing.T, root string, paths []string) []string {
+	t.Helper()
+	var rel []string
+	for _, p := range paths {
+		r, err := filepath.Rel(root, p)
+		if err != nil {
+			t.Fatalf("failed to compute relative path: %v", err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+	return rel
+}
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient(Config{}); err == nil {
+		t.Error("NewClient() should require at least one root")
+	}
+
+	if _, err := NewClient(Config{Roots: []string{"."}, SymlinkPolicy: "sometimes"}); err == nil {
+		t.Error("NewClient() should reject unknown symlink policies")
+	}
+
+	client, err := NewClient(Config{Roots: []string{"."}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.config.SymlinkPolicy != SymlinkPolicySkip {
+		t.Errorf("Expected default symlink policy 'skip', got '%s'", client.config.SymlinkPolicy)
+	}
+}
+
+func TestClient_ListFiles(t *testing.T) {
+	root := createTestTree(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected []string
+	}{
+		{
+			name:   "walks recursively and skips unsupported files",
+			config: Config{Roots: []string{root}},
+			expected: []string{
+				"data.csv",
+				"docs/deep/nested/notes.txt",
+				"docs/guide.html",
+				"readme.txt",
+				"tmp/scratch.txt",
+			},
+		},
+		{
+			name:     "applies include globs",
+			config:   Config{Roots: []string{root}, Include: []string{"*.txt"}},
+			expected: []string{"docs/deep/nested/notes.txt", "readme.txt", "tmp/scratch.txt"},
+		},
+		{
+			name:     "applies path globs with double star",
+			config:   Config{Roots: []string{root}, Include: []string{"docs/**/*.txt"}},
+			expected: []string{"docs/deep/nested/notes.txt"},
+		},
+		{
+			name:   "applies exclude globs to directories",
+			config: Config{Roots: []string{root}, Exclude: []string{"tmp", "deep"}},
+			expected: []string{
+				"data.csv",
+				"docs/guide.html",
+				"readme.txt",
+			},
+		},
+		{
+			name:   "limits walk depth",
+			config: Config{Roots: []string{root}, MaxDepth: 1},
+			expected: []string{
+				"data.csv",
+				"docs/guide.html",
+				"readme.txt",
+				"tmp/scratch.txt",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.config)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			files, err := client.ListFiles(ctx)
+			if err != nil {
+				t.Fatalf("ListFiles() error = %v", err)
+			}
+
+			got := relativePaths(t, root, files)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected files %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected files %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestClient_SymlinkPolicy(t *testing.T) {
+	root := t.TempDir()
+	external := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(external, "shared.txt"), []byte("shared"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Symlink(external, filepath.Join(root, "linked")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	// A link back to the root must not cause an infinite walk
+	if err := os.Symlink(root, filepath.Join(external, "loop")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	ctx := context.Background()
+
+	skipClient, err := NewClient(Config{Roots: []string{root}, SymlinkPolicy: SymlinkPolicySkip})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	files, err := skipClient.ListFiles(ctx)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected symlinks to be skipped, got %v", files)
+	}
+
+	followClient, err := NewClient(Config{Roots: []string{root}, SymlinkPolicy: SymlinkPolicyFollow})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	files, err = followClient.ListFiles(ctx)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != "shared.txt" {
+		t.Errorf("Expected linked file to be followed once, got %v", files)
+	}
+}
+
+func TestClient_GetAllDataAsJSON(t *testing.T) {
+	root := createTestTree(t)
+	ctx := context.Background()
+
+	client, err := NewClient(Config{Roots: []string{root}, Include: []string{"readme.txt"}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	collection, err := client.GetAllDataAsJSON(ctx)
+	if err != nil {
+		t.Fatalf("GetAllDataAsJSON() error = %v", err)
+	}
+
+	if collection.Source != "filesystem" {
+		t.Errorf("Expected collection source 'filesystem', got '%s'", collection.Source)
+	}
+	if collection.GetDocumentCount() != 1 {
+		t.Fatalf("Expected 1 document, got %d", collection.GetDocumentCount())
+	}
+
+	doc := collection.Documents[0]
+	if doc.Source != "filesystem" {
+		t.Errorf("Expected document source 'filesystem', got '%s'", doc.Source)
+	}
+	if doc.Type != "txt" {
+		t.Errorf("Expected document type 'txt', got '%s'", doc.Type)
+	}
+	if doc.Location != filepath.Join(root, "readme.txt") {
+		t.Errorf("Expected location to be the host path, got '%s'", doc.Location)
+	}
+	if doc.Metadata["relative_path"] != "readme.txt" {
+		t.Errorf("Expected relative_path 'readme.txt', got '%v'", doc.Metadata["relative_path"])
+	}
+
+	// Re-extracting the same file must yield the same identity
+	again, err := client.GetAllDataAsJSON(ctx)
+	if err != nil {
+		t.Fatalf("GetAllDataAsJSON() error = %v", err)
+	}
+	if again.Documents[0].ID != doc.ID {
+		t.Errorf("Expected stable document ID '%s', got '%s'", doc.ID, again.Documents[0].ID)
+	}
+	if again.Documents[0].VersionHash != doc.VersionHash {
+		t.Errorf("Expected stable version hash '%s', got '%s'", doc.VersionHash, again.Documents[0].VersionHash)
+	}
+}
+
+func TestClient_GetAllDataAsJSON_MissingRoot(t *testing.T) {
+	client, err := NewClient(Config{Roots: []string{filepath.Join(t.TempDir(), "missing")}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.GetAllDataAsJSON(context.Background()); err == nil {
+		t.Error("GetAllDataAsJSON() should return an error for a missing root")
+	}
+}
+
+func TestClient_ProcessFile_ReportsIssue(t *testing.T) {
+	root := createTestTree(t)
+	client, err := NewClient(Config{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	// A file removed between the walk and the read is reported instead of failing the walk
+	missing := filepath.Join(root, "docs", "removed.txt")
+	doc, issue := client.processFile(context.Background(), root, walkedFile{path: missing, relPath: "docs/removed.txt"})
+	if doc != nil || issue == nil {
+		t.Fatalf("Expected an issue for a missing file, got document %v", doc)
+	}
+	if issue.ItemID != missing || issue.ItemType != "file" || issue.Stage != stageReadFile {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	if issue.ErrorClass != "not_found" || !issue.Retryable {
+		t.Errorf("Expected a retryable not_found issue, got %+v", issue)
+	}
+}
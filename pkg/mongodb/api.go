@@ -2,10 +2,12 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -93,17 +95,24 @@ type Interface interface {
 
 	// READ operations
 	FindOne(ctx context.Context, collection string, filter interface{}) *SingleResult
-	Find(ctx context.Context, collection string, filter interface{}) (*Cursor, error)
+	Find(ctx context.Context, collection string, filter interface{}, opts ...*options.FindOptions) (*Cursor, error)
 	CountDocuments(ctx context.Context, collection string, filter interface{}) (int64, error)
+	Aggregate(ctx context.Context, collection string, pipeline interface{}) (*Cursor, error)
 
 	// UPDATE operations
 	UpdateOne(ctx context.Context, collection string, filter interface{}, update interface{}) (*UpdateResult, error)
 	UpdateMany(ctx context.Context, collection string, filter interface{}, update interface{}) (*UpdateResult, error)
-	ReplaceOne(ctx context.Context, collection string, filter interface{}, replacement interface{}) (*UpdateResult, error)
+	ReplaceOne(ctx context.Context, collection string, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*UpdateResult, error)
 
 	// DELETE operations
 	DeleteOne(ctx context.Context, collection string, filter interface{}) (*DeleteResult, error)
 	DeleteMany(ctx context.Context, collection string, filter interface{}) (*DeleteResult, error)
+
+	// INDEX and SCHEMA operations
+	CreateIndexes(ctx context.Context, collection string, models []IndexModel) ([]string, error)
+	DropIndex(ctx context.Context, collection string, name string) error
+	SetIndexExpiry(ctx context.Context, collection string, name string, expireAfterSeconds int32) error
+	SetCollectionValidator(ctx context.Context, collection string, validator interface{}) error
 }
 
 // Result types
@@ -126,7 +135,8 @@ type DeleteResult struct {
 	DeletedCount int64
 }
 
-// IndexModel represents a MongoDB index model
+// IndexModel represents a MongoDB index model.
+// Options, when set, must be a *options.IndexOptions.
 type IndexModel struct {
 	Keys    interface{}
 	Options interface{}
@@ -150,6 +160,15 @@ type Cursor struct {
 	cursor *mongo.Cursor
 }
 
+// NewCursorFromDocuments returns a Cursor over in-memory documents, e.g. for fakes of Interface in tests
+func NewCursorFromDocuments(documents []interface{}) (*Cursor, error) {
+	cursor, err := mongo.NewCursorFromDocuments(documents, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{cursor: cursor}, nil
+}
+
 func (c *Cursor) Next(ctx context.Context) bool {
 	return c.cursor.Next(ctx)
 }
@@ -292,9 +311,9 @@ func (c *Client) FindOne(ctx context.Context, collection string, filter interfac
 	return &SingleResult{result: result}
 }
 
-// Find finds multiple documents
-func (c *Client) Find(ctx context.Context, collection string, filter interface{}) (*Cursor, error) {
-	cursor, err := c.database.Collection(collection).Find(ctx, filter)
+// Find finds multiple documents, applying optional projection, sort, limit and skip options
+func (c *Client) Find(ctx context.Context, collection string, filter interface{}, opts ...*options.FindOptions) (*Cursor, error) {
+	cursor, err := c.database.Collection(collection).Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +326,16 @@ func (c *Client) CountDocuments(ctx context.Context, collection string, filter i
 	return c.database.Collection(collection).CountDocuments(ctx, filter)
 }
 
+// Aggregate runs an aggregation pipeline
+func (c *Client) Aggregate(ctx context.Context, collection string, pipeline interface{}) (*Cursor, error) {
+	cursor, err := c.database.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{cursor: cursor}, nil
+}
+
 // UpdateOne updates a single document
 func (c *Client) UpdateOne(ctx context.Context, collection string, filter interface{}, update interface{}) (*UpdateResult, error) {
 	result, err := c.database.Collection(collection).UpdateOne(ctx, filter, update)
@@ -337,9 +366,9 @@ func (c *Client) UpdateMany(ctx context.Context, collection string, filter inter
 	}, nil
 }
 
-// ReplaceOne replaces a single document
-func (c *Client) ReplaceOne(ctx context.Context, collection string, filter interface{}, replacement interface{}) (*UpdateResult, error) {
-	result, err := c.database.Collection(collection).ReplaceOne(ctx, filter, replacement)
+// ReplaceOne replaces a single document, inserting it when the upsert option is set and nothing matches
+func (c *Client) ReplaceOne(ctx context.Context, collection string, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*UpdateResult, error) {
+	result, err := c.database.Collection(collection).ReplaceOne(ctx, filter, replacement, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -375,3 +404,66 @@ func (c *Client) DeleteMany(ctx context.Context, collection string, filter inter
 		DeletedCount: result.DeletedCount,
 	}, nil
 }
+
+// CreateIndexes creates the given indexes, returning their names. Existing identical indexes are left untouched.
+func (c *Client) CreateIndexes(ctx context.Context, collection string, models []IndexModel) ([]string, error) {
+	indexModels := make([]mongo.IndexModel, 0, len(models))
+	for _, model := range models {
+		indexModel := mongo.IndexModel{Keys: model.Keys}
+		if model.Options != nil {
+			indexOptions, ok := model.Options.(*options.IndexOptions)
+			if !ok {
+				return nil, fmt.Errorf("unsupported index options type %T", model.Options)
+			}
+			indexModel.Options = indexOptions
+		}
+		indexModels = append(indexModels, indexModel)
+	}
+
+	return c.database.Collection(collection).Indexes().CreateMany(ctx, indexModels)
+}
+
+// DropIndex drops the named index. Missing indexes and collections are not an error.
+func (c *Client) DropIndex(ctx context.Context, collection string, name string) error {
+	_, err := c.database.Collection(collection).Indexes().DropOne(ctx, name)
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && (commandErr.Name == "IndexNotFound" || commandErr.Name == "NamespaceNotFound") {
+		return nil
+	}
+	return err
+}
+
+// SetIndexExpiry changes the expireAfterSeconds of an existing TTL index in place
+func (c *Client) SetIndexExpiry(ctx context.Context, collection string, name string, expireAfterSeconds int32) error {
+	command := bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: name},
+			{Key: "expireAfterSeconds", Value: expireAfterSeconds},
+		}},
+	}
+	return c.database.RunCommand(ctx, command).Err()
+}
+
+// SetCollectionValidator installs a $jsonSchema (or other query) validator on a collection,
+// creating the collection first if it does not exist yet
+func (c *Client) SetCollectionValidator(ctx context.Context, collection string, validator interface{}) error {
+	command := bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}
+
+	err := c.database.RunCommand(ctx, command).Err()
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Name == "NamespaceNotFound" {
+		createOptions := options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("moderate").
+			SetValidationAction("error")
+		return c.database.CreateCollection(ctx, collection, createOptions)
+	}
+
+	return err
+}
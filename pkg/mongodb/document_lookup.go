@@ -0,0 +1,164 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrCollectionNotFound is returned when no stored collection matches the requested ID
+	ErrCollectionNotFound = errors.New("document collection not found")
+	// ErrInvalidCollectionID is returned when a collection ID is not a valid ObjectID
+	ErrInvalidCollectionID = errors.New("invalid document collection ID")
+)
+
+// ExpandedDocumentCollection is a stored collection with its documents resolved from DocumentIDs
+type ExpandedDocumentCollection struct {
+	StoredDocumentCollection
+	Documents          []StoredDocument `json:"documents"`
+	MissingDocumentIDs []string         `json:"missing_document_ids,omitempty"`
+}
+
+// DeleteDocumentResult reports what was removed when a document was deleted
+type DeleteDocumentResult struct {
+	DocumentID         string `json:"document_id"`
+	DeletedCount       int64  `json:"deleted_count"`
+	DeletedVersions    int64  `json:"deleted_versions"`
+	UpdatedCollections int64  `json:"updated_collections"`
+}
+
+// GetDocument returns the most recently fetched stored document with the given DocumentID
+func (ds *DocumentService) GetDocument(ctx context.Context, documentID string) (*StoredDocument, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(1)
+
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, bson.M{"document_id": documentID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find document: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return nil, fmt.Errorf("cursor error: %w", err)
+		}
+		return nil, ErrDocumentNotFound
+	}
+
+	var doc StoredDocument
+	if err := cursor.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return &doc, nil
+}
+
+// DeleteDocument removes every stored revision of a document, including its version history,
+// and drops its ID from the collections that referenced it
+func (ds *DocumentService) DeleteDocument(ctx context.Context, documentID string) (*DeleteDocumentResult, error) {
+	filter := bson.M{"document_id": documentID}
+
+	deleted, err := ds.client.DeleteMany(ctx, DocumentsCollectionName, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete document: %w", err)
+	}
+	if deleted.DeletedCount == 0 {
+		return nil, ErrDocumentNotFound
+	}
+
+	versions, err := ds.client.DeleteMany(ctx, DocumentVersionsCollectionName, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete document versions: %w", err)
+	}
+
+	// Recount after removing the ID, as a collection may list it more than once
+	updated, err := ds.client.UpdateMany(ctx, DocumentCollectionsCollectionName,
+		bson.M{"document_ids": documentID},
+		[]bson.M{
+			{"$set": bson.M{"document_ids": bson.M{"$filter": bson.M{
+				"input": "$document_ids",
+				"cond":  bson.M{"$ne": bson.A{"$$this", documentID}},
+			}}}},
+			{"$set": bson.M{"document_count": bson.M{"$size": "$document_ids"}}},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove document from collections: %w", err)
+	}
+
+	return &DeleteDocumentResult{
+		DocumentID:         documentID,
+		DeletedCount:       deleted.DeletedCount,
+		DeletedVersions:    versions.DeletedCount,
+		UpdatedCollections: updated.ModifiedCount,
+	}, nil
+}
+
+// GetDocumentCollection returns a stored collection with its documents expanded in DocumentIDs order.
+// IDs whose documents no longer exist are reported in MissingDocumentIDs.
+func (ds *DocumentService) GetDocumentCollection(ctx context.Context, collectionID string) (*ExpandedDocumentCollection, error) {
+	objectID, err := primitive.ObjectIDFromHex(collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCollectionID, collectionID)
+	}
+
+	var collection StoredDocumentCollection
+	if err := ds.client.FindOne(ctx, DocumentCollectionsCollectionName, bson.M{"_id": objectID}).Decode(&collection); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to find document collection: %w", err)
+	}
+
+	expanded := &ExpandedDocumentCollection{
+		StoredDocumentCollection: collection,
+		Documents:                []StoredDocument{},
+	}
+	if len(collection.DocumentIDs) == 0 {
+		return expanded, nil
+	}
+
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, bson.M{"document_id": bson.M{"$in": collection.DocumentIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find collection documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	documentsByID := make(map[string]StoredDocument)
+	for cursor.Next(ctx) {
+		var doc StoredDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+		// Keep the most recently fetched revision when several share an ID
+		if existing, ok := documentsByID[doc.DocumentID]; !ok || doc.FetchedAt.After(existing.FetchedAt) {
+			documentsByID[doc.DocumentID] = doc
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range collection.DocumentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if doc, ok := documentsByID[id]; ok {
+			expanded.Documents = append(expanded.Documents, doc)
+		} else {
+			expanded.MissingDocumentIDs = append(expanded.MissingDocumentIDs, id)
+		}
+	}
+
+	return expanded, nil
+}
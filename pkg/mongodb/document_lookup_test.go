@@ -0,0 +1,97 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDocumentService_GetDocument(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"document_id": "doc"}, mock.Anything).
+		Return(newTestCursor(t, bson.M{"document_id": "doc", "title": "Found"}), nil)
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"document_id": "missing"}, mock.Anything).
+		Return(newTestCursor(t), nil)
+
+	doc, err := ds.GetDocument(ctx, "doc")
+	require.NoError(t, err)
+	assert.Equal(t, "Found", doc.Title)
+
+	_, err = ds.GetDocument(ctx, "missing")
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+}
+
+func TestDocumentService_DeleteDocument(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	filter := bson.M{"document_id": "doc"}
+	client.On("DeleteMany", ctx, DocumentsCollectionName, filter).Return(&DeleteResult{DeletedCount: 1}, nil)
+	client.On("DeleteMany", ctx, DocumentVersionsCollectionName, filter).Return(&DeleteResult{DeletedCount: 2}, nil)
+	client.On("UpdateMany", ctx, DocumentCollectionsCollectionName, bson.M{"document_ids": "doc"}, mock.MatchedBy(func(update []bson.M) bool {
+		// document_count is recomputed from the remaining IDs rather than decremented
+		return len(update) == 2 && update[1]["$set"].(bson.M)["document_count"].(bson.M)["$size"] == "$document_ids"
+	})).Return(&UpdateResult{MatchedCount: 3, ModifiedCount: 3}, nil)
+
+	result, err := ds.DeleteDocument(ctx, "doc")
+	require.NoError(t, err)
+	assert.Equal(t, &DeleteDocumentResult{DocumentID: "doc", DeletedCount: 1, DeletedVersions: 2, UpdatedCollections: 3}, result)
+	client.AssertExpectations(t)
+
+	missing := &MockClient{}
+	missing.On("DeleteMany", ctx, DocumentsCollectionName, mock.Anything).Return(&DeleteResult{}, nil)
+	_, err = NewDocumentService(missing).DeleteDocument(ctx, "nope")
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	missing.AssertNotCalled(t, "UpdateMany", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDocumentService_GetDocumentCollection(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	collectionID := primitive.NewObjectID()
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client.On("FindOne", ctx, DocumentCollectionsCollectionName, bson.M{"_id": collectionID}).
+		Return(newTestSingleResult(bson.M{"_id": collectionID, "source": "etl_pipeline", "document_ids": []string{"b", "a", "gone"}}))
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"document_id": bson.M{"$in": []string{"b", "a", "gone"}}}, mock.Anything).
+		Return(newTestCursor(t,
+			bson.M{"document_id": "a", "title": "A old", "fetched_at": older},
+			bson.M{"document_id": "a", "title": "A new", "fetched_at": older.Add(time.Hour)},
+			bson.M{"document_id": "b", "title": "B"},
+		), nil)
+
+	collection, err := ds.GetDocumentCollection(ctx, collectionID.Hex())
+	require.NoError(t, err)
+
+	assert.Equal(t, "etl_pipeline", collection.Source)
+	require.Len(t, collection.Documents, 2)
+	assert.Equal(t, "B", collection.Documents[0].Title)
+	assert.Equal(t, "A new", collection.Documents[1].Title)
+	assert.Equal(t, []string{"gone"}, collection.MissingDocumentIDs)
+}
+
+func TestDocumentService_GetDocumentCollection_Errors(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	_, err := ds.GetDocumentCollection(ctx, "not-an-object-id")
+	assert.ErrorIs(t, err, ErrInvalidCollectionID)
+
+	collectionID := primitive.NewObjectID()
+	client.On("FindOne", ctx, DocumentCollectionsCollectionName, bson.M{"_id": collectionID}).Return(newTestSingleResult(nil))
+	_, err = ds.GetDocumentCollection(ctx, collectionID.Hex())
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+}
@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ishank09/data-extraction-service/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// TextIndexName is the name of the full-text index over document titles and content
+	TextIndexName = "documents_text"
+
+	searchSnippetCount  = 3
+	searchSnippetRadius = 80
+)
+
+// SearchResult is a document matched by a full-text search
+type SearchResult struct {
+	Document StoredDocument `json:"document"`
+	Score    float64        `json:"score"`
+	Snippets []string       `json:"snippets,omitempty"`
+}
+
+// searchHit decodes a stored document together with its text search score
+type searchHit struct {
+	StoredDocument `bson:",inline"`
+	Score          float64 `bson:"score"`
+}
+
+// textIndexModel returns the full-text index over title and content, weighting title matches higher
+func textIndexModel() IndexModel {
+	return IndexModel{
+		Keys: bson.D{{Key: "title", Value: "text"}, {Key: "content", Value: "text"}},
+		Options: options.Index().
+			SetName(TextIndexName).
+			SetWeights(bson.D{{Key: "title", Value: 5}, {Key: "content", Value: 1}}).
+			SetDefaultLanguage("none"),
+	}
+}
+
+// SearchDocuments runs a full-text search over document titles and content, ranked by relevance.
+// Source, type and fetched_at fields of the filter narrow the results; Limit and Skip page them.
+func (ds *DocumentService) SearchDocuments(ctx context.Context, query string, filter DocumentFilter) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	mongoFilter := buildDocumentFilter(filter)
+	mongoFilter["$text"] = bson.M{"$search": query}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "fetched_at", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	if filter.Skip > 0 {
+		opts.SetSkip(int64(filter.Skip))
+	}
+
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, mongoFilter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	terms := utils.SearchTerms(query)
+	var results []SearchResult
+	for cursor.Next(ctx) {
+		var hit searchHit
+		if err := cursor.Decode(&hit); err != nil {
+			return nil, fmt.Errorf("failed to decode search result: %w", err)
+		}
+
+		snippets := utils.HighlightSnippets(hit.Content, terms, searchSnippetCount, searchSnippetRadius)
+		if len(snippets) == 0 {
+			snippets = utils.HighlightSnippets(hit.Title, terms, 1, searchSnippetRadius)
+		}
+
+		results = append(results, SearchResult{
+			Document: hit.StoredDocument,
+			Score:    hit.Score,
+			Snippets: snippets,
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestDocumentService_SearchDocuments(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	fetchedAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedFilter := bson.M{
+		"source":     "onenote",
+		"fetched_at": bson.M{"$gte": fetchedAfter},
+		"$text":      bson.M{"$search": `quarterly "due date" -draft`},
+	}
+
+	client.On("Find", ctx, DocumentsCollectionName, expectedFilter, mock.MatchedBy(func(opts []*options.FindOptions) bool {
+		return len(opts) == 1 && *opts[0].Limit == 5 && opts[0].Projection != nil
+	})).Return(newTestCursor(t,
+		bson.M{
+			"document_id": "onenote_1",
+			"title":       "Planning",
+			"content":     "Notes. The Quarterly numbers are in. The due date is Friday.",
+			"score":       1.5,
+		},
+		bson.M{
+			"document_id": "onenote_2",
+			"title":       "Quarterly review",
+			"content":     "Nothing relevant here",
+			"score":       0.75,
+		},
+	), nil)
+
+	results, err := ds.SearchDocuments(ctx, `quarterly "due date" -draft`, DocumentFilter{
+		Source:       "onenote",
+		FetchedAfter: fetchedAfter,
+		Limit:        5,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "onenote_1", results[0].Document.DocumentID)
+	assert.Equal(t, 1.5, results[0].Score)
+	require.NotEmpty(t, results[0].Snippets)
+	assert.Contains(t, results[0].Snippets[0], "<em>Quarterly</em>")
+	assert.Contains(t, results[0].Snippets[0], "<em>due date</em>")
+
+	// Falls back to highlighting the title when content has no match
+	assert.Equal(t, []string{"<em>Quarterly</em> review"}, results[1].Snippets)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_SearchDocuments_EmptyQuery(t *testing.T) {
+	_, err := NewDocumentService(&MockClient{}).SearchDocuments(context.Background(), "   ", DocumentFilter{})
+	assert.Error(t, err)
+}
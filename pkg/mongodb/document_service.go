@@ -14,18 +14,50 @@ import (
 const (
 	DocumentsCollectionName           = "documents"
 	DocumentCollectionsCollectionName = "document_collections"
+	DocumentVersionsCollectionName    = "document_versions"
+)
+
+// StoreMode controls how StoreDocumentCollection writes documents
+type StoreMode string
+
+const (
+	// StoreModeUpsert keys documents on DocumentID and only writes new or changed versions
+	StoreModeUpsert StoreMode = "upsert"
+	// StoreModeInsert appends every new version of a document alongside the earlier ones
+	StoreModeInsert StoreMode = "insert"
 )
 
 // DocumentService handles document operations with MongoDB
 type DocumentService struct {
-	client Interface
+	client    Interface
+	storeMode StoreMode
 }
 
 // NewDocumentService creates a new document service
 func NewDocumentService(client Interface) *DocumentService {
 	return &DocumentService{
-		client: client,
+		client:    client,
+		storeMode: StoreModeUpsert,
+	}
+}
+
+// SetStoreMode changes how documents are written by StoreDocumentCollection
+func (ds *DocumentService) SetStoreMode(mode StoreMode) error {
+	switch mode {
+	case StoreModeUpsert, StoreModeInsert:
+		ds.storeMode = mode
+		return nil
+	default:
+		return fmt.Errorf("unsupported store mode: %s", mode)
+	}
+}
+
+// StoreMode returns the mode used by StoreDocumentCollection, defaulting to upsert
+func (ds *DocumentService) StoreMode() StoreMode {
+	if ds.storeMode == "" {
+		return StoreModeUpsert
 	}
+	return ds.storeMode
 }
 
 // StoredDocument represents a document stored in MongoDB
@@ -55,6 +87,9 @@ type StoredDocumentCollection struct {
 	SchemaVersion string             `bson:"schema_version" json:"schema_version"`
 	DocumentCount int                `bson:"document_count" json:"document_count"`
 	DocumentIDs   []string           `bson:"document_ids" json:"document_ids"`
+	Status        string             `bson:"status,omitempty" json:"status,omitempty"` // "complete", "partial" or "failed"
+	ErrorCount    int                `bson:"error_count" json:"error_count"`
+	WarningCount  int                `bson:"warning_count" json:"warning_count"`
 }
 
 // StoreDocumentCollection stores a complete document collection in MongoDB
@@ -64,37 +99,28 @@ func (ds *DocumentService) StoreDocumentCollection(ctx context.Context, collecti
 	}
 
 	var documentIDs []string
-	var storedDocuments []interface{}
+	var storedDocuments []*StoredDocument
 
 	// Convert and prepare documents for storage
 	for _, doc := range collection.Documents {
-		storedDoc := &StoredDocument{
-			DocumentID:           doc.ID,
-			Source:               doc.Source,
-			Type:                 doc.Type,
-			Title:                doc.Title,
-			Location:             doc.Location,
-			CreatedAt:            doc.CreatedAt,
-			FetchedAt:            doc.FetchedAt,
-			StoredAt:             time.Now(),
-			VersionHash:          doc.VersionHash,
-			Language:             doc.Language,
-			TextChunkingStrategy: doc.TextChunkingStrategy,
-			Content:              doc.Content,
-			Metadata:             doc.Metadata,
-		}
-		storedDocuments = append(storedDocuments, storedDoc)
+		storedDocuments = append(storedDocuments, newStoredDocument(doc))
 		documentIDs = append(documentIDs, doc.ID)
 	}
 
-	// Insert documents if any exist
-	var insertedDocumentIDs []interface{}
+	result := &StoreCollectionResult{
+		DocumentCount: len(collection.Documents),
+	}
+
 	if len(storedDocuments) > 0 {
-		result, err := ds.client.InsertMany(ctx, DocumentsCollectionName, storedDocuments)
+		var err error
+		if ds.StoreMode() == StoreModeInsert {
+			err = ds.appendDocuments(ctx, storedDocuments, result)
+		} else {
+			err = ds.upsertDocuments(ctx, storedDocuments, result)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to store documents: %w", err)
+			return nil, err
 		}
-		insertedDocumentIDs = result.InsertedIDs
 	}
 
 	// Store collection metadata
@@ -105,6 +131,9 @@ func (ds *DocumentService) StoreDocumentCollection(ctx context.Context, collecti
 		SchemaVersion: collection.SchemaVersion,
 		DocumentCount: len(collection.Documents),
 		DocumentIDs:   documentIDs,
+		Status:        collection.Status(),
+		ErrorCount:    len(collection.Errors),
+		WarningCount:  len(collection.Warnings),
 	}
 
 	collectionResult, err := ds.client.InsertOne(ctx, DocumentCollectionsCollectionName, storedCollection)
@@ -112,49 +141,234 @@ func (ds *DocumentService) StoreDocumentCollection(ctx context.Context, collecti
 		return nil, fmt.Errorf("failed to store collection metadata: %w", err)
 	}
 
-	return &StoreCollectionResult{
-		CollectionID:        collectionResult.InsertedID,
-		InsertedDocumentIDs: insertedDocumentIDs,
-		DocumentCount:       len(collection.Documents),
-	}, nil
+	result.CollectionID = collectionResult.InsertedID
+	return result, nil
 }
 
-// GetDocuments retrieves documents from MongoDB with optional filtering
-func (ds *DocumentService) GetDocuments(ctx context.Context, filter DocumentFilter) ([]StoredDocument, error) {
-	mongoFilter := bson.M{}
+// newStoredDocument converts a pipeline document into its stored representation
+func newStoredDocument(doc types.Document) *StoredDocument {
+	return &StoredDocument{
+		DocumentID:           doc.ID,
+		Source:               doc.Source,
+		Type:                 doc.Type,
+		Title:                doc.Title,
+		Location:             doc.Location,
+		CreatedAt:            doc.CreatedAt,
+		FetchedAt:            doc.FetchedAt,
+		StoredAt:             time.Now(),
+		VersionHash:          doc.VersionHash,
+		Language:             doc.Language,
+		TextChunkingStrategy: doc.TextChunkingStrategy,
+		Content:              doc.Content,
+		Metadata:             doc.Metadata,
+	}
+}
 
-	if filter.Source != "" {
-		mongoFilter["source"] = filter.Source
+// appendDocuments inserts every document version that is not stored yet.
+// Versions already present are counted as unchanged, as the unique
+// document_id+version_hash index would reject them.
+func (ds *DocumentService) appendDocuments(ctx context.Context, documents []*StoredDocument, result *StoreCollectionResult) error {
+	storedVersions, err := ds.findStoredVersions(ctx, documents)
+	if err != nil {
+		return err
 	}
-	if filter.Type != "" {
-		mongoFilter["type"] = filter.Type
+
+	var newDocuments []*StoredDocument
+	for _, doc := range documents {
+		key := doc.DocumentID + "\x00" + doc.VersionHash
+		if storedVersions[key] {
+			result.UnchangedCount++
+			continue
+		}
+		storedVersions[key] = true
+		newDocuments = append(newDocuments, doc)
 	}
-	if filter.Title != "" {
-		mongoFilter["title"] = bson.M{"$regex": filter.Title, "$options": "i"}
+
+	if len(newDocuments) == 0 {
+		return nil
 	}
-	if !filter.FetchedAfter.IsZero() {
-		mongoFilter["fetched_at"] = bson.M{"$gte": filter.FetchedAfter}
+	return ds.insertDocuments(ctx, newDocuments, result)
+}
+
+// findStoredVersions returns the document_id/version_hash pairs already stored for the given documents
+func (ds *DocumentService) findStoredVersions(ctx context.Context, documents []*StoredDocument) (map[string]bool, error) {
+	ids := make([]string, 0, len(documents))
+	for _, doc := range documents {
+		ids = append(ids, doc.DocumentID)
 	}
-	if !filter.FetchedBefore.IsZero() {
-		if existing, ok := mongoFilter["fetched_at"]; ok {
-			mongoFilter["fetched_at"] = bson.M{"$gte": existing.(bson.M)["$gte"], "$lte": filter.FetchedBefore}
-		} else {
-			mongoFilter["fetched_at"] = bson.M{"$lte": filter.FetchedBefore}
+
+	opts := options.Find().SetProjection(bson.M{"document_id": 1, "version_hash": 1})
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, bson.M{"document_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	versions := make(map[string]bool)
+	for cursor.Next(ctx) {
+		var doc StoredDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode existing document: %w", err)
+		}
+		versions[doc.DocumentID+"\x00"+doc.VersionHash] = true
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return versions, nil
+}
+
+// insertDocuments inserts all documents without checking for existing versions
+func (ds *DocumentService) insertDocuments(ctx context.Context, documents []*StoredDocument, result *StoreCollectionResult) error {
+	toInsert := make([]interface{}, 0, len(documents))
+	for _, doc := range documents {
+		toInsert = append(toInsert, doc)
+	}
+
+	insertResult, err := ds.client.InsertMany(ctx, DocumentsCollectionName, toInsert)
+	if err != nil {
+		return fmt.Errorf("failed to store documents: %w", err)
+	}
+
+	result.InsertedDocumentIDs = insertResult.InsertedIDs
+	result.InsertedCount = len(insertResult.InsertedIDs)
+	return nil
+}
+
+// upsertDocuments writes documents keyed on DocumentID, inserting new IDs,
+// replacing documents whose VersionHash changed and skipping unchanged ones.
+// The replaced revision is archived to the document_versions collection.
+func (ds *DocumentService) upsertDocuments(ctx context.Context, documents []*StoredDocument, result *StoreCollectionResult) error {
+	existingDocuments, err := ds.findExistingDocuments(ctx, documents)
+	if err != nil {
+		return err
+	}
+
+	// Documents repeated within the same collection collapse onto their last occurrence
+	var newDocuments []*StoredDocument
+	pendingInserts := make(map[string]int)
+	upsert := options.Replace().SetUpsert(true)
+
+	for _, doc := range documents {
+		if index, pending := pendingInserts[doc.DocumentID]; pending {
+			newDocuments[index] = doc
+			continue
+		}
+
+		existing, exists := existingDocuments[doc.DocumentID]
+		switch {
+		case !exists:
+			pendingInserts[doc.DocumentID] = len(newDocuments)
+			newDocuments = append(newDocuments, doc)
+		case existing.VersionHash == doc.VersionHash:
+			result.UnchangedCount++
+		default:
+			if err := ds.archiveVersion(ctx, existing); err != nil {
+				return err
+			}
+			filter := bson.M{"document_id": doc.DocumentID}
+			if _, err := ds.client.ReplaceOne(ctx, DocumentsCollectionName, filter, doc); err != nil {
+				return fmt.Errorf("failed to update document %s: %w", doc.DocumentID, err)
+			}
+			existingDocuments[doc.DocumentID] = doc
+			result.UpdatedCount++
+		}
+	}
+
+	// New documents are written with an upsert on document_id rather than inserted, so a
+	// concurrent run storing the same document cannot create a second copy. The unique
+	// document_id index created by EnsureIndexes in upsert mode backs this up.
+	for _, doc := range newDocuments {
+		filter := bson.M{"document_id": doc.DocumentID}
+		updateResult, err := ds.client.ReplaceOne(ctx, DocumentsCollectionName, filter, doc, upsert)
+		if err != nil {
+			return fmt.Errorf("failed to store document %s: %w", doc.DocumentID, err)
 		}
+		if updateResult.UpsertedCount == 0 {
+			// Another run stored the document after it was looked up
+			result.UpdatedCount++
+			continue
+		}
+		result.InsertedDocumentIDs = append(result.InsertedDocumentIDs, updateResult.UpsertedID)
+		result.InsertedCount++
+	}
+
+	return nil
+}
+
+// findExistingDocuments returns the currently stored revision of each of the given documents that already exists
+func (ds *DocumentService) findExistingDocuments(ctx context.Context, documents []*StoredDocument) (map[string]*StoredDocument, error) {
+	ids := make([]string, 0, len(documents))
+	for _, doc := range documents {
+		ids = append(ids, doc.DocumentID)
+	}
+
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, bson.M{"document_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	existing := make(map[string]*StoredDocument)
+	for cursor.Next(ctx) {
+		var doc StoredDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode existing document: %w", err)
+		}
+		existing[doc.DocumentID] = &doc
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return existing, nil
+}
+
+// GetDocuments retrieves documents from MongoDB with optional filtering
+func (ds *DocumentService) GetDocuments(ctx context.Context, filter DocumentFilter) ([]StoredDocument, error) {
+	page, err := ds.GetDocumentsPage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return page.Documents, nil
+}
+
+// GetDocumentsPage retrieves one page of documents ordered by fetched_at descending.
+// When more documents follow, the returned page carries a cursor token for the next page.
+func (ds *DocumentService) GetDocumentsPage(ctx context.Context, filter DocumentFilter) (*DocumentPage, error) {
+	mongoFilter := buildDocumentFilter(filter)
+
+	if filter.Cursor != "" {
+		// The cursor already positions the page; skipping on top of it would drop documents
+		if filter.Skip > 0 {
+			return nil, fmt.Errorf("%w: skip cannot be combined with a cursor", ErrInvalidCursor)
+		}
+		pageCursor, err := decodePageCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		mongoFilter = bson.M{"$and": []bson.M{mongoFilter, pageCursor.filter()}}
 	}
 
 	opts := options.Find()
 	if filter.Limit > 0 {
-		opts.SetLimit(int64(filter.Limit))
+		// Fetch one extra document to find out whether another page follows
+		opts.SetLimit(int64(filter.Limit) + 1)
 	}
 	if filter.Skip > 0 {
 		opts.SetSkip(int64(filter.Skip))
 	}
+	if len(filter.Fields) > 0 {
+		opts.SetProjection(buildProjection(filter.Fields))
+	}
 
-	// Sort by fetched_at descending by default
-	opts.SetSort(bson.M{"fetched_at": -1})
+	// Sort by fetched_at descending by default, with _id as a tie-breaker for stable pages
+	opts.SetSort(bson.D{{Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}})
 
-	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, mongoFilter)
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, mongoFilter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find documents: %w", err)
 	}
@@ -173,7 +387,49 @@ func (ds *DocumentService) GetDocuments(ctx context.Context, filter DocumentFilt
 		return nil, fmt.Errorf("cursor error: %w", err)
 	}
 
-	return documents, nil
+	page := &DocumentPage{Documents: documents}
+	if filter.Limit > 0 && len(documents) > filter.Limit {
+		page.Documents = documents[:filter.Limit]
+		page.NextCursor = newPageCursor(page.Documents[filter.Limit-1]).encode()
+	}
+
+	return page, nil
+}
+
+// buildDocumentFilter translates a DocumentFilter into a MongoDB query
+func buildDocumentFilter(filter DocumentFilter) bson.M {
+	mongoFilter := bson.M{}
+
+	if filter.Source != "" {
+		mongoFilter["source"] = filter.Source
+	}
+	if filter.Type != "" {
+		mongoFilter["type"] = filter.Type
+	}
+	if filter.Title != "" {
+		mongoFilter["title"] = bson.M{"$regex": filter.Title, "$options": "i"}
+	}
+	if !filter.FetchedAfter.IsZero() {
+		mongoFilter["fetched_at"] = bson.M{"$gte": filter.FetchedAfter}
+	}
+	if !filter.FetchedBefore.IsZero() {
+		if existing, ok := mongoFilter["fetched_at"]; ok {
+			mongoFilter["fetched_at"] = bson.M{"$gte": existing.(bson.M)["$gte"], "$lte": filter.FetchedBefore}
+		} else {
+			mongoFilter["fetched_at"] = bson.M{"$lte": filter.FetchedBefore}
+		}
+	}
+
+	return mongoFilter
+}
+
+// buildProjection limits returned documents to the requested fields plus the keys pagination depends on
+func buildProjection(fields []string) bson.M {
+	projection := bson.M{"_id": 1, "document_id": 1, "fetched_at": 1}
+	for _, field := range fields {
+		projection[field] = 1
+	}
+	return projection
 }
 
 // GetDocumentCollections retrieves document collection metadata
@@ -205,7 +461,7 @@ func (ds *DocumentService) GetDocumentCollections(ctx context.Context, filter Co
 	// Sort by fetched_at descending by default
 	opts.SetSort(bson.M{"fetched_at": -1})
 
-	cursor, err := ds.client.Find(ctx, DocumentCollectionsCollectionName, mongoFilter)
+	cursor, err := ds.client.Find(ctx, DocumentCollectionsCollectionName, mongoFilter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find document collections: %w", err)
 	}
@@ -278,17 +534,15 @@ func (ds *DocumentService) GetDocumentStats(ctx context.Context) (*DocumentStats
 	}, nil
 }
 
-// DeleteOldDocuments deletes documents older than the specified duration
+// DeleteOldDocuments deletes documents older than the specified duration, along with the
+// version history and collection references of documents left without any revision
 func (ds *DocumentService) DeleteOldDocuments(ctx context.Context, olderThan time.Duration) (*DeleteResult, error) {
-	cutoffTime := time.Now().Add(-olderThan)
-	filter := bson.M{"fetched_at": bson.M{"$lt": cutoffTime}}
-
-	result, err := ds.client.DeleteMany(ctx, DocumentsCollectionName, filter)
+	report, err := ds.ApplyRetention(ctx, []RetentionPolicy{{Source: RetentionDefaultSource, MaxAge: olderThan}}, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete old documents: %w", err)
 	}
 
-	return result, nil
+	return &DeleteResult{DeletedCount: report.DeletedDocuments}, nil
 }
 
 // Filter types
@@ -300,6 +554,8 @@ type DocumentFilter struct {
 	FetchedBefore time.Time `json:"fetched_before,omitempty"`
 	Limit         int       `json:"limit,omitempty"`
 	Skip          int       `json:"skip,omitempty"`
+	Cursor        string    `json:"cursor,omitempty"` // Opaque token from a previous page's NextCursor
+	Fields        []string  `json:"fields,omitempty"` // Projection; empty returns every field
 }
 
 type CollectionFilter struct {
@@ -315,6 +571,14 @@ type StoreCollectionResult struct {
 	CollectionID        interface{}   `json:"collection_id"`
 	InsertedDocumentIDs []interface{} `json:"inserted_document_ids"`
 	DocumentCount       int           `json:"document_count"`
+	InsertedCount       int           `json:"inserted_count"`
+	UpdatedCount        int           `json:"updated_count"`
+	UnchangedCount      int           `json:"unchanged_count"`
+}
+
+type DocumentPage struct {
+	Documents  []StoredDocument `json:"documents"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 type DocumentStats struct {
@@ -0,0 +1,305 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MockClient is a mock for mongodb.Interface
+type MockClient struct {
+	mock.Mock
+}
+
+func (m *MockClient) Connect(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockClient) Disconnect(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockClient) IsConnected(ctx context.Context) bool {
+	return m.Called(ctx).Bool(0)
+}
+
+func (m *MockClient) Ping(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockClient) Database(name string) *mongo.Database {
+	return nil
+}
+
+func (m *MockClient) GetConfig() *Config {
+	return NewConfig()
+}
+
+func (m *MockClient) InsertOne(ctx context.Context, collection string, document interface{}) (*InsertOneResult, error) {
+	args := m.Called(ctx, collection, document)
+	result, _ := args.Get(0).(*InsertOneResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) InsertMany(ctx context.Context, collection string, documents []interface{}) (*InsertManyResult, error) {
+	args := m.Called(ctx, collection, documents)
+	result, _ := args.Get(0).(*InsertManyResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) FindOne(ctx context.Context, collection string, filter interface{}) *SingleResult {
+	args := m.Called(ctx, collection, filter)
+	return args.Get(0).(*SingleResult)
+}
+
+func (m *MockClient) Find(ctx context.Context, collection string, filter interface{}, opts ...*options.FindOptions) (*Cursor, error) {
+	args := m.Called(ctx, collection, filter, opts)
+	cursor, _ := args.Get(0).(*Cursor)
+	return cursor, args.Error(1)
+}
+
+func (m *MockClient) CountDocuments(ctx context.Context, collection string, filter interface{}) (int64, error) {
+	args := m.Called(ctx, collection, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockClient) Aggregate(ctx context.Context, collection string, pipeline interface{}) (*Cursor, error) {
+	args := m.Called(ctx, collection, pipeline)
+	cursor, _ := args.Get(0).(*Cursor)
+	return cursor, args.Error(1)
+}
+
+func (m *MockClient) UpdateOne(ctx context.Context, collection string, filter interface{}, update interface{}) (*UpdateResult, error) {
+	args := m.Called(ctx, collection, filter, update)
+	result, _ := args.Get(0).(*UpdateResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) UpdateMany(ctx context.Context, collection string, filter interface{}, update interface{}) (*UpdateResult, error) {
+	args := m.Called(ctx, collection, filter, update)
+	result, _ := args.Get(0).(*UpdateResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) ReplaceOne(ctx context.Context, collection string, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*UpdateResult, error) {
+	args := m.Called(ctx, collection, filter, replacement, opts)
+	result, _ := args.Get(0).(*UpdateResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) DeleteOne(ctx context.Context, collection string, filter interface{}) (*DeleteResult, error) {
+	args := m.Called(ctx, collection, filter)
+	result, _ := args.Get(0).(*DeleteResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) DeleteMany(ctx context.Context, collection string, filter interface{}) (*DeleteResult, error) {
+	args := m.Called(ctx, collection, filter)
+	result, _ := args.Get(0).(*DeleteResult)
+	return result, args.Error(1)
+}
+
+func (m *MockClient) CreateIndexes(ctx context.Context, collection string, models []IndexModel) ([]string, error) {
+	args := m.Called(ctx, collection, models)
+	names, _ := args.Get(0).([]string)
+	return names, args.Error(1)
+}
+
+func (m *MockClient) DropIndex(ctx context.Context, collection string, name string) error {
+	return m.Called(ctx, collection, name).Error(0)
+}
+
+func (m *MockClient) SetIndexExpiry(ctx context.Context, collection string, name string, expireAfterSeconds int32) error {
+	return m.Called(ctx, collection, name, expireAfterSeconds).Error(0)
+}
+
+func (m *MockClient) SetCollectionValidator(ctx context.Context, collection string, validator interface{}) error {
+	return m.Called(ctx, collection, validator).Error(0)
+}
+
+// newTestCursor builds a Cursor over in-memory documents
+func newTestCursor(t *testing.T, documents ...interface{}) *Cursor {
+	t.Helper()
+	cursor, err := mongo.NewCursorFromDocuments(documents, nil, nil)
+	require.NoError(t, err)
+	return &Cursor{cursor: cursor}
+}
+
+// isUpsert matches ReplaceOne options that insert the document when nothing matches
+func isUpsert(opts []*options.ReplaceOptions) bool {
+	return len(opts) == 1 && opts[0].Upsert != nil && *opts[0].Upsert
+}
+
+func newTestCollection(docs ...types.Document) *types.DocumentCollection {
+	collection := types.NewDocumentCollection("etl_pipeline")
+	for _, doc := range docs {
+		collection.AddDocument(doc)
+	}
+	return collection
+}
+
+func TestDocumentService_StoreMode(t *testing.T) {
+	ds := NewDocumentService(&MockClient{})
+	assert.Equal(t, StoreModeUpsert, ds.StoreMode())
+	assert.Equal(t, StoreModeUpsert, (&DocumentService{}).StoreMode())
+
+	require.NoError(t, ds.SetStoreMode(StoreModeInsert))
+	assert.Equal(t, StoreModeInsert, ds.StoreMode())
+
+	assert.Error(t, ds.SetStoreMode("append"))
+	assert.Equal(t, StoreModeInsert, ds.StoreMode())
+}
+
+func TestDocumentService_StoreDocumentCollection_Upsert(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	collection := newTestCollection(
+		types.Document{ID: "doc_new", VersionHash: "sha256:new", FetchedAt: time.Now()},
+		types.Document{ID: "doc_changed", VersionHash: "sha256:v2", FetchedAt: time.Now()},
+		types.Document{ID: "doc_same", VersionHash: "sha256:same", FetchedAt: time.Now()},
+	)
+
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"document_id": bson.M{"$in": []string{"doc_new", "doc_changed", "doc_same"}}}, mock.Anything).
+		Return(newTestCursor(t,
+			bson.M{"document_id": "doc_changed", "version_hash": "sha256:v1"},
+			bson.M{"document_id": "doc_same", "version_hash": "sha256:same"},
+		), nil)
+	client.On("InsertOne", ctx, DocumentVersionsCollectionName, mock.MatchedBy(func(version *StoredDocumentVersion) bool {
+		return version.DocumentID == "doc_changed" && version.VersionHash == "sha256:v1"
+	})).Return(&InsertOneResult{InsertedID: "version_id"}, nil)
+	client.On("ReplaceOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc_changed"}, mock.AnythingOfType("*mongodb.StoredDocument"), mock.Anything).
+		Return(&UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+	client.On("ReplaceOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc_new"}, mock.AnythingOfType("*mongodb.StoredDocument"), mock.MatchedBy(isUpsert)).
+		Return(&UpdateResult{UpsertedCount: 1, UpsertedID: "oid_new"}, nil)
+	client.On("InsertOne", ctx, DocumentCollectionsCollectionName, mock.AnythingOfType("*mongodb.StoredDocumentCollection")).
+		Return(&InsertOneResult{InsertedID: "collection_id"}, nil)
+
+	result, err := ds.StoreDocumentCollection(ctx, collection)
+	require.NoError(t, err)
+
+	assert.Equal(t, "collection_id", result.CollectionID)
+	assert.Equal(t, 3, result.DocumentCount)
+	assert.Equal(t, 1, result.InsertedCount)
+	assert.Equal(t, 1, result.UpdatedCount)
+	assert.Equal(t, 1, result.UnchangedCount)
+	assert.Equal(t, []interface{}{"oid_new"}, result.InsertedDocumentIDs)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_StoreDocumentCollection_UpsertDuplicateIDs(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	collection := newTestCollection(
+		types.Document{ID: "doc_dup", VersionHash: "sha256:first"},
+		types.Document{ID: "doc_dup", VersionHash: "sha256:second"},
+	)
+
+	client.On("Find", ctx, DocumentsCollectionName, mock.Anything, mock.Anything).Return(newTestCursor(t), nil)
+	client.On("ReplaceOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc_dup"}, mock.MatchedBy(func(doc *StoredDocument) bool {
+		return doc.VersionHash == "sha256:second"
+	}), mock.MatchedBy(isUpsert)).Return(&UpdateResult{UpsertedCount: 1, UpsertedID: "oid"}, nil)
+	client.On("InsertOne", ctx, DocumentCollectionsCollectionName, mock.Anything).
+		Return(&InsertOneResult{InsertedID: "collection_id"}, nil)
+
+	result, err := ds.StoreDocumentCollection(ctx, collection)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.InsertedCount)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_StoreDocumentCollection_UpsertConcurrentInsert(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	// Another run stores the document between the lookup and the upsert
+	client.On("Find", ctx, DocumentsCollectionName, mock.Anything, mock.Anything).Return(newTestCursor(t), nil)
+	client.On("ReplaceOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc_raced"}, mock.Anything, mock.MatchedBy(isUpsert)).
+		Return(&UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+	client.On("InsertOne", ctx, DocumentCollectionsCollectionName, mock.Anything).
+		Return(&InsertOneResult{InsertedID: "collection_id"}, nil)
+
+	result, err := ds.StoreDocumentCollection(ctx, newTestCollection(types.Document{ID: "doc_raced", VersionHash: "sha256:a"}))
+	require.NoError(t, err)
+	assert.Zero(t, result.InsertedCount)
+	assert.Equal(t, 1, result.UpdatedCount)
+	client.AssertNotCalled(t, "InsertMany", mock.Anything, mock.Anything, mock.Anything)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_StoreDocumentCollection_InsertMode(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+	require.NoError(t, ds.SetStoreMode(StoreModeInsert))
+
+	collection := newTestCollection(
+		types.Document{ID: "doc_1", VersionHash: "sha256:a"},
+		types.Document{ID: "doc_2", VersionHash: "sha256:b"},
+		types.Document{ID: "doc_3", VersionHash: "sha256:c"},
+	)
+
+	// doc_1 is stored with a different version, doc_3 with the same one
+	client.On("Find", ctx, DocumentsCollectionName, mock.Anything, mock.Anything).Return(newTestCursor(t,
+		bson.M{"document_id": "doc_1", "version_hash": "sha256:old"},
+		bson.M{"document_id": "doc_3", "version_hash": "sha256:c"},
+	), nil)
+	client.On("InsertMany", ctx, DocumentsCollectionName, mock.MatchedBy(func(docs []interface{}) bool {
+		return len(docs) == 2
+	})).Return(&InsertManyResult{InsertedIDs: []interface{}{"oid_1", "oid_2"}}, nil)
+	client.On("InsertOne", ctx, DocumentCollectionsCollectionName, mock.Anything).
+		Return(&InsertOneResult{InsertedID: "collection_id"}, nil)
+
+	result, err := ds.StoreDocumentCollection(ctx, collection)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.InsertedCount)
+	assert.Equal(t, 1, result.UnchangedCount)
+	assert.Zero(t, result.UpdatedCount)
+	client.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_StoreDocumentCollection_Status(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	collection := newTestCollection()
+	collection.AddError(types.Issue{ItemID: "page_1", ItemType: "page", Stage: "fetch_content", ErrorClass: "throttled", Retryable: true})
+	collection.AddWarning(types.Issue{ItemID: "resource_1", ItemType: "attachment", ErrorClass: "unsupported"})
+
+	client.On("InsertOne", ctx, DocumentCollectionsCollectionName, mock.MatchedBy(func(stored *StoredDocumentCollection) bool {
+		return stored.Status == types.CollectionStatusFailed && stored.ErrorCount == 1 && stored.WarningCount == 1
+	})).Return(&InsertOneResult{InsertedID: "collection_id"}, nil)
+
+	result, err := ds.StoreDocumentCollection(ctx, collection)
+	require.NoError(t, err)
+	assert.Zero(t, result.DocumentCount)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_StoreDocumentCollection_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewDocumentService(&MockClient{}).StoreDocumentCollection(ctx, nil)
+	assert.Error(t, err)
+
+	client := &MockClient{}
+	client.On("Find", ctx, DocumentsCollectionName, mock.Anything, mock.Anything).Return(nil, errors.New("connection lost"))
+
+	_, err = NewDocumentService(client).StoreDocumentCollection(ctx, newTestCollection(types.Document{ID: "doc"}))
+	assert.ErrorContains(t, err, "connection lost")
+}
@@ -0,0 +1,207 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrDocumentNotFound is returned when no stored document matches the requested ID
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrVersionNotFound is returned when a document has no revision with the requested version hash
+	ErrVersionNotFound = errors.New("document version not found")
+)
+
+// StoredDocumentVersion represents a prior revision of a document kept in the document_versions collection
+type StoredDocumentVersion struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"_id,omitempty"`
+	DocumentID  string                 `bson:"document_id" json:"document_id"`
+	VersionHash string                 `bson:"version_hash" json:"version_hash"`
+	Title       string                 `bson:"title" json:"title"`
+	Location    string                 `bson:"location" json:"location"`
+	FetchedAt   time.Time              `bson:"fetched_at" json:"fetched_at"`
+	StoredAt    time.Time              `bson:"stored_at" json:"stored_at"`
+	ArchivedAt  time.Time              `bson:"archived_at" json:"archived_at"`
+	Content     string                 `bson:"content" json:"content"`
+	Metadata    map[string]interface{} `bson:"metadata" json:"metadata"`
+}
+
+// DocumentVersionInfo summarises one revision of a document without its content
+type DocumentVersionInfo struct {
+	VersionHash string     `json:"version_hash"`
+	Title       string     `json:"title"`
+	FetchedAt   time.Time  `json:"fetched_at"`
+	StoredAt    time.Time  `json:"stored_at"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	Current     bool       `json:"current"`
+}
+
+// DocumentDiff is a line-based diff between two revisions of a document
+type DocumentDiff struct {
+	DocumentID   string           `json:"document_id"`
+	FromVersion  string           `json:"from_version"`
+	ToVersion    string           `json:"to_version"`
+	AddedLines   int              `json:"added_lines"`
+	RemovedLines int              `json:"removed_lines"`
+	TitleChanged bool             `json:"title_changed"`
+	Lines        []utils.DiffLine `json:"lines"`
+}
+
+// archiveVersion copies the currently stored revision of a document into the document_versions collection
+func (ds *DocumentService) archiveVersion(ctx context.Context, doc *StoredDocument) error {
+	version := &StoredDocumentVersion{
+		DocumentID:  doc.DocumentID,
+		VersionHash: doc.VersionHash,
+		Title:       doc.Title,
+		Location:    doc.Location,
+		FetchedAt:   doc.FetchedAt,
+		StoredAt:    doc.StoredAt,
+		ArchivedAt:  time.Now(),
+		Content:     doc.Content,
+		Metadata:    doc.Metadata,
+	}
+
+	if _, err := ds.client.InsertOne(ctx, DocumentVersionsCollectionName, version); err != nil {
+		return fmt.Errorf("failed to archive version of document %s: %w", doc.DocumentID, err)
+	}
+	return nil
+}
+
+// GetDocumentVersions lists the current and prior revisions of a document, newest first
+func (ds *DocumentService) GetDocumentVersions(ctx context.Context, documentID string) ([]DocumentVersionInfo, error) {
+	var versions []DocumentVersionInfo
+
+	current, err := ds.findCurrentDocument(ctx, bson.M{"document_id": documentID})
+	if err != nil && !errors.Is(err, ErrDocumentNotFound) {
+		return nil, err
+	}
+	if current != nil {
+		versions = append(versions, DocumentVersionInfo{
+			VersionHash: current.VersionHash,
+			Title:       current.Title,
+			FetchedAt:   current.FetchedAt,
+			StoredAt:    current.StoredAt,
+			Current:     true,
+		})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "archived_at", Value: -1}})
+	cursor, err := ds.client.Find(ctx, DocumentVersionsCollectionName, bson.M{"document_id": documentID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find document versions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var version StoredDocumentVersion
+		if err := cursor.Decode(&version); err != nil {
+			return nil, fmt.Errorf("failed to decode document version: %w", err)
+		}
+		archivedAt := version.ArchivedAt
+		versions = append(versions, DocumentVersionInfo{
+			VersionHash: version.VersionHash,
+			Title:       version.Title,
+			FetchedAt:   version.FetchedAt,
+			StoredAt:    version.StoredAt,
+			ArchivedAt:  &archivedAt,
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrDocumentNotFound
+	}
+	return versions, nil
+}
+
+// GetDocumentVersion returns the revision of a document with the given version hash,
+// whether it is the current revision or an archived one
+func (ds *DocumentService) GetDocumentVersion(ctx context.Context, documentID, versionHash string) (*StoredDocumentVersion, error) {
+	current, err := ds.findCurrentDocument(ctx, bson.M{"document_id": documentID, "version_hash": versionHash})
+	if err == nil {
+		return &StoredDocumentVersion{
+			DocumentID:  current.DocumentID,
+			VersionHash: current.VersionHash,
+			Title:       current.Title,
+			Location:    current.Location,
+			FetchedAt:   current.FetchedAt,
+			StoredAt:    current.StoredAt,
+			Content:     current.Content,
+			Metadata:    current.Metadata,
+		}, nil
+	}
+	if !errors.Is(err, ErrDocumentNotFound) {
+		return nil, err
+	}
+
+	var version StoredDocumentVersion
+	result := ds.client.FindOne(ctx, DocumentVersionsCollectionName, bson.M{"document_id": documentID, "version_hash": versionHash})
+	if err := result.Decode(&version); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to find document version: %w", err)
+	}
+	return &version, nil
+}
+
+// DiffDocumentVersions computes a line-based diff of a document's content between two revisions.
+// An empty toVersion compares against the current revision.
+func (ds *DocumentService) DiffDocumentVersions(ctx context.Context, documentID, fromVersion, toVersion string) (*DocumentDiff, error) {
+	from, err := ds.GetDocumentVersion(ctx, documentID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *StoredDocumentVersion
+	if toVersion == "" {
+		current, err := ds.findCurrentDocument(ctx, bson.M{"document_id": documentID})
+		if err != nil {
+			return nil, err
+		}
+		to = &StoredDocumentVersion{VersionHash: current.VersionHash, Title: current.Title, Content: current.Content}
+	} else if to, err = ds.GetDocumentVersion(ctx, documentID, toVersion); err != nil {
+		return nil, err
+	}
+
+	diff := &DocumentDiff{
+		DocumentID:   documentID,
+		FromVersion:  from.VersionHash,
+		ToVersion:    to.VersionHash,
+		TitleChanged: from.Title != to.Title,
+		Lines:        utils.DiffLines(from.Content, to.Content),
+	}
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case utils.DiffInsert:
+			diff.AddedLines++
+		case utils.DiffDelete:
+			diff.RemovedLines++
+		}
+	}
+
+	return diff, nil
+}
+
+// findCurrentDocument returns the stored document matching filter, or ErrDocumentNotFound
+func (ds *DocumentService) findCurrentDocument(ctx context.Context, filter bson.M) (*StoredDocument, error) {
+	var doc StoredDocument
+	if err := ds.client.FindOne(ctx, DocumentsCollectionName, filter).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to find document: %w", err)
+	}
+	return &doc, nil
+}
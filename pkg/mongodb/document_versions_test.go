@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newTestSingleResult builds a SingleResult over an in-memory document, or a not-found result when document is nil
+func newTestSingleResult(document interface{}) *SingleResult {
+	if document == nil {
+		return &SingleResult{result: mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)}
+	}
+	return &SingleResult{result: mongo.NewSingleResultFromDocument(document, nil, nil)}
+}
+
+func TestDocumentService_GetDocumentVersions(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+
+	client.On("FindOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc"}).
+		Return(newTestSingleResult(bson.M{"document_id": "doc", "version_hash": "sha256:v3", "title": "Current"}))
+	client.On("Find", ctx, DocumentVersionsCollectionName, bson.M{"document_id": "doc"}, mock.Anything).
+		Return(newTestCursor(t,
+			bson.M{"document_id": "doc", "version_hash": "sha256:v2", "archived_at": newer},
+			bson.M{"document_id": "doc", "version_hash": "sha256:v1", "archived_at": older},
+		), nil)
+
+	versions, err := ds.GetDocumentVersions(ctx, "doc")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+
+	assert.Equal(t, "sha256:v3", versions[0].VersionHash)
+	assert.True(t, versions[0].Current)
+	assert.Nil(t, versions[0].ArchivedAt)
+	assert.Equal(t, "sha256:v2", versions[1].VersionHash)
+	assert.Equal(t, "sha256:v1", versions[2].VersionHash)
+	assert.False(t, versions[2].Current)
+}
+
+func TestDocumentService_GetDocumentVersions_NotFound(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	client.On("FindOne", ctx, DocumentsCollectionName, bson.M{"document_id": "missing"}).Return(newTestSingleResult(nil))
+	client.On("Find", ctx, DocumentVersionsCollectionName, bson.M{"document_id": "missing"}, mock.Anything).Return(newTestCursor(t), nil)
+
+	_, err := ds.GetDocumentVersions(ctx, "missing")
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+}
+
+func TestDocumentService_DiffDocumentVersions(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	client.On("FindOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc", "version_hash": "sha256:v1"}).
+		Return(newTestSingleResult(nil))
+	client.On("FindOne", ctx, DocumentVersionsCollectionName, bson.M{"document_id": "doc", "version_hash": "sha256:v1"}).
+		Return(newTestSingleResult(bson.M{"document_id": "doc", "version_hash": "sha256:v1", "title": "Notes", "content": "a\nb\nc"}))
+	client.On("FindOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc"}).
+		Return(newTestSingleResult(bson.M{"document_id": "doc", "version_hash": "sha256:v2", "title": "Notes", "content": "a\nB\nc\nd"}))
+
+	diff, err := ds.DiffDocumentVersions(ctx, "doc", "sha256:v1", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "sha256:v1", diff.FromVersion)
+	assert.Equal(t, "sha256:v2", diff.ToVersion)
+	assert.False(t, diff.TitleChanged)
+	assert.Equal(t, 2, diff.AddedLines)
+	assert.Equal(t, 1, diff.RemovedLines)
+	assert.Equal(t, []utils.DiffLine{
+		{Op: utils.DiffEqual, Text: "a"},
+		{Op: utils.DiffDelete, Text: "b"},
+		{Op: utils.DiffInsert, Text: "B"},
+		{Op: utils.DiffEqual, Text: "c"},
+		{Op: utils.DiffInsert, Text: "d"},
+	}, diff.Lines)
+}
+
+func TestDocumentService_DiffDocumentVersions_UnknownVersion(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	client.On("FindOne", ctx, DocumentsCollectionName, bson.M{"document_id": "doc", "version_hash": "sha256:nope"}).
+		Return(newTestSingleResult(nil))
+	client.On("FindOne", ctx, DocumentVersionsCollectionName, bson.M{"document_id": "doc", "version_hash": "sha256:nope"}).
+		Return(newTestSingleResult(nil))
+
+	_, err := ds.DiffDocumentVersions(ctx, "doc", "sha256:nope", "")
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
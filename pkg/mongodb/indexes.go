@@ -0,0 +1,371 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DocumentTTLIndexName is the name of the optional TTL index expiring documents by fetched_at
+const DocumentTTLIndexName = "documents_fetched_at_ttl"
+
+// DocumentIDIndexName is the name of the unique document_id index created in upsert mode
+const DocumentIDIndexName = "documents_document_id"
+
+// documentIndexModels returns the indexes backing document queries, upserts, search and pagination
+func documentIndexModels(documentTTL time.Duration) []IndexModel {
+	models := []IndexModel{
+		{
+			Keys:    bson.D{{Key: "document_id", Value: 1}, {Key: "version_hash", Value: 1}},
+			Options: options.Index().SetName("documents_document_id_version_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "source", Value: 1}, {Key: "fetched_at", Value: -1}},
+			Options: options.Index().SetName("documents_source_fetched_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "type", Value: 1}, {Key: "fetched_at", Value: -1}},
+			Options: options.Index().SetName("documents_type_fetched_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("documents_fetched_at_id"),
+		},
+		textIndexModel(),
+	}
+
+	if documentTTL > 0 {
+		models = append(models, IndexModel{
+			Keys: bson.D{{Key: "fetched_at", Value: 1}},
+			Options: options.Index().
+				SetName(DocumentTTLIndexName).
+				SetExpireAfterSeconds(int32(documentTTL.Seconds())),
+		})
+	}
+
+	return models
+}
+
+// documentIDIndexModel returns the unique document_id index keeping a single stored revision
+// per document in upsert mode. Insert mode stores several versions per ID and skips it.
+func documentIDIndexModel() IndexModel {
+	return IndexModel{
+		Keys:    bson.D{{Key: "document_id", Value: 1}},
+		Options: options.Index().SetName(DocumentIDIndexName).SetUnique(true),
+	}
+}
+
+// collectionIndexModels returns the indexes backing collection metadata queries
+func collectionIndexModels() []IndexModel {
+	return []IndexModel{
+		{
+			Keys:    bson.D{{Key: "source", Value: 1}, {Key: "fetched_at", Value: -1}},
+			Options: options.Index().SetName("document_collections_source_fetched_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "document_ids", Value: 1}},
+			Options: options.Index().SetName("document_collections_document_ids"),
+		},
+	}
+}
+
+// versionIndexModels returns the indexes backing version history lookups
+func versionIndexModels() []IndexModel {
+	return []IndexModel{
+		{
+			Keys:    bson.D{{Key: "document_id", Value: 1}, {Key: "archived_at", Value: -1}},
+			Options: options.Index().SetName("document_versions_document_id_archived_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "document_id", Value: 1}, {Key: "version_hash", Value: 1}},
+			Options: options.Index().SetName("document_versions_document_id_version_hash"),
+		},
+	}
+}
+
+// documentSchema is the $jsonSchema validator rejecting malformed documents
+func documentSchema() bson.M {
+	return bson.M{
+		"$jsonSchema": bson.M{
+			"bsonType": "object",
+			"required": []string{"document_id", "source", "type", "title", "fetched_at", "version_hash", "content"},
+			"properties": bson.M{
+				"document_id":  bson.M{"bsonType": "string", "minLength": 1},
+				"source":       bson.M{"bsonType": "string", "minLength": 1},
+				"type":         bson.M{"bsonType": "string", "minLength": 1},
+				"title":        bson.M{"bsonType": "string"},
+				"location":     bson.M{"bsonType": "string"},
+				"created_at":   bson.M{"bsonType": "date"},
+				"fetched_at":   bson.M{"bsonType": "date"},
+				"stored_at":    bson.M{"bsonType": "date"},
+				"version_hash": bson.M{"bsonType": "string", "minLength": 1},
+				"content":      bson.M{"bsonType": "string"},
+				"metadata":     bson.M{"bsonType": []string{"object", "null"}},
+			},
+		},
+	}
+}
+
+// DuplicateKeysError is returned by EnsureIndexes when documents stored before a unique
+// index existed share its keys. Index setup never deletes documents; RemoveDuplicateDocuments
+// removes the duplicates when run explicitly.
+type DuplicateKeysError struct {
+	Collection string
+	Index      string
+	Keys       []string // Duplicated key values, e.g. "document_id=doc_1"
+}
+
+// Error lists the first duplicated keys
+func (e *DuplicateKeysError) Error() string {
+	const maxListed = 10
+	listed := e.Keys
+	more := ""
+	if len(listed) > maxListed {
+		listed = listed[:maxListed]
+		more = fmt.Sprintf(" and %d more", len(e.Keys)-maxListed)
+	}
+	return fmt.Sprintf("unique index %s on %s is blocked by %d duplicated keys: %s%s",
+		e.Index, e.Collection, len(e.Keys), strings.Join(listed, "; "), more)
+}
+
+// DeduplicationReport describes the duplicate documents RemoveDuplicateDocuments removed,
+// or would remove when DryRun is set
+type DeduplicationReport struct {
+	DryRun           bool     `json:"dry_run"`
+	DuplicateKeys    []string `json:"duplicate_keys"`
+	RemovedDocuments int64    `json:"removed_documents"`
+	ArchivedVersions int      `json:"archived_versions"`
+}
+
+// EnsureIndexes creates the indexes required by DocumentService on all of its collections.
+// A positive documentTTL additionally expires documents that long after their fetched_at time;
+// changing it updates the existing TTL index in place and a zero TTL drops it. Each index is
+// created on its own, so one failing index does not keep the others from being created.
+// A unique index blocked by duplicate documents fails with a *DuplicateKeysError.
+func (ds *DocumentService) EnsureIndexes(ctx context.Context, documentTTL time.Duration) error {
+	indexes := map[string][]IndexModel{
+		DocumentsCollectionName:           ds.documentIndexModels(documentTTL),
+		DocumentCollectionsCollectionName: collectionIndexModels(),
+		DocumentVersionsCollectionName:    versionIndexModels(),
+	}
+
+	var errs []error
+	for _, collection := range []string{DocumentsCollectionName, DocumentCollectionsCollectionName, DocumentVersionsCollectionName} {
+		for _, model := range indexes[collection] {
+			if err := ds.ensureIndex(ctx, collection, model); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create index %s on %s: %w", indexName(model), collection, err))
+			}
+		}
+	}
+
+	if documentTTL <= 0 {
+		if err := ds.client.DropIndex(ctx, DocumentsCollectionName, DocumentTTLIndexName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drop index %s on %s: %w", DocumentTTLIndexName, DocumentsCollectionName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// documentIndexModels returns the documents indexes of the store mode
+func (ds *DocumentService) documentIndexModels(documentTTL time.Duration) []IndexModel {
+	models := documentIndexModels(documentTTL)
+	if ds.StoreMode() == StoreModeUpsert {
+		models = append(models, documentIDIndexModel())
+	}
+	return models
+}
+
+// ensureIndex creates a single index. An existing TTL index with another expiry is updated,
+// and a unique documents index blocked by rows stored before it existed reports their keys.
+func (ds *DocumentService) ensureIndex(ctx context.Context, collection string, model IndexModel) error {
+	_, err := ds.client.CreateIndexes(ctx, collection, []IndexModel{model})
+	if err == nil {
+		return nil
+	}
+
+	indexOptions, _ := model.Options.(*options.IndexOptions)
+	switch {
+	case isIndexConflict(err) && indexOptions != nil && indexOptions.ExpireAfterSeconds != nil:
+		return ds.client.SetIndexExpiry(ctx, collection, *indexOptions.Name, *indexOptions.ExpireAfterSeconds)
+
+	case mongo.IsDuplicateKeyError(err) && collection == DocumentsCollectionName:
+		keys, _ := model.Keys.(bson.D)
+		groups, findErr := ds.findDuplicateDocuments(ctx, keys)
+		if findErr != nil {
+			return errors.Join(err, findErr)
+		}
+		duplicates := &DuplicateKeysError{Collection: collection, Index: indexName(model)}
+		for _, group := range groups {
+			duplicates.Keys = append(duplicates.Keys, group.describe(keys))
+		}
+		return duplicates
+	}
+
+	return err
+}
+
+// duplicateGroup is a set of documents sharing the keys of a unique index, most recently fetched first
+type duplicateGroup struct {
+	Key    bson.M               `bson:"_id"`
+	IDs    []primitive.ObjectID `bson:"ids"`
+	Hashes []string             `bson:"hashes"`
+}
+
+// describe formats the shared key values in index key order, e.g. "document_id=doc_1"
+func (g duplicateGroup) describe(keys bson.D) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key.Key, g.Key[key.Key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// findDuplicateDocuments returns every group of documents sharing the values of keys
+func (ds *DocumentService) findDuplicateDocuments(ctx context.Context, keys bson.D) ([]duplicateGroup, error) {
+	groupKey := bson.M{}
+	for _, key := range keys {
+		groupKey[key.Key] = "$" + key.Key
+	}
+
+	pipeline := []bson.M{
+		{"$sort": bson.D{{Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{"$group": bson.M{
+			"_id":    groupKey,
+			"ids":    bson.M{"$push": "$_id"},
+			"hashes": bson.M{"$push": "$version_hash"},
+		}},
+		{"$match": bson.M{"ids.1": bson.M{"$exists": true}}},
+	}
+
+	cursor, err := ds.client.Aggregate(ctx, DocumentsCollectionName, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []duplicateGroup
+	for cursor.Next(ctx) {
+		var group duplicateGroup
+		if err := cursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("failed to decode duplicate documents: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+	return groups, nil
+}
+
+// RemoveDuplicateDocuments removes the documents blocking the unique documents indexes of the
+// store mode, keeping the most recently fetched document of every group sharing their keys.
+// Removed revisions with another version hash than the kept one are archived to the version
+// history first, as an upsert replacing them would have done. Every removed document is logged.
+// With dryRun set nothing is modified and the report describes what would be removed.
+func (ds *DocumentService) RemoveDuplicateDocuments(ctx context.Context, dryRun bool) (*DeduplicationReport, error) {
+	report := &DeduplicationReport{DryRun: dryRun, DuplicateKeys: []string{}}
+
+	// In upsert mode the unique document_id index also covers document_id and version_hash
+	keys := bson.D{{Key: "document_id", Value: 1}, {Key: "version_hash", Value: 1}}
+	if ds.StoreMode() == StoreModeUpsert {
+		keys = bson.D{{Key: "document_id", Value: 1}}
+	}
+
+	groups, err := ds.findDuplicateDocuments(ctx, keys)
+	if err != nil {
+		return report, err
+	}
+	if err := ds.removeDuplicateGroups(ctx, keys, groups, dryRun, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// removeDuplicateGroups removes all but the first document of every group
+func (ds *DocumentService) removeDuplicateGroups(ctx context.Context, keys bson.D, groups []duplicateGroup, dryRun bool, report *DeduplicationReport) error {
+	var duplicateIDs []primitive.ObjectID
+	keptHashes := make(map[primitive.ObjectID]string) // Duplicate _id to the version hash kept in its group
+	for _, group := range groups {
+		report.DuplicateKeys = append(report.DuplicateKeys, group.describe(keys))
+		for _, id := range group.IDs[1:] {
+			duplicateIDs = append(duplicateIDs, id)
+			keptHashes[id] = group.Hashes[0]
+		}
+	}
+	if len(duplicateIDs) == 0 {
+		return nil
+	}
+
+	duplicates, err := ds.client.Find(ctx, DocumentsCollectionName, bson.M{"_id": bson.M{"$in": duplicateIDs}})
+	if err != nil {
+		return fmt.Errorf("failed to find duplicate documents: %w", err)
+	}
+	defer duplicates.Close(ctx)
+
+	action := "Removing"
+	if dryRun {
+		action = "Would remove"
+	}
+	for duplicates.Next(ctx) {
+		var doc StoredDocument
+		if err := duplicates.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode duplicate document: %w", err)
+		}
+		log.Warnf("%s duplicate document %s (document_id=%s, version_hash=%s, fetched_at=%s)",
+			action, doc.ID.Hex(), doc.DocumentID, doc.VersionHash, doc.FetchedAt.Format(time.RFC3339))
+		if doc.VersionHash != keptHashes[doc.ID] {
+			report.ArchivedVersions++
+			if dryRun {
+				continue
+			}
+			if err := ds.archiveVersion(ctx, &doc); err != nil {
+				return err
+			}
+		}
+	}
+	if err := duplicates.Err(); err != nil {
+		return fmt.Errorf("cursor error: %w", err)
+	}
+
+	if dryRun {
+		report.RemovedDocuments += int64(len(duplicateIDs))
+		return nil
+	}
+	result, err := ds.client.DeleteMany(ctx, DocumentsCollectionName, bson.M{"_id": bson.M{"$in": duplicateIDs}})
+	if err != nil {
+		return fmt.Errorf("failed to delete duplicate documents: %w", err)
+	}
+	report.RemovedDocuments += result.DeletedCount
+	log.Warnf("Removed %d duplicate documents from %s", result.DeletedCount, DocumentsCollectionName)
+	return nil
+}
+
+// indexName returns the name set in the options of an index model
+func indexName(model IndexModel) string {
+	if indexOptions, ok := model.Options.(*options.IndexOptions); ok && indexOptions.Name != nil {
+		return *indexOptions.Name
+	}
+	return fmt.Sprint(model.Keys)
+}
+
+// isIndexConflict reports whether an index exists under the same name or keys with other options
+func isIndexConflict(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && (serverErr.HasErrorCode(85) || serverErr.HasErrorCode(86))
+}
+
+// EnsureSchema installs the JSON-schema validator on the documents collection
+func (ds *DocumentService) EnsureSchema(ctx context.Context) error {
+	if err := ds.client.SetCollectionValidator(ctx, DocumentsCollectionName, documentSchema()); err != nil {
+		return fmt.Errorf("failed to set schema validator on %s: %w", DocumentsCollectionName, err)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexNamed returns the options of the index with the given name, or nil
+func indexNamed(models []IndexModel, name string) *options.IndexOptions {
+	for _, model := range models {
+		if indexOptions, ok := model.Options.(*options.IndexOptions); ok && *indexOptions.Name == name {
+			return indexOptions
+		}
+	}
+	return nil
+}
+
+func TestDocumentIndexModels(t *testing.T) {
+	models := documentIndexModels(0)
+
+	unique := indexNamed(models, "documents_document_id_version_hash")
+	require.NotNil(t, unique)
+	assert.True(t, *unique.Unique)
+	assert.NotNil(t, indexNamed(models, TextIndexName))
+	assert.Nil(t, indexNamed(models, DocumentTTLIndexName), "TTL index is only created when a TTL is configured")
+
+	ttl := indexNamed(documentIndexModels(90*24*time.Hour), DocumentTTLIndexName)
+	require.NotNil(t, ttl)
+	assert.Equal(t, int32(90*24*60*60), *ttl.ExpireAfterSeconds)
+}
+
+// createdIndexes returns the names of the indexes passed to CreateIndexes on a collection
+func createdIndexes(client *MockClient, collection string) []string {
+	var names []string
+	for _, call := range client.Calls {
+		if call.Method == "CreateIndexes" && call.Arguments.Get(1) == collection {
+			for _, model := range call.Arguments.Get(2).([]IndexModel) {
+				names = append(names, indexName(model))
+			}
+		}
+	}
+	return names
+}
+
+func TestDocumentService_EnsureIndexes(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	client.On("CreateIndexes", ctx, mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	require.NoError(t, ds.EnsureIndexes(ctx, time.Hour))
+	assert.Contains(t, createdIndexes(client, DocumentsCollectionName), DocumentTTLIndexName)
+	client.AssertNotCalled(t, "DropIndex", mock.Anything, mock.Anything, mock.Anything)
+
+	// The unique document_id index backs upserts and is skipped in insert mode
+	assert.Contains(t, createdIndexes(client, DocumentsCollectionName), DocumentIDIndexName)
+
+	insertClient := &MockClient{}
+	insertClient.On("CreateIndexes", ctx, mock.Anything, mock.Anything).Return([]string{}, nil)
+	insertClient.On("DropIndex", ctx, DocumentsCollectionName, DocumentTTLIndexName).Return(nil)
+	insertService := NewDocumentService(insertClient)
+	require.NoError(t, insertService.SetStoreMode(StoreModeInsert))
+	require.NoError(t, insertService.EnsureIndexes(ctx, 0))
+	assert.NotContains(t, createdIndexes(insertClient, DocumentsCollectionName), DocumentIDIndexName)
+
+	// A zero TTL drops a TTL index left by an earlier configuration
+	insertClient.AssertCalled(t, "DropIndex", ctx, DocumentsCollectionName, DocumentTTLIndexName)
+}
+
+func TestDocumentService_EnsureIndexes_ContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	client.On("CreateIndexes", ctx, DocumentsCollectionName, mock.MatchedBy(func(models []IndexModel) bool {
+		return indexName(models[0]) == TextIndexName
+	})).Return(nil, errors.New("text index limit"))
+	client.On("CreateIndexes", ctx, mock.Anything, mock.Anything).Return([]string{}, nil)
+	client.On("DropIndex", ctx, DocumentsCollectionName, DocumentTTLIndexName).Return(nil)
+
+	err := NewDocumentService(client).EnsureIndexes(ctx, 0)
+	assert.ErrorContains(t, err, TextIndexName)
+	assert.Contains(t, createdIndexes(client, DocumentsCollectionName), "documents_fetched_at_id")
+	assert.NotEmpty(t, createdIndexes(client, DocumentVersionsCollectionName))
+}
+
+func TestDocumentService_EnsureIndexes_UpdatesTTL(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	client.On("CreateIndexes", ctx, DocumentsCollectionName, mock.MatchedBy(func(models []IndexModel) bool {
+		return indexName(models[0]) == DocumentTTLIndexName
+	})).Return(nil, mongo.CommandError{Code: 85, Name: "IndexOptionsConflict"})
+	client.On("CreateIndexes", ctx, mock.Anything, mock.Anything).Return([]string{}, nil)
+	client.On("SetIndexExpiry", ctx, DocumentsCollectionName, DocumentTTLIndexName, int32(48*60*60)).Return(nil)
+
+	require.NoError(t, NewDocumentService(client).EnsureIndexes(ctx, 48*time.Hour))
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_EnsureIndexes_ReportsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	isDocumentIDIndex := mock.MatchedBy(func(models []IndexModel) bool {
+		return indexName(models[0]) == DocumentIDIndexName
+	})
+	client.On("CreateIndexes", ctx, DocumentsCollectionName, isDocumentIDIndex).
+		Return(nil, mongo.CommandError{Code: 11000, Name: "DuplicateKey"}).Once()
+	client.On("CreateIndexes", ctx, mock.Anything, mock.Anything).Return([]string{}, nil)
+	client.On("DropIndex", ctx, DocumentsCollectionName, DocumentTTLIndexName).Return(nil)
+	client.On("Aggregate", ctx, DocumentsCollectionName, mock.Anything).Return(newTestCursor(t,
+		bson.M{"_id": bson.M{"document_id": "doc_1"}, "ids": bson.A{primitive.NewObjectID(), primitive.NewObjectID()}, "hashes": bson.A{"sha256:a", "sha256:b"}},
+		bson.M{"_id": bson.M{"document_id": "doc_2"}, "ids": bson.A{primitive.NewObjectID(), primitive.NewObjectID()}, "hashes": bson.A{"sha256:c", "sha256:c"}},
+	), nil)
+
+	err := ds.EnsureIndexes(ctx, 0)
+
+	var duplicates *DuplicateKeysError
+	require.ErrorAs(t, err, &duplicates)
+	assert.Equal(t, DocumentIDIndexName, duplicates.Index)
+	assert.Equal(t, []string{"document_id=doc_1", "document_id=doc_2"}, duplicates.Keys)
+	assert.Contains(t, err.Error(), "document_id=doc_1; document_id=doc_2")
+	client.AssertNotCalled(t, "Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	client.AssertNotCalled(t, "DeleteMany", mock.Anything, mock.Anything, mock.Anything)
+	client.AssertExpectations(t)
+}
+
+// mockDuplicateDocuments sets up a group of three documents sharing document_id doc_1, of which
+// the most recently fetched one is kept, and returns the _ids of the two others
+func mockDuplicateDocuments(t *testing.T, ctx context.Context, client *MockClient) []primitive.ObjectID {
+	kept, sameVersion, olderVersion := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	client.On("Aggregate", ctx, DocumentsCollectionName, mock.Anything).Return(newTestCursor(t, bson.M{
+		"_id":    bson.M{"document_id": "doc_1"},
+		"ids":    bson.A{kept, sameVersion, olderVersion},
+		"hashes": bson.A{"sha256:new", "sha256:new", "sha256:old"},
+	}), nil)
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"_id": bson.M{"$in": []primitive.ObjectID{sameVersion, olderVersion}}}, mock.Anything).
+		Return(newTestCursor(t,
+			bson.M{"_id": sameVersion, "document_id": "doc_1", "version_hash": "sha256:new"},
+			bson.M{"_id": olderVersion, "document_id": "doc_1", "version_hash": "sha256:old"},
+		), nil)
+	return []primitive.ObjectID{sameVersion, olderVersion}
+}
+
+func TestDocumentService_RemoveDuplicateDocuments(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	removed := mockDuplicateDocuments(t, ctx, client)
+	client.On("InsertOne", ctx, DocumentVersionsCollectionName, mock.MatchedBy(func(version *StoredDocumentVersion) bool {
+		return version.VersionHash == "sha256:old"
+	})).Return(&InsertOneResult{}, nil).Once()
+	client.On("DeleteMany", ctx, DocumentsCollectionName, bson.M{"_id": bson.M{"$in": removed}}).
+		Return(&DeleteResult{DeletedCount: 2}, nil)
+
+	report, err := ds.RemoveDuplicateDocuments(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"document_id=doc_1"}, report.DuplicateKeys)
+	assert.Equal(t, int64(2), report.RemovedDocuments)
+	assert.Equal(t, 1, report.ArchivedVersions)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_RemoveDuplicateDocuments_DryRun(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	mockDuplicateDocuments(t, ctx, client)
+
+	report, err := ds.RemoveDuplicateDocuments(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, int64(2), report.RemovedDocuments)
+	assert.Equal(t, 1, report.ArchivedVersions)
+	client.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything, mock.Anything)
+	client.AssertNotCalled(t, "DeleteMany", mock.Anything, mock.Anything, mock.Anything)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_EnsureSchema(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	client.On("SetCollectionValidator", ctx, DocumentsCollectionName, mock.MatchedBy(func(validator bson.M) bool {
+		schema, ok := validator["$jsonSchema"].(bson.M)
+		return ok && len(schema["required"].([]string)) > 0
+	})).Return(nil)
+
+	require.NoError(t, ds.EnsureSchema(ctx))
+	client.AssertExpectations(t)
+}
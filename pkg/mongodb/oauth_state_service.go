@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OAuthStatesCollectionName is the collection holding issued OAuth states
+const OAuthStatesCollectionName = "oauth_states"
+
+// StoredOAuthState is an issued OAuth state awaiting its callback. The record is opaque to
+// the state service.
+type StoredOAuthState struct {
+	State     string    `bson:"state" json:"state"`
+	Record    []byte    `bson:"record" json:"-"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// OAuthStateService stores issued OAuth states in MongoDB so sign-in survives restarts and
+// works across replicas
+type OAuthStateService struct {
+	client Interface
+}
+
+// NewOAuthStateService creates a new OAuth state service
+func NewOAuthStateService(client Interface) *OAuthStateService {
+	return &OAuthStateService{client: client}
+}
+
+// EnsureIndexes creates the unique state index and the TTL index removing expired states
+func (ss *OAuthStateService) EnsureIndexes(ctx context.Context) error {
+	models := []IndexModel{
+		{
+			Keys:    bson.D{{Key: "state", Value: 1}},
+			Options: options.Index().SetName("oauth_states_state").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("oauth_states_expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := ss.client.CreateIndexes(ctx, OAuthStatesCollectionName, models); err != nil {
+		return fmt.Errorf("failed to create %s indexes: %w", OAuthStatesCollectionName, err)
+	}
+	return nil
+}
+
+// SaveState stores the record of a newly issued state
+func (ss *OAuthStateService) SaveState(ctx context.Context, state string, record []byte, expiresAt time.Time) error {
+	stored := &StoredOAuthState{
+		State:     state,
+		Record:    record,
+		ExpiresAt: expiresAt,
+	}
+	if _, err := ss.client.InsertOne(ctx, OAuthStatesCollectionName, stored); err != nil {
+		return fmt.Errorf("failed to insert OAuth state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeState removes a state and returns its record, or nil when the state is unknown.
+// Only the caller whose delete succeeds gets the record, so a state cannot be used twice.
+func (ss *OAuthStateService) ConsumeState(ctx context.Context, state string) ([]byte, error) {
+	filter := bson.M{"state": state}
+
+	var stored StoredOAuthState
+	if err := ss.client.FindOne(ctx, OAuthStatesCollectionName, filter).Decode(&stored); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find OAuth state: %w", err)
+	}
+
+	result, err := ss.client.DeleteOne(ctx, OAuthStatesCollectionName, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete OAuth state: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return nil, nil
+	}
+	return stored.Record, nil
+}
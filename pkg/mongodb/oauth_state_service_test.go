@@ -0,0 +1,77 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOAuthStateService_SaveState(t *testing.T) {
+	client := &MockClient{}
+	ctx := context.Background()
+	expiresAt := time.Now().Add(10 * time.Minute)
+
+	client.On("InsertOne", ctx, OAuthStatesCollectionName, mock.MatchedBy(func(stored *StoredOAuthState) bool {
+		return stored.State == "state-1" && string(stored.Record) == "record" && stored.ExpiresAt.Equal(expiresAt)
+	})).Return(&InsertOneResult{}, nil)
+
+	require.NoError(t, NewOAuthStateService(client).SaveState(ctx, "state-1", []byte("record"), expiresAt))
+	client.AssertExpectations(t)
+}
+
+func TestOAuthStateService_ConsumeState(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns and deletes issued state", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("FindOne", ctx, OAuthStatesCollectionName, bson.M{"state": "state-1"}).
+			Return(newTestSingleResult(bson.M{"state": "state-1", "record": []byte("record")}))
+		client.On("DeleteOne", ctx, OAuthStatesCollectionName, bson.M{"state": "state-1"}).
+			Return(&DeleteResult{DeletedCount: 1}, nil)
+
+		record, err := NewOAuthStateService(client).ConsumeState(ctx, "state-1")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("record"), record)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("returns nil for unknown state", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("FindOne", ctx, OAuthStatesCollectionName, bson.M{"state": "missing"}).
+			Return(newTestSingleResult(nil))
+
+		record, err := NewOAuthStateService(client).ConsumeState(ctx, "missing")
+		require.NoError(t, err)
+		assert.Nil(t, record)
+		client.AssertNotCalled(t, "DeleteOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns nil when a concurrent callback consumed the state", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("FindOne", ctx, OAuthStatesCollectionName, bson.M{"state": "state-1"}).
+			Return(newTestSingleResult(bson.M{"state": "state-1", "record": []byte("record")}))
+		client.On("DeleteOne", ctx, OAuthStatesCollectionName, bson.M{"state": "state-1"}).
+			Return(&DeleteResult{DeletedCount: 0}, nil)
+
+		record, err := NewOAuthStateService(client).ConsumeState(ctx, "state-1")
+		require.NoError(t, err)
+		assert.Nil(t, record)
+	})
+
+	t.Run("reports errors", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("FindOne", ctx, OAuthStatesCollectionName, bson.M{"state": "state-1"}).
+			Return(newTestSingleResult(bson.M{"state": "state-1", "record": []byte("record")}))
+		client.On("DeleteOne", ctx, OAuthStatesCollectionName, bson.M{"state": "state-1"}).
+			Return(nil, errors.New("connection lost"))
+
+		_, err := NewOAuthStateService(client).ConsumeState(ctx, "state-1")
+		assert.ErrorContains(t, err, "connection lost")
+	})
+}
@@ -0,0 +1,63 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor token cannot be decoded,
+// or is combined with a skip that would shift the page away from the cursor position
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// pageCursor marks the position of the last document of a page in fetched_at/_id order
+type pageCursor struct {
+	FetchedAt time.Time `json:"f"`
+	ID        string    `json:"i"`
+}
+
+// newPageCursor returns the cursor positioned after the given document
+func newPageCursor(doc StoredDocument) pageCursor {
+	return pageCursor{
+		FetchedAt: doc.FetchedAt,
+		ID:        doc.ID.Hex(),
+	}
+}
+
+// encode serialises the cursor into an opaque URL-safe token
+func (pc pageCursor) encode() string {
+	data, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageCursor parses a token produced by encode
+func decodePageCursor(token string) (pageCursor, error) {
+	var pc pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pc, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return pc, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if _, err := primitive.ObjectIDFromHex(pc.ID); err != nil {
+		return pc, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return pc, nil
+}
+
+// filter matches documents sorted after the cursor position in descending fetched_at/_id order
+func (pc pageCursor) filter() bson.M {
+	id, _ := primitive.ObjectIDFromHex(pc.ID)
+	return bson.M{"$or": []bson.M{
+		{"fetched_at": bson.M{"$lt": pc.FetchedAt}},
+		{"fetched_at": pc.FetchedAt, "_id": bson.M{"$lt": id}},
+	}}
+}
@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestPageCursor_RoundTrip(t *testing.T) {
+	doc := StoredDocument{
+		ID:        primitive.NewObjectID(),
+		FetchedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	decoded, err := decodePageCursor(newPageCursor(doc).encode())
+	require.NoError(t, err)
+	assert.Equal(t, doc.ID.Hex(), decoded.ID)
+	assert.True(t, doc.FetchedAt.Equal(decoded.FetchedAt))
+}
+
+func TestDecodePageCursor_Invalid(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "eyJmIjoiMjAyNC0wMS0wMVQwMDowMDowMFoiLCJpIjoibm9wZSJ9"} {
+		_, err := decodePageCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
+
+func TestDocumentService_GetDocumentsPage(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	fetchedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"source": "static"}, mock.MatchedBy(func(opts []*options.FindOptions) bool {
+		return len(opts) == 1 && *opts[0].Limit == 3 && opts[0].Skip == nil && opts[0].Projection == nil
+	})).Return(newTestCursor(t,
+		bson.M{"_id": ids[0], "document_id": "a", "fetched_at": fetchedAt},
+		bson.M{"_id": ids[1], "document_id": "b", "fetched_at": fetchedAt},
+		bson.M{"_id": ids[2], "document_id": "c", "fetched_at": fetchedAt},
+	), nil)
+
+	page, err := ds.GetDocumentsPage(ctx, DocumentFilter{Source: "static", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Documents, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	next, err := decodePageCursor(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, ids[1].Hex(), next.ID)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_GetDocumentsPage_WithCursorAndFields(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	last := StoredDocument{ID: primitive.NewObjectID(), FetchedAt: time.Now().UTC().Truncate(time.Millisecond)}
+	token := newPageCursor(last).encode()
+
+	client.On("Find", ctx, DocumentsCollectionName, bson.M{"$and": []bson.M{
+		{"type": "pdf"},
+		newPageCursor(last).filter(),
+	}}, mock.MatchedBy(func(opts []*options.FindOptions) bool {
+		projection, ok := opts[0].Projection.(bson.M)
+		return ok && projection["title"] == 1 && projection["fetched_at"] == 1 && projection["content"] == nil
+	})).Return(newTestCursor(t, bson.M{"_id": primitive.NewObjectID(), "document_id": "z", "title": "Last"}), nil)
+
+	page, err := ds.GetDocumentsPage(ctx, DocumentFilter{Type: "pdf", Limit: 10, Cursor: token, Fields: []string{"title"}})
+	require.NoError(t, err)
+	assert.Len(t, page.Documents, 1)
+	assert.Empty(t, page.NextCursor)
+	client.AssertExpectations(t)
+
+	_, err = ds.GetDocumentsPage(ctx, DocumentFilter{Cursor: "garbage!"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = ds.GetDocumentsPage(ctx, DocumentFilter{Limit: 10, Skip: 10, Cursor: token})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	client.AssertNumberOfCalls(t, "Find", 1)
+}
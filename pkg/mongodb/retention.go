@@ -0,0 +1,426 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RetentionDefaultSource is the policy source matching every source without a policy of its own
+const RetentionDefaultSource = "*"
+
+// maxReportedDocumentIDs caps the document IDs listed in a RetentionReport
+const maxReportedDocumentIDs = 100
+
+var log = logging.GetLogger()
+
+// RetentionPolicy expires documents of a source once they are older than MaxAge (by fetched_at)
+type RetentionPolicy struct {
+	Source string
+	MaxAge time.Duration
+}
+
+// RetentionPolicyReport describes what a single policy matched
+type RetentionPolicyReport struct {
+	Source        string    `json:"source"`
+	MaxAge        string    `json:"max_age"`
+	Cutoff        time.Time `json:"cutoff"`
+	DocumentCount int64     `json:"document_count"`
+}
+
+// RetentionReport describes what a retention run deleted, or would delete when DryRun is set
+type RetentionReport struct {
+	DryRun             bool                    `json:"dry_run"`
+	Policies           []RetentionPolicyReport `json:"policies"`
+	DeletedDocuments   int64                   `json:"deleted_documents"`
+	DeletedVersions    int64                   `json:"deleted_versions"`
+	PrunedCollections  int64                   `json:"pruned_collections"`
+	DeletedCollections int64                   `json:"deleted_collections"`
+	OrphanedReferences int64                   `json:"orphaned_references"`
+	RemovedDocumentIDs []string                `json:"removed_document_ids,omitempty"`
+	Truncated          bool                    `json:"truncated,omitempty"`
+}
+
+// ParseRetentionAge parses a retention age as a Go duration, additionally accepting whole days such as "90d"
+func ParseRetentionAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid retention age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid retention age %q: %w", value, err)
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("retention age must be positive: %q", value)
+	}
+	return age, nil
+}
+
+// ParseRetentionPolicies parses a comma-separated list of source=age pairs, e.g. "onenote=90d,static=7d,*=30d"
+func ParseRetentionPolicies(spec string) ([]RetentionPolicy, error) {
+	var policies []RetentionPolicy
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		source, age, ok := strings.Cut(entry, "=")
+		source = strings.TrimSpace(source)
+		if !ok || source == "" {
+			return nil, fmt.Errorf("invalid retention policy %q, expected source=age", entry)
+		}
+		if seen[source] {
+			return nil, fmt.Errorf("duplicate retention policy for source %q", source)
+		}
+		seen[source] = true
+
+		maxAge, err := ParseRetentionAge(age)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, RetentionPolicy{Source: source, MaxAge: maxAge})
+	}
+
+	return policies, nil
+}
+
+// retentionFilter matches the documents a policy expires. The default policy
+// excludes every source that has a policy of its own.
+func retentionFilter(policy RetentionPolicy, policies []RetentionPolicy, now time.Time) bson.M {
+	filter := bson.M{"fetched_at": bson.M{"$lt": now.Add(-policy.MaxAge)}}
+
+	if policy.Source != RetentionDefaultSource {
+		filter["source"] = policy.Source
+		return filter
+	}
+
+	var explicitSources []string
+	for _, p := range policies {
+		if p.Source != RetentionDefaultSource {
+			explicitSources = append(explicitSources, p.Source)
+		}
+	}
+	if len(explicitSources) > 0 {
+		filter["source"] = bson.M{"$nin": explicitSources}
+	}
+	return filter
+}
+
+// ApplyRetention expires documents according to the given policies and keeps the other
+// collections consistent: version history of removed documents is deleted, and collection
+// records are pruned of document IDs that no longer exist (or deleted once none remain).
+// With dryRun set nothing is modified and the report describes what would be deleted.
+func (ds *DocumentService) ApplyRetention(ctx context.Context, policies []RetentionPolicy, dryRun bool) (*RetentionReport, error) {
+	report := &RetentionReport{DryRun: dryRun, Policies: []RetentionPolicyReport{}}
+	now := time.Now()
+
+	// Find the document IDs touched by each policy
+	var policyFilters []bson.M
+	affected := make(map[string]bool)
+	for _, policy := range policies {
+		filter := retentionFilter(policy, policies, now)
+		policyFilters = append(policyFilters, filter)
+
+		ids, matched, err := ds.findDocumentIDs(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			affected[id] = true
+		}
+
+		report.Policies = append(report.Policies, RetentionPolicyReport{
+			Source:        policy.Source,
+			MaxAge:        policy.MaxAge.String(),
+			Cutoff:        now.Add(-policy.MaxAge),
+			DocumentCount: matched,
+		})
+		report.DeletedDocuments += matched
+	}
+
+	// An ID is only removed once none of its stored revisions survive the policies
+	removed := make(map[string]bool)
+	if len(affected) > 0 {
+		affectedIDs := sortedKeys(affected)
+		survivors, _, err := ds.findDocumentIDs(ctx, bson.M{
+			"document_id": bson.M{"$in": affectedIDs},
+			"$nor":        policyFilters,
+		})
+		if err != nil {
+			return nil, err
+		}
+		surviving := make(map[string]bool)
+		for _, id := range survivors {
+			surviving[id] = true
+		}
+		for _, id := range affectedIDs {
+			if !surviving[id] {
+				removed[id] = true
+			}
+		}
+	}
+
+	removedIDs := sortedKeys(removed)
+	report.RemovedDocumentIDs = removedIDs
+	if len(removedIDs) > maxReportedDocumentIDs {
+		report.RemovedDocumentIDs = removedIDs[:maxReportedDocumentIDs]
+		report.Truncated = true
+	}
+
+	versionFilter := bson.M{"document_id": bson.M{"$in": removedIDs}}
+	if dryRun && len(removedIDs) > 0 {
+		count, err := ds.client.CountDocuments(ctx, DocumentVersionsCollectionName, versionFilter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count document versions: %w", err)
+		}
+		report.DeletedVersions = count
+	}
+
+	if !dryRun {
+		report.DeletedDocuments = 0
+		for _, filter := range policyFilters {
+			result, err := ds.client.DeleteMany(ctx, DocumentsCollectionName, filter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete expired documents: %w", err)
+			}
+			report.DeletedDocuments += result.DeletedCount
+		}
+
+		if len(removedIDs) > 0 {
+			result, err := ds.client.DeleteMany(ctx, DocumentVersionsCollectionName, versionFilter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete document versions: %w", err)
+			}
+			report.DeletedVersions = result.DeletedCount
+		}
+	}
+
+	if err := ds.pruneCollections(ctx, removed, dryRun, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// pruneCollections removes references to documents that no longer exist from collection records.
+// This also repairs references left behind by the TTL index or earlier cleanups. In a dry run,
+// pendingRemoval lists document IDs that would have been deleted by the same run.
+func (ds *DocumentService) pruneCollections(ctx context.Context, pendingRemoval map[string]bool, dryRun bool, report *RetentionReport) error {
+	pending := []string{}
+	if dryRun {
+		pending = sortedKeys(pendingRemoval)
+	}
+
+	// A single aggregation resolves the referenced IDs of every collection against the
+	// documents collection and returns only collections with references to missing documents.
+	// The let/pipeline form of $lookup runs on MongoDB 3.6 and later; combining it with
+	// localField/foreignField would require 5.0.
+	pipeline := []bson.M{
+		{"$match": bson.M{"document_ids.0": bson.M{"$exists": true}}},
+		{"$lookup": bson.M{
+			"from": DocumentsCollectionName,
+			"let":  bson.M{"document_ids": "$document_ids"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{"$expr": bson.M{"$in": bson.A{"$document_id", "$$document_ids"}}}},
+				{"$project": bson.M{"_id": 0, "document_id": 1}},
+			},
+			"as": "existing",
+		}},
+		{"$project": bson.M{
+			"document_ids": 1,
+			"existing":     bson.M{"$setDifference": bson.A{"$existing.document_id", pending}},
+		}},
+		{"$project": bson.M{
+			"missing":  bson.M{"$setDifference": bson.A{"$document_ids", "$existing"}},
+			"retained": bson.M{"$size": bson.M{"$setIntersection": bson.A{"$document_ids", "$existing"}}},
+		}},
+		{"$match": bson.M{"missing.0": bson.M{"$exists": true}}},
+	}
+
+	cursor, err := ds.client.Aggregate(ctx, DocumentCollectionsCollectionName, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to find orphaned document references: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var toPrune, toDelete []primitive.ObjectID
+	orphans := make(map[string]bool)
+
+	for cursor.Next(ctx) {
+		var collection struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			Missing  []string           `bson:"missing"`
+			Retained int                `bson:"retained"`
+		}
+		if err := cursor.Decode(&collection); err != nil {
+			return fmt.Errorf("failed to decode collection: %w", err)
+		}
+
+		for _, id := range collection.Missing {
+			orphans[id] = true
+		}
+		if collection.Retained == 0 {
+			toDelete = append(toDelete, collection.ID)
+		} else {
+			toPrune = append(toPrune, collection.ID)
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("cursor error: %w", err)
+	}
+
+	report.PrunedCollections = int64(len(toPrune))
+	report.DeletedCollections = int64(len(toDelete))
+	report.OrphanedReferences = int64(len(orphans))
+
+	if dryRun {
+		return nil
+	}
+
+	if len(toPrune) > 0 {
+		orphanIDs := sortedKeys(orphans)
+		update := []bson.M{
+			{"$set": bson.M{"document_ids": bson.M{"$filter": bson.M{
+				"input": "$document_ids",
+				"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this", orphanIDs}}}},
+			}}}},
+			{"$set": bson.M{"document_count": bson.M{"$size": "$document_ids"}}},
+		}
+		if _, err := ds.client.UpdateMany(ctx, DocumentCollectionsCollectionName, bson.M{"_id": bson.M{"$in": toPrune}}, update); err != nil {
+			return fmt.Errorf("failed to prune document collections: %w", err)
+		}
+	}
+
+	if len(toDelete) > 0 {
+		if _, err := ds.client.DeleteMany(ctx, DocumentCollectionsCollectionName, bson.M{"_id": bson.M{"$in": toDelete}}); err != nil {
+			return fmt.Errorf("failed to delete orphaned document collections: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// findDocumentIDs returns the distinct document IDs of the documents matching filter,
+// along with the number of stored documents matched
+func (ds *DocumentService) findDocumentIDs(ctx context.Context, filter bson.M) ([]string, int64, error) {
+	opts := options.Find().SetProjection(bson.M{"document_id": 1})
+	cursor, err := ds.client.Find(ctx, DocumentsCollectionName, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	var matched int64
+	seen := make(map[string]bool)
+	for cursor.Next(ctx) {
+		var doc StoredDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode document: %w", err)
+		}
+		matched++
+		if !seen[doc.DocumentID] {
+			seen[doc.DocumentID] = true
+			ids = append(ids, doc.DocumentID)
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, 0, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return ids, matched, nil
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RetentionScheduler periodically applies retention policies in the background
+type RetentionScheduler struct {
+	documentService *DocumentService
+	policies        []RetentionPolicy
+	interval        time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewRetentionScheduler creates a scheduler applying policies every interval
+func NewRetentionScheduler(documentService *DocumentService, policies []RetentionPolicy, interval time.Duration) *RetentionScheduler {
+	return &RetentionScheduler{
+		documentService: documentService,
+		policies:        policies,
+		interval:        interval,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// Start runs retention every interval until Stop is called
+func (s *RetentionScheduler) Start() {
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.run()
+			}
+		}
+	}()
+}
+
+// Stop halts the scheduler and waits for an in-flight run to finish
+func (s *RetentionScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// run applies the policies once, bounded by the scheduler interval
+func (s *RetentionScheduler) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	report, err := s.documentService.ApplyRetention(ctx, s.policies, false)
+	if err != nil {
+		log.Errorf("Scheduled retention failed: %v", err)
+		return
+	}
+
+	log.Infof("Scheduled retention deleted %d documents, %d versions and %d collections; pruned %d collections",
+		report.DeletedDocuments, report.DeletedVersions, report.DeletedCollections, report.PrunedCollections)
+}
@@ -0,0 +1,176 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseRetentionPolicies(t *testing.T) {
+	policies, err := ParseRetentionPolicies("onenote=90d, static=7d,*=720h")
+	require.NoError(t, err)
+	assert.Equal(t, []RetentionPolicy{
+		{Source: "onenote", MaxAge: 90 * 24 * time.Hour},
+		{Source: "static", MaxAge: 7 * 24 * time.Hour},
+		{Source: RetentionDefaultSource, MaxAge: 720 * time.Hour},
+	}, policies)
+
+	policies, err = ParseRetentionPolicies("")
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+
+	for _, spec := range []string{"onenote", "=7d", "onenote=soon", "onenote=0d", "static=-1h", "static=7d,static=8d"} {
+		_, err := ParseRetentionPolicies(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRetentionFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	policies := []RetentionPolicy{
+		{Source: "static", MaxAge: 24 * time.Hour},
+		{Source: RetentionDefaultSource, MaxAge: 48 * time.Hour},
+	}
+
+	assert.Equal(t, bson.M{
+		"fetched_at": bson.M{"$lt": now.Add(-24 * time.Hour)},
+		"source":     "static",
+	}, retentionFilter(policies[0], policies, now))
+	assert.Equal(t, bson.M{
+		"fetched_at": bson.M{"$lt": now.Add(-48 * time.Hour)},
+		"source":     bson.M{"$nin": []string{"static"}},
+	}, retentionFilter(policies[1], policies, now))
+	assert.Equal(t, bson.M{
+		"fetched_at": bson.M{"$lt": now.Add(-48 * time.Hour)},
+	}, retentionFilter(policies[1], policies[1:], now))
+}
+
+// mockRetentionScenario expects the lookups of a retention run over two policies. "s1" and "s2" are
+// expired static documents where "s2" keeps a newer revision, "o1" is expired under the default policy.
+func mockRetentionScenario(t *testing.T, ctx context.Context, client *MockClient) {
+	isPolicy := func(source interface{}) interface{} {
+		return mock.MatchedBy(func(filter bson.M) bool {
+			_, hasCutoff := filter["fetched_at"]
+			return hasCutoff && assert.ObjectsAreEqual(source, filter["source"])
+		})
+	}
+	isLookup := func(ids []string, survivors bool) interface{} {
+		return mock.MatchedBy(func(filter bson.M) bool {
+			_, hasNor := filter["$nor"]
+			return hasNor == survivors && assert.ObjectsAreEqual(bson.M{"$in": ids}, filter["document_id"])
+		})
+	}
+
+	client.On("Find", ctx, DocumentsCollectionName, isPolicy("static"), mock.Anything).
+		Return(newTestCursor(t, bson.M{"document_id": "s1"}, bson.M{"document_id": "s2"}, bson.M{"document_id": "s1"}), nil).Once()
+	client.On("Find", ctx, DocumentsCollectionName, isPolicy(bson.M{"$nin": []string{"static"}}), mock.Anything).
+		Return(newTestCursor(t, bson.M{"document_id": "o1"}), nil).Once()
+	client.On("Find", ctx, DocumentsCollectionName, isLookup([]string{"o1", "s1", "s2"}, true), mock.Anything).
+		Return(newTestCursor(t, bson.M{"document_id": "s2"}), nil).Once()
+}
+
+// mockOrphanLookup expects the aggregation resolving collection references against the documents
+// that remain, excluding pending IDs, and returns one collection to prune and one to delete
+func mockOrphanLookup(t *testing.T, ctx context.Context, client *MockClient, pending []string) (pruned, deleted primitive.ObjectID) {
+	excludesPending := mock.MatchedBy(func(pipeline []bson.M) bool {
+		for _, stage := range pipeline {
+			// localField/foreignField together with a pipeline needs MongoDB 5.0
+			if lookup, ok := stage["$lookup"].(bson.M); ok {
+				if _, ok := lookup["localField"]; ok || lookup["let"] == nil {
+					return false
+				}
+			}
+			project, ok := stage["$project"].(bson.M)
+			if !ok {
+				continue
+			}
+			if existing, ok := project["existing"].(bson.M); ok {
+				return assert.ObjectsAreEqual(bson.A{"$existing.document_id", pending}, existing["$setDifference"])
+			}
+		}
+		return false
+	})
+
+	pruned, deleted = primitive.NewObjectID(), primitive.NewObjectID()
+	client.On("Aggregate", ctx, DocumentCollectionsCollectionName, excludesPending).
+		Return(newTestCursor(t,
+			bson.M{"_id": pruned, "missing": []string{"s1"}, "retained": 1},
+			bson.M{"_id": deleted, "missing": []string{"o1"}, "retained": 0},
+		), nil).Once()
+
+	return pruned, deleted
+}
+
+func TestDocumentService_ApplyRetention(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	mockRetentionScenario(t, ctx, client)
+	pruned, deleted := mockOrphanLookup(t, ctx, client, []string{})
+
+	client.On("DeleteMany", ctx, DocumentsCollectionName, mock.MatchedBy(func(filter bson.M) bool { return filter["source"] == "static" })).
+		Return(&DeleteResult{DeletedCount: 3}, nil).Once()
+	client.On("DeleteMany", ctx, DocumentsCollectionName, mock.Anything).
+		Return(&DeleteResult{DeletedCount: 1}, nil).Once()
+	client.On("DeleteMany", ctx, DocumentVersionsCollectionName, bson.M{"document_id": bson.M{"$in": []string{"o1", "s1"}}}).
+		Return(&DeleteResult{DeletedCount: 4}, nil).Once()
+	client.On("UpdateMany", ctx, DocumentCollectionsCollectionName, bson.M{"_id": bson.M{"$in": []primitive.ObjectID{pruned}}}, mock.Anything).
+		Return(&UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil).Once()
+	client.On("DeleteMany", ctx, DocumentCollectionsCollectionName, bson.M{"_id": bson.M{"$in": []primitive.ObjectID{deleted}}}).
+		Return(&DeleteResult{DeletedCount: 1}, nil).Once()
+
+	report, err := ds.ApplyRetention(ctx, []RetentionPolicy{
+		{Source: "static", MaxAge: 7 * 24 * time.Hour},
+		{Source: RetentionDefaultSource, MaxAge: 30 * 24 * time.Hour},
+	}, false)
+	require.NoError(t, err)
+
+	assert.False(t, report.DryRun)
+	require.Len(t, report.Policies, 2)
+	assert.Equal(t, int64(3), report.Policies[0].DocumentCount)
+	assert.Equal(t, int64(1), report.Policies[1].DocumentCount)
+	assert.Equal(t, int64(4), report.DeletedDocuments)
+	assert.Equal(t, int64(4), report.DeletedVersions)
+	assert.Equal(t, int64(1), report.PrunedCollections)
+	assert.Equal(t, int64(1), report.DeletedCollections)
+	assert.Equal(t, int64(2), report.OrphanedReferences)
+	assert.Equal(t, []string{"o1", "s1"}, report.RemovedDocumentIDs)
+	client.AssertExpectations(t)
+}
+
+func TestDocumentService_ApplyRetention_DryRun(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	ds := NewDocumentService(client)
+
+	mockRetentionScenario(t, ctx, client)
+
+	// Nothing is deleted, so the documents that would be removed are excluded from the lookup
+	mockOrphanLookup(t, ctx, client, []string{"o1", "s1"})
+	client.On("CountDocuments", ctx, DocumentVersionsCollectionName, bson.M{"document_id": bson.M{"$in": []string{"o1", "s1"}}}).
+		Return(int64(4), nil).Once()
+
+	report, err := ds.ApplyRetention(ctx, []RetentionPolicy{
+		{Source: "static", MaxAge: 7 * 24 * time.Hour},
+		{Source: RetentionDefaultSource, MaxAge: 30 * 24 * time.Hour},
+	}, true)
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, int64(4), report.DeletedDocuments)
+	assert.Equal(t, int64(4), report.DeletedVersions)
+	assert.Equal(t, int64(1), report.PrunedCollections)
+	assert.Equal(t, int64(1), report.DeletedCollections)
+	assert.Equal(t, int64(2), report.OrphanedReferences)
+	assert.Equal(t, []string{"o1", "s1"}, report.RemovedDocumentIDs)
+	client.AssertExpectations(t)
+	client.AssertNotCalled(t, "DeleteMany", mock.Anything, mock.Anything, mock.Anything)
+	client.AssertNotCalled(t, "UpdateMany", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
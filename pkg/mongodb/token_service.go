@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OAuthTokensCollectionName is the collection holding encrypted OAuth tokens
+const OAuthTokensCollectionName = "oauth_tokens"
+
+// StoredOAuthToken is an encrypted OAuth token set of a user. The token service never sees
+// plaintext tokens; sealing and opening them is up to the caller.
+type StoredOAuthToken struct {
+	UserKey   string    `bson:"user_key" json:"user_key"`
+	Sealed    []byte    `bson:"sealed" json:"-"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// TokenService stores encrypted OAuth tokens in MongoDB, one record per user
+type TokenService struct {
+	client Interface
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(client Interface) *TokenService {
+	return &TokenService{client: client}
+}
+
+// EnsureIndexes creates the unique user key index of the token collection
+func (ts *TokenService) EnsureIndexes(ctx context.Context) error {
+	models := []IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_key", Value: 1}},
+			Options: options.Index().SetName("oauth_tokens_user_key").SetUnique(true),
+		},
+	}
+	if _, err := ts.client.CreateIndexes(ctx, OAuthTokensCollectionName, models); err != nil {
+		return fmt.Errorf("failed to create %s indexes: %w", OAuthTokensCollectionName, err)
+	}
+	return nil
+}
+
+// LoadToken returns the sealed token of a user, or nil when the user has no token
+func (ts *TokenService) LoadToken(ctx context.Context, userKey string) ([]byte, error) {
+	var stored StoredOAuthToken
+	if err := ts.client.FindOne(ctx, OAuthTokensCollectionName, bson.M{"user_key": userKey}).Decode(&stored); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find OAuth token: %w", err)
+	}
+	return stored.Sealed, nil
+}
+
+// SaveToken stores the sealed token of a user, replacing any previous one
+func (ts *TokenService) SaveToken(ctx context.Context, userKey string, sealed []byte) error {
+	stored := &StoredOAuthToken{
+		UserKey:   userKey,
+		Sealed:    sealed,
+		UpdatedAt: time.Now(),
+	}
+
+	result, err := ts.client.ReplaceOne(ctx, OAuthTokensCollectionName, bson.M{"user_key": userKey}, stored)
+	if err != nil {
+		return fmt.Errorf("failed to replace OAuth token: %w", err)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	if _, err := ts.client.InsertOne(ctx, OAuthTokensCollectionName, stored); err != nil {
+		return fmt.Errorf("failed to insert OAuth token: %w", err)
+	}
+	return nil
+}
+
+// DeleteToken removes the token of a user
+func (ts *TokenService) DeleteToken(ctx context.Context, userKey string) error {
+	if _, err := ts.client.DeleteOne(ctx, OAuthTokensCollectionName, bson.M{"user_key": userKey}); err != nil {
+		return fmt.Errorf("failed to delete OAuth token: %w", err)
+	}
+	return nil
+}
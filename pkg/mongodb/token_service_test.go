@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTokenService_LoadToken(t *testing.T) {
+	client := &MockClient{}
+	service := NewTokenService(client)
+	ctx := context.Background()
+
+	client.On("FindOne", ctx, OAuthTokensCollectionName, bson.M{"user_key": "user-1"}).
+		Return(newTestSingleResult(bson.M{"user_key": "user-1", "sealed": []byte("sealed")}))
+	client.On("FindOne", ctx, OAuthTokensCollectionName, bson.M{"user_key": "missing"}).
+		Return(newTestSingleResult(nil))
+
+	sealed, err := service.LoadToken(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("sealed"), sealed)
+
+	sealed, err = service.LoadToken(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, sealed)
+}
+
+func TestTokenService_SaveToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("replaces existing token", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("ReplaceOne", ctx, OAuthTokensCollectionName, bson.M{"user_key": "user-1"}, mock.AnythingOfType("*mongodb.StoredOAuthToken"), mock.Anything).
+			Return(&UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+
+		require.NoError(t, NewTokenService(client).SaveToken(ctx, "user-1", []byte("sealed")))
+		client.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("inserts first token", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("ReplaceOne", ctx, OAuthTokensCollectionName, bson.M{"user_key": "user-2"}, mock.Anything, mock.Anything).
+			Return(&UpdateResult{}, nil)
+		client.On("InsertOne", ctx, OAuthTokensCollectionName, mock.MatchedBy(func(stored *StoredOAuthToken) bool {
+			return stored.UserKey == "user-2" && string(stored.Sealed) == "sealed" && !stored.UpdatedAt.IsZero()
+		})).Return(&InsertOneResult{}, nil)
+
+		require.NoError(t, NewTokenService(client).SaveToken(ctx, "user-2", []byte("sealed")))
+		client.AssertExpectations(t)
+	})
+
+	t.Run("reports errors", func(t *testing.T) {
+		client := &MockClient{}
+		client.On("ReplaceOne", ctx, OAuthTokensCollectionName, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("connection lost"))
+
+		err := NewTokenService(client).SaveToken(ctx, "user-1", []byte("sealed"))
+		assert.ErrorContains(t, err, "connection lost")
+	})
+}
+
+func TestTokenService_DeleteToken(t *testing.T) {
+	client := &MockClient{}
+	ctx := context.Background()
+	client.On("DeleteOne", ctx, OAuthTokensCollectionName, bson.M{"user_key": "user-1"}).
+		Return(&DeleteResult{DeletedCount: 1}, nil)
+
+	require.NoError(t, NewTokenService(client).DeleteToken(ctx, "user-1"))
+	client.AssertExpectations(t)
+}
@@ -3,12 +3,16 @@ package msgraph
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	khttp "github.com/microsoft/kiota-http-go"
 	msgraph "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	msgraphauth "github.com/microsoftgraph/msgraph-sdk-go-core/authentication"
 
 	"github.com/ishank09/data-extraction-service/internal/types"
 )
@@ -17,8 +21,18 @@ import (
 type Interface interface {
 	// OneNote data extraction - returns all OneNote pages as JSON array
 	GetOneNoteDataAsJSON(ctx context.Context) (*types.DocumentCollection, error)
+	// Incremental OneNote sync - returns only pages changed since the previous sync
+	SyncOneNoteData(ctx context.Context, full bool) (*OneNoteSyncResult, error)
+	// Saves the checkpoint of a sync once its changes have been stored
+	CommitSync(ctx context.Context, result *OneNoteSyncResult) error
 }
 
+// Default Microsoft Graph and Microsoft identity platform endpoints
+const (
+	DefaultGraphEndpoint = "https://graph.microsoft.com/v1.0"
+	DefaultLoginEndpoint = "https://login.microsoftonline.com"
+)
+
 // AuthType represents the type of authentication being used
 type AuthType int
 
@@ -31,16 +45,38 @@ const (
 
 // ConcurrencyConfig defines limits for concurrent operations
 type ConcurrencyConfig struct {
-	MaxSectionWorkers int // Maximum concurrent section fetchers
-	MaxContentWorkers int // Maximum concurrent content fetchers
+	MaxSectionWorkers  int   // Maximum concurrent section fetchers
+	MaxContentWorkers  int   // Maximum concurrent content fetchers
+	MaxResourceWorkers int   // Maximum concurrent attachment downloaders (0 uses the default, negative disables attachment extraction)
+	MaxAttachmentSize  int64 // Largest attachment downloaded in bytes (0 uses DefaultMaxAttachmentSize)
 }
 
 // DefaultConcurrencyConfig returns sensible defaults for API rate limiting
 func DefaultConcurrencyConfig() ConcurrencyConfig {
 	return ConcurrencyConfig{
-		MaxSectionWorkers: 5,  // Conservative limit for section processing
-		MaxContentWorkers: 10, // Higher limit for content fetching as it's the main bottleneck
+		MaxSectionWorkers:  5,  // Conservative limit for section processing
+		MaxContentWorkers:  10, // Higher limit for content fetching as it's the main bottleneck
+		MaxResourceWorkers: 5,  // Attachments are larger downloads, so keep this limit lower
+		MaxAttachmentSize:  DefaultMaxAttachmentSize,
+	}
+}
+
+// resourceLimits returns the number of attachment download workers, 0 when downloads are
+// disabled, and the attachment size limit, applying the defaults for unset values
+func (c ConcurrencyConfig) resourceLimits() (int, int64) {
+	workers := c.MaxResourceWorkers
+	switch {
+	case workers < 0:
+		workers = 0
+	case workers == 0:
+		workers = DefaultConcurrencyConfig().MaxResourceWorkers
+	}
+
+	maxSize := c.MaxAttachmentSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxAttachmentSize
 	}
+	return workers, maxSize
 }
 
 // Config represents the configuration for Microsoft Graph client
@@ -48,10 +84,25 @@ type Config struct {
 	ClientID      string
 	ClientSecret  string
 	TenantID      string
-	LoginEndpoint string
+	Cloud         string // National cloud (CloudGlobal, CloudUSGov, CloudUSGovDoD, CloudChina or CloudGermany; defaults to CloudGlobal)
+	LoginEndpoint string // Identity platform host (overrides the cloud's login endpoint)
+	GraphEndpoint string // Graph API base URL including the version (overrides the cloud's Graph endpoint)
 	Scopes        []string
+	// Application credentials used instead of ClientSecret (see CredentialType)
+	CertificatePath     string // PEM or PKCS#12 (PFX) file with certificate and RSA private key
+	CertificatePassword string // Password of an encrypted certificate file
+	FederatedTokenFile  string // Federated workload identity token file, e.g. AZURE_FEDERATED_TOKEN_FILE on AKS
 	// OneNote concurrency configuration
 	OneNoteConcurrency *ConcurrencyConfig
+	// Graph collection paging configuration
+	Pagination *PaginationConfig
+	// Retry configuration for throttled Graph requests
+	Retry *RetryConfig
+	// Store for incremental sync checkpoints (defaults to an in-memory store per client; share one
+	// store between the per-request clients created from access tokens)
+	CheckpointStore CheckpointStore
+	// Notebooks, sections and pages to crawl (defaults to everything)
+	OneNoteFilter *FilterConfig
 }
 
 // Client represents the base Microsoft Graph client
@@ -61,15 +112,29 @@ type Client struct {
 	tenantID      string
 	loginEndpoint string
 	scopes        []string
+	graphEndpoint string
 	graphClient   *msgraph.GraphServiceClient
 	authType      AuthType // Track authentication type
 	userID        string   // User ID for application flow
 	// OneNote concurrency configuration
 	oneNoteConcurrency ConcurrencyConfig
+	// Graph collection paging configuration
+	pagination PaginationConfig
+	// Retry configuration for throttled Graph requests
+	retry RetryConfig
+	// Store for incremental sync checkpoints
+	checkpoints CheckpointStore
+	// Notebooks, sections and pages to crawl (nil crawls everything)
+	filter *compiledFilter
 }
 
 // NewClient creates a new Microsoft Graph client with service credentials (client credentials flow)
 func NewClient(config Config) (*Client, error) {
+	endpoints, err := config.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
 	// Set default scopes if not provided
 	scopes := config.Scopes
 	if len(scopes) == 0 {
@@ -77,46 +142,90 @@ func NewClient(config Config) (*Client, error) {
 			// "Notes.Read",
 			// "Notes.Read.All",
 			// "User.Read",
-			"https://graph.microsoft.com/.default",
+			defaultScope(endpoints.GraphEndpoint),
 		}
 	}
 
-	// Create credentials
-	credential, err := azidentity.NewClientSecretCredential(config.TenantID, config.ClientID, config.ClientSecret, nil)
+	// Create credentials against the cloud's identity platform
+	credential, err := config.newCredential(endpoints.LoginEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create credentials: %w", err)
 	}
 
 	// Create graph client
-	graphClient, err := msgraph.NewGraphServiceClientWithCredentials(credential, scopes)
+	graphClient, err := newGraphServiceClient(credential, scopes, endpoints.GraphEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create graph client: %w", err)
 	}
 
+	// Set OneNote crawl filter
+	var filter *compiledFilter
+	if config.OneNoteFilter != nil {
+		filter, err = config.OneNoteFilter.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid OneNote filter: %w", err)
+		}
+	}
+
 	// Set OneNote concurrency configuration
 	concurrencyConfig := DefaultConcurrencyConfig()
 	if config.OneNoteConcurrency != nil {
 		concurrencyConfig = *config.OneNoteConcurrency
 	}
 
+	// Set Graph paging configuration
+	paginationConfig := DefaultPaginationConfig()
+	if config.Pagination != nil {
+		paginationConfig = *config.Pagination
+	}
+
+	// Set retry configuration for throttled requests
+	retryConfig := DefaultRetryConfig()
+	if config.Retry != nil {
+		retryConfig = *config.Retry
+	}
+
+	// Set store for incremental sync checkpoints
+	checkpoints := config.CheckpointStore
+	if checkpoints == nil {
+		checkpoints = NewMemoryCheckpointStore()
+	}
+
 	return &Client{
 		clientID:           config.ClientID,
 		clientSecret:       config.ClientSecret,
 		tenantID:           config.TenantID,
-		loginEndpoint:      config.LoginEndpoint,
+		loginEndpoint:      endpoints.LoginEndpoint,
 		scopes:             scopes,
+		graphEndpoint:      endpoints.GraphEndpoint,
 		graphClient:        graphClient,
 		authType:           AuthTypeApplication,
 		oneNoteConcurrency: concurrencyConfig,
+		pagination:         paginationConfig,
+		retry:              retryConfig,
+		checkpoints:        checkpoints,
+		filter:             filter,
 	}, nil
 }
 
 // NewClientWithToken creates a new Microsoft Graph client using an existing access token (from auth service)
 func NewClientWithToken(accessToken string) (*Client, error) {
+	return NewClientWithTokenAndConfig(accessToken, Config{})
+}
+
+// NewClientWithTokenAndConfig creates a new Microsoft Graph client using an existing access token.
+// Credentials in config are ignored; its cloud, endpoint, concurrency, paging, retry, checkpoint
+// and filter settings apply, with defaults for those left unset.
+func NewClientWithTokenAndConfig(accessToken string, config Config) (*Client, error) {
 	if accessToken == "" {
 		return nil, fmt.Errorf("access token cannot be empty")
 	}
 
+	endpoints, err := config.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create token credential from the provided access token
 	tokenCredential := &staticTokenCredential{token: accessToken}
 
@@ -128,18 +237,85 @@ func NewClientWithToken(accessToken string) (*Client, error) {
 	}
 
 	// Create graph client with the token
-	graphClient, err := msgraph.NewGraphServiceClientWithCredentials(tokenCredential, scopes)
+	graphClient, err := newGraphServiceClient(tokenCredential, scopes, endpoints.GraphEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create graph client with token: %w", err)
 	}
 
-	return &Client{
+	var filter *compiledFilter
+	if config.OneNoteFilter != nil {
+		filter, err = config.OneNoteFilter.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid OneNote filter: %w", err)
+		}
+	}
+
+	client := &Client{
 		graphClient:        graphClient,
 		scopes:             scopes,
+		loginEndpoint:      endpoints.LoginEndpoint,
+		graphEndpoint:      endpoints.GraphEndpoint,
 		authType:           AuthTypeDelegated,
 		oneNoteConcurrency: DefaultConcurrencyConfig(), // Use default for token-based auth
-		// Note: clientID, clientSecret, tenantID, loginEndpoint are not needed for token-based auth
-	}, nil
+		pagination:         DefaultPaginationConfig(),
+		retry:              DefaultRetryConfig(),
+		checkpoints:        NewMemoryCheckpointStore(),
+		filter:             filter,
+		// Note: clientID, clientSecret, tenantID are not needed for token-based auth
+	}
+	if config.OneNoteConcurrency != nil {
+		client.oneNoteConcurrency = *config.OneNoteConcurrency
+	}
+	if config.Pagination != nil {
+		client.pagination = *config.Pagination
+	}
+	if config.Retry != nil {
+		client.retry = *config.Retry
+	}
+	if config.CheckpointStore != nil {
+		client.checkpoints = config.CheckpointStore
+	}
+
+	return client, nil
+}
+
+// newGraphServiceClient creates a Graph client that sends requests to graphEndpoint.
+// The SDK's retry handler is left out of the middleware so that throttled requests are
+// only retried by withRetry, which records metrics and adapts the worker limiters.
+func newGraphServiceClient(credential azcore.TokenCredential, scopes []string, graphEndpoint string) (*msgraph.GraphServiceClient, error) {
+	endpoint, err := url.Parse(graphEndpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid Graph endpoint %q", graphEndpoint)
+	}
+
+	// Tokens are only attached to requests for the configured host
+	validHosts := []string{endpoint.Hostname()}
+	if endpoint.Host != endpoint.Hostname() {
+		validHosts = append(validHosts, endpoint.Host)
+	}
+
+	auth, err := msgraphauth.NewAzureIdentityAuthenticationProviderWithScopesAndValidHosts(credential, scopes, validHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	options := msgraph.GetDefaultClientOptions()
+	var middlewares []khttp.Middleware
+	for _, middleware := range msgraphcore.GetDefaultMiddlewaresWithOptions(&options) {
+		if _, ok := middleware.(*khttp.RetryHandler); ok {
+			continue
+		}
+		middlewares = append(middlewares, middleware)
+	}
+
+	adapter, err := msgraph.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+		auth, nil, nil, msgraphcore.GetDefaultClient(&options, middlewares...))
+	if err != nil {
+		return nil, err
+	}
+	adapter.SetBaseUrl(strings.TrimSuffix(graphEndpoint, "/"))
+
+	return msgraph.NewGraphServiceClient(adapter), nil
 }
 
 // NewClientWithUserID creates a new Microsoft Graph client with service credentials for a specific user
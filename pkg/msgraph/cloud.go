@@ -0,0 +1,100 @@
+package msgraph
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// National clouds supported by Config.Cloud
+const (
+	CloudGlobal   = "global"    // Microsoft Graph global service (default)
+	CloudUSGov    = "usgov"     // Microsoft Graph for US Government L4
+	CloudUSGovDoD = "usgov-dod" // Microsoft Graph for US Government L5 (DOD)
+	CloudChina    = "china"     // Microsoft Graph China operated by 21Vianet
+	CloudGermany  = "germany"   // Microsoft Cloud Deutschland (legacy)
+)
+
+// CloudEndpoints are the identity platform host and Graph API base URL of a cloud
+type CloudEndpoints struct {
+	LoginEndpoint string
+	GraphEndpoint string
+}
+
+// cloudEndpoints maps national cloud names to their endpoints
+var cloudEndpoints = map[string]CloudEndpoints{
+	CloudGlobal:   {LoginEndpoint: DefaultLoginEndpoint, GraphEndpoint: DefaultGraphEndpoint},
+	CloudUSGov:    {LoginEndpoint: "https://login.microsoftonline.us", GraphEndpoint: "https://graph.microsoft.us/v1.0"},
+	CloudUSGovDoD: {LoginEndpoint: "https://login.microsoftonline.us", GraphEndpoint: "https://dod-graph.microsoft.us/v1.0"},
+	CloudChina:    {LoginEndpoint: "https://login.chinacloudapi.cn", GraphEndpoint: "https://microsoftgraph.chinacloudapi.cn/v1.0"},
+	CloudGermany:  {LoginEndpoint: "https://login.microsoftonline.de", GraphEndpoint: "https://graph.microsoft.de/v1.0"},
+}
+
+// LookupCloud returns the endpoints of a national cloud by name (case-insensitive).
+// An empty name is the global service.
+func LookupCloud(name string) (CloudEndpoints, error) {
+	if name == "" {
+		name = CloudGlobal
+	}
+	endpoints, ok := cloudEndpoints[strings.ToLower(name)]
+	if !ok {
+		return CloudEndpoints{}, fmt.Errorf("unknown cloud %q (expected %s, %s, %s, %s or %s)",
+			name, CloudGlobal, CloudUSGov, CloudUSGovDoD, CloudChina, CloudGermany)
+	}
+	return endpoints, nil
+}
+
+// Endpoints returns the identity platform host and Graph API base URL of the configured
+// cloud, with LoginEndpoint and GraphEndpoint overriding the cloud's endpoints when set
+func (c Config) Endpoints() (CloudEndpoints, error) {
+	endpoints, err := LookupCloud(c.Cloud)
+	if err != nil {
+		return CloudEndpoints{}, err
+	}
+	if c.LoginEndpoint != "" {
+		endpoints.LoginEndpoint = c.LoginEndpoint
+	}
+	if c.GraphEndpoint != "" {
+		endpoints.GraphEndpoint = c.GraphEndpoint
+	}
+	endpoints.LoginEndpoint = strings.TrimSuffix(endpoints.LoginEndpoint, "/")
+	endpoints.GraphEndpoint = strings.TrimSuffix(endpoints.GraphEndpoint, "/")
+
+	for _, endpoint := range []string{endpoints.LoginEndpoint, endpoints.GraphEndpoint} {
+		if parsed, err := url.Parse(endpoint); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return CloudEndpoints{}, fmt.Errorf("invalid endpoint %q", endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+// OAuthConfig returns the OAuth configuration for the client credentials and cloud of c
+func (c Config) OAuthConfig(redirectURI string, scopes []string) (OAuthConfig, error) {
+	endpoints, err := c.Endpoints()
+	if err != nil {
+		return OAuthConfig{}, err
+	}
+	return OAuthConfig{
+		ClientID:      c.ClientID,
+		ClientSecret:  c.ClientSecret,
+		TenantID:      c.TenantID,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		LoginEndpoint: endpoints.LoginEndpoint,
+		GraphEndpoint: endpoints.GraphEndpoint,
+
+		CertificatePath:     c.CertificatePath,
+		CertificatePassword: c.CertificatePassword,
+		FederatedTokenFile:  c.FederatedTokenFile,
+	}, nil
+}
+
+// defaultScope returns the .default application permission scope of the Graph service
+// hosting graphEndpoint, e.g. https://graph.microsoft.us/.default
+func defaultScope(graphEndpoint string) string {
+	endpoint, err := url.Parse(graphEndpoint)
+	if err != nil || endpoint.Host == "" {
+		return "https://graph.microsoft.com/.default"
+	}
+	return endpoint.Scheme + "://" + endpoint.Host + "/.default"
+}
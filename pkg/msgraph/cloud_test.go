@@ -0,0 +1,99 @@
+package msgraph
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLookupCloud tests resolving national cloud names
+func TestLookupCloud(t *testing.T) {
+	tests := []struct {
+		name  string
+		login string
+		graph string
+	}{
+		{name: "", login: DefaultLoginEndpoint, graph: DefaultGraphEndpoint},
+		{name: "global", login: DefaultLoginEndpoint, graph: DefaultGraphEndpoint},
+		{name: "USGov", login: "https://login.microsoftonline.us", graph: "https://graph.microsoft.us/v1.0"},
+		{name: "usgov-dod", login: "https://login.microsoftonline.us", graph: "https://dod-graph.microsoft.us/v1.0"},
+		{name: "china", login: "https://login.chinacloudapi.cn", graph: "https://microsoftgraph.chinacloudapi.cn/v1.0"},
+		{name: "germany", login: "https://login.microsoftonline.de", graph: "https://graph.microsoft.de/v1.0"},
+	}
+
+	for _, tt := range tests {
+		endpoints, err := LookupCloud(tt.name)
+		if err != nil {
+			t.Errorf("Cloud %q: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if endpoints.LoginEndpoint != tt.login || endpoints.GraphEndpoint != tt.graph {
+			t.Errorf("Cloud %q: expected %s and %s, got %+v", tt.name, tt.login, tt.graph, endpoints)
+		}
+	}
+
+	if _, err := LookupCloud("mars"); err == nil {
+		t.Error("Expected an unknown cloud to be rejected")
+	}
+}
+
+// TestConfigEndpoints tests that explicit endpoints override the cloud's
+func TestConfigEndpoints(t *testing.T) {
+	endpoints, err := Config{Cloud: CloudChina, GraphEndpoint: "http://localhost:8080/v1.0/"}.Endpoints()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if endpoints.LoginEndpoint != "https://login.chinacloudapi.cn" || endpoints.GraphEndpoint != "http://localhost:8080/v1.0" {
+		t.Errorf("Unexpected endpoints: %+v", endpoints)
+	}
+
+	if _, err := (Config{LoginEndpoint: "login.microsoftonline.us"}).Endpoints(); err == nil {
+		t.Error("Expected an endpoint without scheme to be rejected")
+	}
+}
+
+// TestConfigOAuthConfig tests that OAuth uses the credentials and cloud of the Graph config
+func TestConfigOAuthConfig(t *testing.T) {
+	config := Config{ClientID: "client", ClientSecret: "secret", TenantID: "tenant", Cloud: CloudUSGov}
+
+	oauthConfig, err := config.OAuthConfig("https://localhost/callback", []string{"User.Read"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if oauthConfig.ClientID != "client" || oauthConfig.TenantID != "tenant" || oauthConfig.RedirectURI != "https://localhost/callback" {
+		t.Errorf("Unexpected OAuth config: %+v", oauthConfig)
+	}
+
+	authURL, err := (&Client{}).GenerateAuthorizationURL(oauthConfig, "state")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(authURL, "https://login.microsoftonline.us/tenant/oauth2/v2.0/authorize?") {
+		t.Errorf("Expected US Government authorization URL, got %s", authURL)
+	}
+	if oauthConfig.endpointURL("token") != "https://login.microsoftonline.us/tenant/oauth2/v2.0/token" {
+		t.Errorf("Unexpected token URL: %s", oauthConfig.endpointURL("token"))
+	}
+}
+
+// TestClientOAuthEndpoints tests that OAuth requests without endpoints use the client's cloud
+func TestClientOAuthEndpoints(t *testing.T) {
+	client := &Client{loginEndpoint: "https://login.chinacloudapi.cn", graphEndpoint: "https://microsoftgraph.chinacloudapi.cn/v1.0"}
+
+	authURL, err := client.GenerateAuthorizationURL(OAuthConfig{ClientID: "client", RedirectURI: "https://localhost/callback"}, "state")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(authURL, "https://login.chinacloudapi.cn/common/oauth2/v2.0/authorize?") {
+		t.Errorf("Expected China authorization URL, got %s", authURL)
+	}
+}
+
+// TestDefaultScope tests the .default scope of each Graph service
+func TestDefaultScope(t *testing.T) {
+	if scope := defaultScope(DefaultGraphEndpoint); scope != "https://graph.microsoft.com/.default" {
+		t.Errorf("Unexpected global scope: %s", scope)
+	}
+	if scope := defaultScope("https://dod-graph.microsoft.us/v1.0"); scope != "https://dod-graph.microsoft.us/.default" {
+		t.Errorf("Unexpected US Government L5 scope: %s", scope)
+	}
+}
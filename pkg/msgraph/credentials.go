@@ -0,0 +1,187 @@
+package msgraph
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Application authentication modes of Config
+const (
+	CredentialClientSecret     = "client_secret"
+	CredentialCertificate      = "certificate"
+	CredentialWorkloadIdentity = "workload_identity"
+)
+
+// clientAssertionType is the client_assertion_type of JWT client assertions (RFC 7523)
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// clientAssertionLifetime is how long a certificate-signed client assertion is valid
+const clientAssertionLifetime = 10 * time.Minute
+
+// CredentialType returns the application authentication mode of the configuration. A
+// federated token file or certificate takes precedence over a client secret, which may
+// still be set for the OAuth authorization code flow.
+func (c Config) CredentialType() (string, error) {
+	switch {
+	case c.FederatedTokenFile != "" && c.CertificatePath != "":
+		return "", errors.New("configure either a certificate or a federated token file, not both")
+	case c.FederatedTokenFile != "":
+		return CredentialWorkloadIdentity, nil
+	case c.CertificatePath != "":
+		return CredentialCertificate, nil
+	case c.ClientSecret != "":
+		return CredentialClientSecret, nil
+	default:
+		return "", errors.New("a client secret, certificate or federated token file is required")
+	}
+}
+
+// newCredential creates the application credential of the configuration against the
+// identity platform at loginEndpoint
+func (c Config) newCredential(loginEndpoint string) (azcore.TokenCredential, error) {
+	credentialType, err := c.CredentialType()
+	if err != nil {
+		return nil, err
+	}
+
+	clientOptions := policy.ClientOptions{
+		Cloud: cloud.Configuration{ActiveDirectoryAuthorityHost: loginEndpoint + "/"},
+	}
+
+	switch credentialType {
+	case CredentialWorkloadIdentity:
+		// The token file is re-read whenever a new access token is needed, so rotated tokens are picked up
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			ClientID:      c.ClientID,
+			TenantID:      c.TenantID,
+			TokenFilePath: c.FederatedTokenFile,
+		})
+
+	case CredentialCertificate:
+		data, err := os.ReadFile(c.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate: %w", err)
+		}
+		var password []byte
+		if c.CertificatePassword != "" {
+			password = []byte(c.CertificatePassword)
+		}
+		// PEM and PKCS#12 (PFX) files are supported; the file must hold the RSA private key
+		certificates, key, err := azidentity.ParseCertificates(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %s: %w", c.CertificatePath, err)
+		}
+		return azidentity.NewClientCertificateCredential(c.TenantID, c.ClientID, certificates, key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+
+	default:
+		return azidentity.NewClientSecretCredential(c.TenantID, c.ClientID, c.ClientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	}
+}
+
+// setClientCredential authenticates a token request to tokenURL as the app registration: with a
+// client assertion signed by the certificate, the federated token, or the client secret, in the
+// same order of precedence as Config.CredentialType. Public clients send no credential.
+func (c OAuthConfig) setClientCredential(data url.Values, tokenURL string) error {
+	switch {
+	case c.FederatedTokenFile != "" && c.CertificatePath != "":
+		return errors.New("configure either a certificate or a federated token file, not both")
+
+	case c.FederatedTokenFile != "":
+		// Re-read on every request so rotated tokens are picked up
+		token, err := os.ReadFile(c.FederatedTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read federated token: %w", err)
+		}
+		data.Set("client_assertion_type", clientAssertionType)
+		data.Set("client_assertion", strings.TrimSpace(string(token)))
+
+	case c.CertificatePath != "":
+		assertion, err := c.certificateAssertion(tokenURL)
+		if err != nil {
+			return err
+		}
+		data.Set("client_assertion_type", clientAssertionType)
+		data.Set("client_assertion", assertion)
+
+	case c.ClientSecret != "":
+		data.Set("client_secret", c.ClientSecret)
+	}
+	return nil
+}
+
+// certificateAssertion returns a JWT for tokenURL signed with the certificate's RSA key (PS256),
+// identifying the certificate by its SHA-256 thumbprint
+func (c OAuthConfig) certificateAssertion(tokenURL string) (string, error) {
+	data, err := os.ReadFile(c.CertificatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read certificate: %w", err)
+	}
+	var password []byte
+	if c.CertificatePassword != "" {
+		password = []byte(c.CertificatePassword)
+	}
+	certificates, key, err := azidentity.ParseCertificates(data, password)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse certificate %s: %w", c.CertificatePath, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok || len(certificates) == 0 {
+		return "", fmt.Errorf("certificate %s must hold a certificate and its RSA private key", c.CertificatePath)
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("failed to generate assertion ID: %w", err)
+	}
+	thumbprint := sha256.Sum256(certificates[0].Raw)
+	now := time.Now()
+
+	header, err := json.Marshal(map[string]string{
+		"alg":      "PS256",
+		"typ":      "JWT",
+		"x5t#S256": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode assertion header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": tokenURL,
+		"iss": c.ClientID,
+		"sub": c.ClientID,
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode assertion claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,221 @@
+package msgraph
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/pkg/msgraph/msgraphtest"
+)
+
+// writeTestCertificate writes a self-signed certificate and its RSA private key as PEM
+func writeTestCertificate(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "data-extraction-service"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})...)
+	path := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	return path
+}
+
+// TestConfigCredentialType tests choosing the application authentication mode
+func TestConfigCredentialType(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+		wantErr  bool
+	}{
+		{name: "client secret", config: Config{ClientSecret: "secret"}, expected: CredentialClientSecret},
+		{name: "certificate wins over secret", config: Config{ClientSecret: "secret", CertificatePath: "app.pem"}, expected: CredentialCertificate},
+		{name: "federated token file", config: Config{FederatedTokenFile: "token"}, expected: CredentialWorkloadIdentity},
+		{name: "certificate and federated token", config: Config{CertificatePath: "app.pem", FederatedTokenFile: "token"}, wantErr: true},
+		{name: "no credential", config: Config{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		credentialType, err := tt.config.CredentialType()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if credentialType != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, credentialType)
+		}
+	}
+}
+
+// TestConfigNewCredential tests creating certificate and workload identity credentials
+func TestConfigNewCredential(t *testing.T) {
+	base := Config{ClientID: "00000000-0000-0000-0000-000000000001", TenantID: "00000000-0000-0000-0000-000000000002"}
+
+	certificateConfig := base
+	certificateConfig.CertificatePath = writeTestCertificate(t)
+	if _, err := certificateConfig.newCredential(DefaultLoginEndpoint); err != nil {
+		t.Errorf("Unexpected error creating certificate credential: %v", err)
+	}
+
+	certificateConfig.CertificatePath = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := certificateConfig.newCredential(DefaultLoginEndpoint); err == nil {
+		t.Error("Expected a missing certificate file to be rejected")
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	workloadConfig := base
+	workloadConfig.FederatedTokenFile = tokenFile
+	if _, err := workloadConfig.newCredential(DefaultLoginEndpoint); err != nil {
+		t.Errorf("Unexpected error creating workload identity credential: %v", err)
+	}
+}
+
+// TestOAuthConfigSetClientCredential tests the client authentication of token requests
+func TestOAuthConfigSetClientCredential(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token: %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		config            OAuthConfig
+		expectedSecret    string
+		expectedAssertion string
+		wantErr           bool
+	}{
+		{name: "public client", config: OAuthConfig{}},
+		{name: "client secret", config: OAuthConfig{ClientSecret: "secret"}, expectedSecret: "secret"},
+		{name: "federated token wins over secret", config: OAuthConfig{ClientSecret: "secret", FederatedTokenFile: tokenFile}, expectedAssertion: "federated-token"},
+		{name: "missing federated token file", config: OAuthConfig{FederatedTokenFile: filepath.Join(t.TempDir(), "missing")}, wantErr: true},
+		{name: "certificate and federated token", config: OAuthConfig{CertificatePath: "app.pem", FederatedTokenFile: tokenFile}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := url.Values{}
+			err := tt.config.setClientCredential(data, "https://login.example/token")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if data.Get("client_secret") != tt.expectedSecret || data.Get("client_assertion") != tt.expectedAssertion {
+				t.Errorf("Unexpected credential %v", data)
+			}
+			if tt.expectedAssertion != "" && data.Get("client_assertion_type") != clientAssertionType {
+				t.Errorf("Expected client_assertion_type %s, got %s", clientAssertionType, data.Get("client_assertion_type"))
+			}
+		})
+	}
+}
+
+// TestOAuthConfigCertificateAssertion tests that the client assertion is signed with the certificate
+func TestOAuthConfigCertificateAssertion(t *testing.T) {
+	config := OAuthConfig{ClientID: "client-1", CertificatePath: writeTestCertificate(t)}
+	tokenURL := "https://login.example/tenant/oauth2/v2.0/token"
+
+	data := url.Values{}
+	if err := config.setClientCredential(data, tokenURL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data.Get("client_secret") != "" || data.Get("client_assertion_type") != clientAssertionType {
+		t.Fatalf("Expected a client assertion, got %v", data)
+	}
+
+	parts := strings.Split(data.Get("client_assertion"), ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a JWT, got %q", data.Get("client_assertion"))
+	}
+	var header map[string]string
+	var claims map[string]interface{}
+	for i, target := range []interface{}{&header, &claims} {
+		decoded, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil || json.Unmarshal(decoded, target) != nil {
+			t.Fatalf("Failed to decode JWT part %d: %v", i, err)
+		}
+	}
+
+	pemData, _ := os.ReadFile(config.CertificatePath)
+	block, _ := pem.Decode(pemData)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	thumbprint := sha256.Sum256(certificate.Raw)
+	if header["alg"] != "PS256" || header["x5t#S256"] != base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+		t.Errorf("Unexpected header %v", header)
+	}
+	if claims["aud"] != tokenURL || claims["iss"] != "client-1" || claims["sub"] != "client-1" || claims["jti"] == "" {
+		t.Errorf("Unexpected claims %v", claims)
+	}
+	if lifetime := claims["exp"].(float64) - claims["nbf"].(float64); lifetime != clientAssertionLifetime.Seconds() {
+		t.Errorf("Expected a lifetime of %s, got %vs", clientAssertionLifetime, lifetime)
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	publicKey := certificate.PublicKey.(*rsa.PublicKey)
+	if err := rsa.VerifyPSS(publicKey, crypto.SHA256, digest[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+		t.Errorf("Expected the assertion to be signed by the certificate key: %v", err)
+	}
+}
+
+// TestOAuthClientFederatedAssertion tests exchanging a code with a federated client assertion
+func TestOAuthClientFederatedAssertion(t *testing.T) {
+	server := msgraphtest.NewServer()
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte(msgraphtest.ClientAssertion), 0o600); err != nil {
+		t.Fatalf("Failed to write token: %v", err)
+	}
+	oauthClient := NewOAuthClient(OAuthConfig{
+		ClientID:           msgraphtest.ClientID,
+		FederatedTokenFile: tokenFile,
+		LoginEndpoint:      server.LoginURL(),
+		GraphEndpoint:      server.GraphURL(),
+	})
+
+	tokens, err := oauthClient.ExchangeCode(msgraphtest.AuthorizationCode)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := oauthClient.RefreshAccessToken(tokens.RefreshToken); err != nil {
+		t.Errorf("Expected refresh with the client assertion to succeed: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package msgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Device authorization poll outcomes (RFC 8628 section 3.5)
+var (
+	ErrAuthorizationPending  = errors.New("authorization pending")
+	ErrSlowDown              = errors.New("polling too frequently")
+	ErrAuthorizationDeclined = errors.New("authorization declined by the user")
+	ErrDeviceCodeExpired     = errors.New("device code expired")
+)
+
+// deviceCodeGrantType is the grant type of device code token requests
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// defaultDeviceCodeInterval is the polling interval when the identity platform sends none
+const defaultDeviceCodeInterval = 5 * time.Second
+
+// DeviceCodeResponse represents the response from the device authorization endpoint
+type DeviceCodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+}
+
+// StartDeviceCode starts a device authorization. The user enters UserCode at VerificationURI
+// on any device while the caller polls for the token.
+func (c *Client) StartDeviceCode(ctx context.Context, oauthConfig OAuthConfig) (*DeviceCodeResponse, error) {
+	if oauthConfig.ClientID == "" {
+		return nil, errors.New("client_id is required")
+	}
+
+	deviceCodeURL := c.withEndpoints(oauthConfig).endpointURL("devicecode")
+
+	// Set default scopes if not provided
+	scopes := oauthConfig.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"offline_access", "User.Read", "Mail.Read"}
+	}
+
+	data := url.Values{}
+	data.Set("client_id", oauthConfig.ClientID)
+	data.Set("scope", strings.Join(scopes, " "))
+
+	resp, err := postForm(ctx, deviceCodeURL, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make device code request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("device code request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var deviceCode DeviceCodeResponse
+	if err := json.Unmarshal(body, &deviceCode); err != nil {
+		return nil, fmt.Errorf("failed to parse device code response: %w", err)
+	}
+	return &deviceCode, nil
+}
+
+// PollDeviceCodeToken makes one token request for a started device authorization. It returns
+// ErrAuthorizationPending until the user has signed in, and ErrSlowDown when polled too often.
+func (c *Client) PollDeviceCodeToken(ctx context.Context, oauthConfig OAuthConfig, deviceCode string) (*TokenResponse, error) {
+	if deviceCode == "" {
+		return nil, errors.New("device code is required")
+	}
+
+	tokenURL := c.withEndpoints(oauthConfig).endpointURL("token")
+
+	data := url.Values{}
+	data.Set("client_id", oauthConfig.ClientID)
+	data.Set("grant_type", deviceCodeGrantType)
+	data.Set("device_code", deviceCode)
+	if err := oauthConfig.setClientCredential(data, tokenURL); err != nil {
+		return nil, err
+	}
+
+	resp, err := postForm(ctx, tokenURL, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var tokenError struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &tokenError)
+		switch tokenError.Error {
+		case "authorization_pending":
+			return nil, ErrAuthorizationPending
+		case "slow_down":
+			return nil, ErrSlowDown
+		case "authorization_declined":
+			return nil, ErrAuthorizationDeclined
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		}
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResponse TokenResponse
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	return &tokenResponse, nil
+}
+
+// WaitForDeviceCodeToken polls for the token of a started device authorization until the
+// user signs in, declines, the device code expires or ctx is cancelled
+func (c *Client) WaitForDeviceCodeToken(ctx context.Context, oauthConfig OAuthConfig, deviceCode *DeviceCodeResponse) (*TokenResponse, error) {
+	interval := time.Duration(deviceCode.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceCodeInterval
+	}
+	deadline := time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+
+	for {
+		tokenResponse, err := c.PollDeviceCodeToken(ctx, oauthConfig, deviceCode.DeviceCode)
+		switch {
+		case err == nil:
+			return tokenResponse, nil
+		case errors.Is(err, ErrSlowDown):
+			interval += 5 * time.Second // Required back-off of RFC 8628
+			log.Printf("⏳ Slowing down device code polling to every %s", interval)
+		case !errors.Is(err, ErrAuthorizationPending):
+			return nil, err
+		}
+
+		if deviceCode.ExpiresIn > 0 && time.Now().Add(interval).After(deadline) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// StartDeviceAuthorization starts a device authorization
+func (oc *OAuthClient) StartDeviceAuthorization(ctx context.Context) (*DeviceCodeResponse, error) {
+	client := &Client{} // Create temporary client for method access
+	return client.StartDeviceCode(ctx, oc.config)
+}
+
+// PollDeviceAuthorization makes one token request for a started device authorization
+func (oc *OAuthClient) PollDeviceAuthorization(ctx context.Context, deviceCode string) (*TokenResponse, error) {
+	client := &Client{} // Create temporary client for method access
+	return client.PollDeviceCodeToken(ctx, oc.config, deviceCode)
+}
+
+// WaitForDeviceAuthorization polls until the user completes a started device authorization
+func (oc *OAuthClient) WaitForDeviceAuthorization(ctx context.Context, deviceCode *DeviceCodeResponse) (*TokenResponse, error) {
+	client := &Client{} // Create temporary client for method access
+	return client.WaitForDeviceCodeToken(ctx, oc.config, deviceCode)
+}
@@ -0,0 +1,105 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/pkg/msgraph/msgraphtest"
+)
+
+// newDeviceCodeTestClient creates an OAuth client for the fake identity platform
+func newDeviceCodeTestClient(server *msgraphtest.Server) *OAuthClient {
+	return NewOAuthClient(OAuthConfig{
+		ClientID:      msgraphtest.ClientID,
+		ClientSecret:  msgraphtest.ClientSecret,
+		LoginEndpoint: server.LoginURL(),
+		GraphEndpoint: server.GraphURL(),
+	})
+}
+
+// TestDeviceCodeFlow tests starting a device authorization and polling until sign-in completes
+func TestDeviceCodeFlow(t *testing.T) {
+	server := msgraphtest.NewServer()
+	defer server.Close()
+	oauthClient := newDeviceCodeTestClient(server)
+
+	deviceCode, err := oauthClient.StartDeviceAuthorization(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deviceCode.DeviceCode != msgraphtest.DeviceCode || deviceCode.UserCode != msgraphtest.UserCode || deviceCode.Message == "" {
+		t.Errorf("Unexpected device code response: %+v", deviceCode)
+	}
+
+	server.SetDeviceCodePending(1)
+	if _, err := oauthClient.PollDeviceAuthorization(context.Background(), deviceCode.DeviceCode); !errors.Is(err, ErrAuthorizationPending) {
+		t.Errorf("Expected ErrAuthorizationPending, got %v", err)
+	}
+
+	server.SetDeviceCodePending(1)
+	tokens, err := oauthClient.WaitForDeviceAuthorization(context.Background(), deviceCode)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tokens.RefreshToken == "" {
+		t.Error("Expected a refresh token")
+	}
+	if err := oauthClient.TestToken(tokens.AccessToken); err != nil {
+		t.Errorf("Expected issued token to be valid: %v", err)
+	}
+}
+
+// TestDeviceCodeFlowDeclined tests that waiting stops when the user declines
+func TestDeviceCodeFlowDeclined(t *testing.T) {
+	server := msgraphtest.NewServer()
+	defer server.Close()
+	oauthClient := newDeviceCodeTestClient(server)
+
+	deviceCode, err := oauthClient.StartDeviceAuthorization(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	server.DeclineDeviceCode()
+	if _, err := oauthClient.WaitForDeviceAuthorization(context.Background(), deviceCode); !errors.Is(err, ErrAuthorizationDeclined) {
+		t.Errorf("Expected ErrAuthorizationDeclined, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pending := *deviceCode
+	pending.DeviceCode = "unknown-device-code"
+	if _, err := oauthClient.WaitForDeviceAuthorization(ctx, &pending); err == nil {
+		t.Error("Expected an unknown device code to be rejected")
+	}
+}
+
+// TestDeviceCodeStalledEndpoint tests that a stalled identity platform does not outlive the context
+func TestDeviceCodeStalledEndpoint(t *testing.T) {
+	unblock := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer stalled.Close()
+	// Deferred calls run in reverse, so the handlers return before Close waits for them
+	defer close(unblock)
+
+	oauthClient := NewOAuthClient(OAuthConfig{ClientID: msgraphtest.ClientID, LoginEndpoint: stalled.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	if _, err := oauthClient.StartDeviceAuthorization(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if _, err := oauthClient.PollDeviceAuthorization(ctx, msgraphtest.DeviceCode); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Expected requests to stop with the context, took %s", elapsed)
+	}
+}
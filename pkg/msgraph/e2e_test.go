@@ -0,0 +1,331 @@
+package msgraph_test
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/pkg/msgraph"
+	"github.com/ishank09/data-extraction-service/pkg/msgraph/msgraphtest"
+)
+
+// newFakeOneNote starts a fake Graph server with two notebooks, three sections and five pages
+func newFakeOneNote() *msgraphtest.Server {
+	server := msgraphtest.NewServer()
+
+	server.AddNotebook("nb-1", "Work")
+	server.AddNotebook("nb-2", "Personal")
+	server.AddSection("nb-1", "sec-1", "Meetings")
+	server.AddSection("nb-1", "sec-2", "Projects")
+	server.AddSection("nb-2", "sec-3", "Recipes")
+	server.AddPage("sec-1", "page-1", "Standup", "<html><body><p>Standup notes</p></body></html>")
+	server.AddPage("sec-1", "page-2", "Planning", "<html><body><p>Sprint planning</p></body></html>")
+	server.AddPage("sec-1", "page-3", "Retro", "<html><body><p>Retrospective</p></body></html>")
+	server.AddPage("sec-2", "page-4", "Roadmap", "<html><body><p>Roadmap</p></body></html>")
+	server.AddPage("sec-3", "page-5", "Pancakes", "<html><body><p>Flour, eggs, milk</p></body></html>")
+
+	return server
+}
+
+// newFakeClient creates a token-based client for the fake server with small Graph pages and fast retries
+func newFakeClient(t *testing.T, server *msgraphtest.Server) *msgraph.Client {
+	t.Helper()
+	client, err := msgraph.NewClientWithTokenAndConfig(msgraphtest.AccessToken, msgraph.Config{
+		GraphEndpoint:   server.GraphURL(),
+		Pagination:      &msgraph.PaginationConfig{PageSize: 2},
+		Retry:           &msgraph.RetryConfig{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+		CheckpointStore: msgraph.NewMemoryCheckpointStore(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+// documentIDs returns the sorted IDs of the page documents in a collection
+func documentIDs(collection *types.DocumentCollection) []string {
+	var ids []string
+	for _, doc := range collection.Documents {
+		if doc.Type == "page" {
+			ids = append(ids, doc.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// nextPageRequests counts the requests for following pages of a collection whose path ends with pathSuffix
+func nextPageRequests(server *msgraphtest.Server, pathSuffix string) int {
+	count := 0
+	for _, req := range server.Requests() {
+		if strings.HasSuffix(req.Path, pathSuffix) && req.Query.Get("$skip") != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// TestEndToEndOneNoteExtraction tests a full crawl across nextLink pages and throttled responses,
+// including a throttled nextLink page
+func TestEndToEndOneNoteExtraction(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+	server.Throttle(2, "0")
+	server.ThrottleNextPage("/sections/sec-1/pages", 2, "0")
+
+	collection, err := newFakeClient(t, server).GetOneNoteDataAsJSON(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ids := documentIDs(collection); strings.Join(ids, ",") != "page-1,page-2,page-3,page-4,page-5" {
+		t.Errorf("Expected all 5 pages, got %v", ids)
+	}
+	if status := collection.Status(); status != types.CollectionStatusComplete {
+		t.Errorf("Expected status %s, got %s (errors: %+v)", types.CollectionStatusComplete, status, collection.Errors)
+	}
+	if server.ThrottledCount() != 4 {
+		t.Errorf("Expected 4 throttled requests, got %d", server.ThrottledCount())
+	}
+	if count := nextPageRequests(server, "/sections/sec-1/pages"); count != 3 {
+		t.Errorf("Expected the throttled nextLink page to be requested 3 times, got %d", count)
+	}
+}
+
+// TestEndToEndThrottledNextPage tests that a nextLink page is only retried by the client's retry
+// policy and that the pages listed before it are kept when it stays throttled
+func TestEndToEndThrottledNextPage(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+	server.ThrottleNextPage("/sections/sec-1/pages", 2, "0")
+
+	client, err := msgraph.NewClientWithTokenAndConfig(msgraphtest.AccessToken, msgraph.Config{
+		GraphEndpoint:   server.GraphURL(),
+		Pagination:      &msgraph.PaginationConfig{PageSize: 2},
+		Retry:           &msgraph.RetryConfig{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		CheckpointStore: msgraph.NewMemoryCheckpointStore(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	collection, err := client.GetOneNoteDataAsJSON(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// One retry and no hidden retries in the SDK middleware
+	if count := nextPageRequests(server, "/sections/sec-1/pages"); count != 2 {
+		t.Errorf("Expected the throttled nextLink page to be requested twice, got %d", count)
+	}
+	if ids := documentIDs(collection); strings.Join(ids, ",") != "page-1,page-2,page-4,page-5" {
+		t.Errorf("Expected the pages listed before the throttled page to be kept, got %v", ids)
+	}
+	if status := collection.Status(); status != types.CollectionStatusPartial {
+		t.Errorf("Expected status %s, got %s", types.CollectionStatusPartial, status)
+	}
+	if len(collection.Errors) != 1 || collection.Errors[0].ItemID != "sec-1" || collection.Errors[0].ErrorClass != "throttled" {
+		t.Errorf("Expected a throttled error for sec-1, got %+v", collection.Errors)
+	}
+}
+
+// TestEndToEndPartialFailure tests that a failed page download is reported without failing the crawl
+func TestEndToEndPartialFailure(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+	server.Fail("/pages/page-2/content", http.StatusInternalServerError)
+
+	collection, err := newFakeClient(t, server).GetOneNoteDataAsJSON(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(documentIDs(collection)) != 4 {
+		t.Errorf("Expected 4 pages, got %v", documentIDs(collection))
+	}
+	if status := collection.Status(); status != types.CollectionStatusPartial {
+		t.Errorf("Expected status %s, got %s", types.CollectionStatusPartial, status)
+	}
+	if len(collection.Errors) != 1 || collection.Errors[0].ItemID != "page-2" {
+		t.Errorf("Expected an error for page-2, got %+v", collection.Errors)
+	}
+}
+
+// TestEndToEndIncrementalSync tests that a second sync only returns changed and deleted pages
+func TestEndToEndIncrementalSync(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+	client := newFakeClient(t, server)
+
+	first, err := client.SyncOneNoteData(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error on first sync: %v", err)
+	}
+	if !first.FullSync || len(first.AddedPageIDs) != 5 {
+		t.Fatalf("Expected a full first sync adding 5 pages, got %+v", first)
+	}
+
+	// Without a commit the next sync reports the same pages again
+	retried, err := client.SyncOneNoteData(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error on uncommitted sync: %v", err)
+	}
+	if !retried.FullSync || len(retried.AddedPageIDs) != 5 {
+		t.Fatalf("Expected an uncommitted sync to be repeated, got %+v", retried)
+	}
+	if err := client.CommitSync(context.Background(), first); err != nil {
+		t.Fatalf("Unexpected error committing sync: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	server.UpdatePage("page-1", "<html><body><p>Updated standup notes</p></body></html>")
+	server.DeletePage("page-5")
+
+	second, err := client.SyncOneNoteData(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error on second sync: %v", err)
+	}
+	if second.FullSync {
+		t.Error("Expected an incremental second sync")
+	}
+	if strings.Join(second.ChangedPageIDs, ",") != "page-1" || strings.Join(second.DeletedPageIDs, ",") != "page-5" {
+		t.Errorf("Expected page-1 changed and page-5 deleted, got %v and %v", second.ChangedPageIDs, second.DeletedPageIDs)
+	}
+	if ids := documentIDs(second.Collection); strings.Join(ids, ",") != "page-1" {
+		t.Errorf("Expected only page-1 to be downloaded, got %v", ids)
+	}
+}
+
+// TestEndToEndSyncFilterChange tests that narrowing the filter does not report the excluded
+// pages as deleted
+func TestEndToEndSyncFilterChange(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+	client := newFakeClient(t, server)
+
+	first, err := client.SyncOneNoteData(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error on first sync: %v", err)
+	}
+	if err := client.CommitSync(context.Background(), first); err != nil {
+		t.Fatalf("Unexpected error committing sync: %v", err)
+	}
+
+	ctx, err := msgraph.WithFilter(context.Background(), msgraph.FilterConfig{
+		ExcludeNotebooks: []string{"Personal"},
+		ExcludeSections:  []string{"sec-2"},
+		PageTitlePattern: "^(Standup|Retro)$",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating filter: %v", err)
+	}
+	server.DeletePage("page-3")
+
+	second, err := client.SyncOneNoteData(ctx, false)
+	if err != nil {
+		t.Fatalf("Unexpected error on filtered sync: %v", err)
+	}
+	if !second.DeletionsDetected || strings.Join(second.DeletedPageIDs, ",") != "page-3" {
+		t.Errorf("Expected only page-3 to be deleted, got %v", second.DeletedPageIDs)
+	}
+}
+
+// TestEndToEndOAuth tests the authorization code exchange, token refresh and token test
+// against the fake identity platform
+func TestEndToEndOAuth(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+
+	oauthClient := msgraph.NewOAuthClient(msgraph.OAuthConfig{
+		ClientID:      msgraphtest.ClientID,
+		ClientSecret:  msgraphtest.ClientSecret,
+		RedirectURI:   "http://localhost/callback",
+		LoginEndpoint: server.LoginURL(),
+		GraphEndpoint: server.GraphURL(),
+	})
+
+	authURL, err := oauthClient.GetAuthorizationURL("state")
+	if err != nil || !strings.HasPrefix(authURL, server.LoginURL()+"/common/oauth2/v2.0/authorize?") {
+		t.Errorf("Expected authorization URL on the fake server, got %s (%v)", authURL, err)
+	}
+
+	tokens, err := oauthClient.ExchangeCode(msgraphtest.AuthorizationCode)
+	if err != nil {
+		t.Fatalf("Unexpected error exchanging code: %v", err)
+	}
+	if err := oauthClient.TestToken(tokens.AccessToken); err != nil {
+		t.Errorf("Expected issued token to be valid: %v", err)
+	}
+
+	server.ExpireToken(tokens.AccessToken)
+	if err := oauthClient.TestToken(tokens.AccessToken); err == nil {
+		t.Error("Expected expired token to be rejected")
+	}
+
+	refreshed, err := oauthClient.RefreshAccessToken(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Unexpected error refreshing token: %v", err)
+	}
+
+	client, err := msgraph.NewClientWithTokenAndConfig(refreshed.AccessToken, msgraph.Config{GraphEndpoint: server.GraphURL()})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	collection, err := client.GetOneNoteDataAsJSON(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(documentIDs(collection)) != 5 {
+		t.Errorf("Expected 5 pages with the refreshed token, got %v", documentIDs(collection))
+	}
+
+	if _, err := oauthClient.ExchangeCode("invalid-code"); err == nil {
+		t.Error("Expected an invalid code to be rejected")
+	}
+}
+
+// TestEndToEndOAuthPKCE tests that the code verifier of an issued state completes a PKCE exchange
+func TestEndToEndOAuthPKCE(t *testing.T) {
+	server := newFakeOneNote()
+	defer server.Close()
+
+	oauthClient := msgraph.NewOAuthClient(msgraph.OAuthConfig{
+		ClientID:      msgraphtest.ClientID,
+		ClientSecret:  msgraphtest.ClientSecret,
+		RedirectURI:   "http://localhost/callback",
+		LoginEndpoint: server.LoginURL(),
+		GraphEndpoint: server.GraphURL(),
+	})
+	states := msgraph.NewOAuthStateManager(nil)
+	ctx := context.Background()
+
+	request, err := states.Issue(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	authURL, err := oauthClient.GetAuthorizationURLWithPKCE(request.State, request.CodeChallenge)
+	if err != nil || !strings.Contains(authURL, "code_challenge="+request.CodeChallenge) {
+		t.Errorf("Expected the code challenge in the authorization URL, got %s (%v)", authURL, err)
+	}
+	server.RequireCodeChallenge(request.CodeChallenge)
+
+	if _, err := oauthClient.ExchangeCode(msgraphtest.AuthorizationCode); err == nil {
+		t.Error("Expected an exchange without code verifier to be rejected")
+	}
+
+	codeVerifier, err := states.Validate(ctx, request.State, "session-1")
+	if err != nil {
+		t.Fatalf("Unexpected error validating state: %v", err)
+	}
+	tokens, err := oauthClient.ExchangeCodeWithPKCE(msgraphtest.AuthorizationCode, codeVerifier)
+	if err != nil {
+		t.Fatalf("Unexpected error exchanging code: %v", err)
+	}
+	if err := oauthClient.TestToken(tokens.AccessToken); err != nil {
+		t.Errorf("Expected issued token to be valid: %v", err)
+	}
+}
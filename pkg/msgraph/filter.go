@@ -0,0 +1,118 @@
+package msgraph
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// FilterConfig limits a OneNote crawl to some notebooks, sections and pages. Notebooks and
+// sections match by ID or by display name (case-insensitive). Empty include lists allow
+// everything, and excludes take precedence over includes.
+type FilterConfig struct {
+	IncludeNotebooks []string // Notebook names or IDs to crawl (empty crawls all notebooks)
+	ExcludeNotebooks []string // Notebook names or IDs to skip
+	IncludeSections  []string // Section names or IDs to crawl (empty crawls all sections)
+	ExcludeSections  []string // Section names or IDs to skip
+	PageTitlePattern string   // Regular expression page titles must match (empty matches all pages)
+}
+
+// IsEmpty returns true if the filter allows every notebook, section and page
+func (f FilterConfig) IsEmpty() bool {
+	return len(f.IncludeNotebooks) == 0 && len(f.ExcludeNotebooks) == 0 &&
+		len(f.IncludeSections) == 0 && len(f.ExcludeSections) == 0 &&
+		f.PageTitlePattern == ""
+}
+
+// compiledFilter is a FilterConfig with its page title pattern compiled.
+// A nil filter allows everything.
+type compiledFilter struct {
+	config    FilterConfig
+	pageTitle *regexp.Regexp
+}
+
+// compile validates the filter, returning nil when it allows everything
+func (f FilterConfig) compile() (*compiledFilter, error) {
+	if f.IsEmpty() {
+		return nil, nil
+	}
+
+	filter := &compiledFilter{config: f}
+	if f.PageTitlePattern != "" {
+		pattern, err := regexp.Compile(f.PageTitlePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page title pattern: %w", err)
+		}
+		filter.pageTitle = pattern
+	}
+	return filter, nil
+}
+
+// filterContextKey is the context key of a per-request filter
+type filterContextKey struct{}
+
+// WithFilter returns a context that restricts OneNote crawls started with it to filter,
+// replacing the filter configured on the client
+func WithFilter(ctx context.Context, filter FilterConfig) (context.Context, error) {
+	compiled, err := filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, filterContextKey{}, compiled), nil
+}
+
+// filterFor returns the filter of a crawl: the per-request filter if one was set, otherwise
+// the filter configured on the client
+func (c *Client) filterFor(ctx context.Context) *compiledFilter {
+	if filter, ok := ctx.Value(filterContextKey{}).(*compiledFilter); ok {
+		return filter
+	}
+	return c.filter
+}
+
+// notebookAllowed reports whether the sections of a notebook are crawled
+func (f *compiledFilter) notebookAllowed(id, name string) bool {
+	if f == nil {
+		return true
+	}
+	return allowedByLists(f.config.IncludeNotebooks, f.config.ExcludeNotebooks, id, name)
+}
+
+// sectionAllowed reports whether the pages of a section are listed
+func (f *compiledFilter) sectionAllowed(section msgraphmodels.OnenoteSectionable) bool {
+	if f == nil {
+		return true
+	}
+	id := getStringValue(section.GetId())
+	name := getStringValue(section.GetDisplayName())
+	return allowedByLists(f.config.IncludeSections, f.config.ExcludeSections, id, name)
+}
+
+// pageAllowed reports whether the content of a page is downloaded
+func (f *compiledFilter) pageAllowed(page msgraphmodels.OnenotePageable) bool {
+	if f == nil || f.pageTitle == nil {
+		return true
+	}
+	return f.pageTitle.MatchString(getStringValue(page.GetTitle()))
+}
+
+// allowedByLists applies include and exclude lists to an item with the given ID and name
+func allowedByLists(include, exclude []string, id, name string) bool {
+	if matchesAny(exclude, id, name) {
+		return false
+	}
+	return len(include) == 0 || matchesAny(include, id, name)
+}
+
+// matchesAny reports whether any value equals the ID or, ignoring case, the name
+func matchesAny(values []string, id, name string) bool {
+	for _, value := range values {
+		if (id != "" && value == id) || (name != "" && strings.EqualFold(value, name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package msgraph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// TestFilterNotebooksAndSections tests include and exclude lists by name and ID
+func TestFilterNotebooksAndSections(t *testing.T) {
+	filter, err := FilterConfig{
+		IncludeNotebooks: []string{"work", "nb-2"},
+		ExcludeNotebooks: []string{"nb-3"},
+		ExcludeSections:  []string{"Archive", "sec-9"},
+	}.compile()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	notebooks := []struct {
+		id, name string
+		expected bool
+	}{
+		{id: "nb-1", name: "Work", expected: true},
+		{id: "nb-2", name: "Personal", expected: true},
+		{id: "nb-3", name: "Work", expected: false},
+		{id: "nb-4", name: "Recipes", expected: false},
+	}
+	for _, nb := range notebooks {
+		if got := filter.notebookAllowed(nb.id, nb.name); got != nb.expected {
+			t.Errorf("Notebook %s (%s): expected %v, got %v", nb.id, nb.name, nb.expected, got)
+		}
+	}
+
+	sections := []struct {
+		section  msgraphmodels.OnenoteSectionable
+		expected bool
+	}{
+		{section: createMockSection("sec-1", "Meetings"), expected: true},
+		{section: createMockSection("sec-2", "archive"), expected: false},
+		{section: createMockSection("sec-9", "Ideas"), expected: false},
+	}
+	for _, tt := range sections {
+		if got := filter.sectionAllowed(tt.section); got != tt.expected {
+			t.Errorf("Section %s: expected %v, got %v", getStringValue(tt.section.GetId()), tt.expected, got)
+		}
+	}
+}
+
+// TestFilterPageTitle tests matching page titles against the pattern
+func TestFilterPageTitle(t *testing.T) {
+	filter, err := FilterConfig{PageTitlePattern: `(?i)^meeting`}.compile()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !filter.pageAllowed(createMockPage("p1", "Meeting notes", time.Time{})) {
+		t.Error("Expected matching title to be allowed")
+	}
+	if filter.pageAllowed(createMockPage("p2", "Shopping list", time.Time{})) {
+		t.Error("Expected other title to be filtered out")
+	}
+
+	if _, err := (FilterConfig{PageTitlePattern: "("}).compile(); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+// TestNilFilterAllowsEverything tests that an empty filter compiles to nil and allows everything
+func TestNilFilterAllowsEverything(t *testing.T) {
+	filter, err := FilterConfig{}.compile()
+	if err != nil || filter != nil {
+		t.Fatalf("Expected nil filter, got %v (%v)", filter, err)
+	}
+
+	if !filter.notebookAllowed("nb-1", "Work") ||
+		!filter.sectionAllowed(createMockSection("sec-1", "Meetings")) ||
+		!filter.pageAllowed(createMockPage("p1", "Notes", time.Time{})) {
+		t.Error("Expected nil filter to allow everything")
+	}
+}
+
+// TestWithFilter tests that a per-request filter replaces the client filter
+func TestWithFilter(t *testing.T) {
+	configured, _ := FilterConfig{IncludeNotebooks: []string{"Work"}}.compile()
+	client := &Client{filter: configured}
+
+	if client.filterFor(context.Background()) != configured {
+		t.Error("Expected the client filter without a per-request filter")
+	}
+
+	ctx, err := WithFilter(context.Background(), FilterConfig{IncludeNotebooks: []string{"Personal"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	filter := client.filterFor(ctx)
+	if filter.notebookAllowed("nb-1", "Work") || !filter.notebookAllowed("nb-2", "Personal") {
+		t.Error("Expected the per-request filter to replace the client filter")
+	}
+
+	if _, err := WithFilter(context.Background(), FilterConfig{PageTitlePattern: "["}); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
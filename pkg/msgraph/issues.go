@@ -0,0 +1,91 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+)
+
+// Extraction stages reported in collection issues
+const (
+	stageListNotebooks     = "list_notebooks"
+	stageListSections      = "list_sections"
+	stageListPages         = "list_pages"
+	stageFetchContent      = "fetch_content"
+	stageFetchAttachment   = "fetch_attachment"
+	stageProcessPage       = "process_page"
+	stageProcessAttachment = "process_attachment"
+)
+
+// Error classes reported in collection issues
+const (
+	errorClassThrottled    = "throttled"
+	errorClassUnauthorized = "unauthorized"
+	errorClassForbidden    = "forbidden"
+	errorClassNotFound     = "not_found"
+	errorClassServer       = "server"
+	errorClassTimeout      = "timeout"
+	errorClassCanceled     = "canceled"
+	errorClassNetwork      = "network"
+	errorClassLimit        = "limit"
+	errorClassUnsupported  = "unsupported"
+	errorClassUnknown      = "unknown"
+)
+
+// newIssue describes a failed item, classifying err to tell consumers whether a retry may help
+func newIssue(itemType, itemID, stage string, err error) types.Issue {
+	class, retryable := classifyError(err)
+	return types.Issue{
+		ItemID:     itemID,
+		ItemType:   itemType,
+		Stage:      stage,
+		ErrorClass: class,
+		Retryable:  retryable,
+		Message:    err.Error(),
+	}
+}
+
+// classifyError returns the error class of err and whether retrying the request may succeed
+func classifyError(err error) (string, bool) {
+	if errors.Is(err, errAttachmentTooLarge) {
+		return errorClassLimit, false
+	}
+
+	if throttled, _ := throttleInfo(err); throttled {
+		return errorClassThrottled, true
+	}
+
+	if statusCode, _, ok := graphResponse(err); ok {
+		switch {
+		case statusCode == http.StatusUnauthorized:
+			return errorClassUnauthorized, false
+		case statusCode == http.StatusForbidden:
+			return errorClassForbidden, false
+		case statusCode == http.StatusNotFound || statusCode == http.StatusGone:
+			return errorClassNotFound, false
+		case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+			return errorClassTimeout, true
+		case statusCode >= 500:
+			return errorClassServer, true
+		}
+		return errorClassUnknown, false
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorClassTimeout, true
+	case errors.Is(err, context.Canceled):
+		return errorClassCanceled, true
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return errorClassTimeout, true
+		}
+		return errorClassNetwork, true
+	}
+
+	return errorClassUnknown, false
+}
@@ -0,0 +1,73 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+)
+
+// TestClassifyError tests mapping errors to error classes and retryability
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		class     string
+		retryable bool
+	}{
+		{name: "throttled", err: throttledError(429, "5"), class: errorClassThrottled, retryable: true},
+		{name: "exhausted retries", err: fmt.Errorf("still throttled after 5 retries: %w", throttledError(503, "")), class: errorClassThrottled, retryable: true},
+		{name: "unauthorized", err: throttledError(401, ""), class: errorClassUnauthorized, retryable: false},
+		{name: "forbidden", err: throttledError(403, ""), class: errorClassForbidden, retryable: false},
+		{name: "not found", err: throttledError(404, ""), class: errorClassNotFound, retryable: false},
+		{name: "server error", err: throttledError(500, ""), class: errorClassServer, retryable: true},
+		{name: "gateway timeout", err: throttledError(504, ""), class: errorClassTimeout, retryable: true},
+		{name: "deadline", err: fmt.Errorf("failed: %w", context.DeadlineExceeded), class: errorClassTimeout, retryable: true},
+		{name: "canceled", err: context.Canceled, class: errorClassCanceled, retryable: true},
+		{name: "unknown", err: errors.New("boom"), class: errorClassUnknown, retryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, retryable := classifyError(tt.err)
+			if class != tt.class || retryable != tt.retryable {
+				t.Errorf("Expected (%s, %v), got (%s, %v)", tt.class, tt.retryable, class, retryable)
+			}
+		})
+	}
+}
+
+// TestNewIssue tests describing a failed item
+func TestNewIssue(t *testing.T) {
+	issue := newIssue("page", "page-1", stageFetchContent, throttledError(429, ""))
+
+	if issue.ItemID != "page-1" || issue.ItemType != "page" || issue.Stage != stageFetchContent {
+		t.Errorf("Unexpected item in issue: %+v", issue)
+	}
+	if issue.ErrorClass != errorClassThrottled || !issue.Retryable || issue.Message == "" {
+		t.Errorf("Unexpected classification in issue: %+v", issue)
+	}
+}
+
+// TestBuildOneNoteCollectionCarriesIssues tests that fetch errors and warnings reach the collection
+func TestBuildOneNoteCollectionCarriesIssues(t *testing.T) {
+	client := &Client{}
+	rawData := &OneNoteRawData{
+		Errors:   []types.Issue{newIssue("section", "section-1", stageListPages, throttledError(503, ""))},
+		Warnings: []types.Issue{{ItemID: "resource-1", ItemType: "attachment", Stage: stageFetchAttachment, ErrorClass: errorClassUnsupported}},
+	}
+
+	collection := client.buildOneNoteCollection(context.Background(), rawData)
+
+	if len(collection.Errors) != 1 || collection.Errors[0].ItemID != "section-1" {
+		t.Errorf("Expected the section error to be carried over, got %+v", collection.Errors)
+	}
+	if len(collection.Warnings) != 1 {
+		t.Errorf("Expected the attachment warning to be carried over, got %+v", collection.Warnings)
+	}
+	if status := collection.Status(); status != types.CollectionStatusFailed {
+		t.Errorf("Expected status %s, got %s", types.CollectionStatusFailed, status)
+	}
+}
@@ -0,0 +1,651 @@
+// Package msgraphtest provides a local fake of the Microsoft Graph OneNote API and the
+// Microsoft identity platform token endpoint for end-to-end tests.
+//
+// Point a client at the fake by setting the Graph endpoint to GraphURL() and the login
+// endpoint to LoginURL(). The fake serves plain HTTP, so it supports the token-based and
+// OAuth flows; azidentity credentials require an https authority host.
+package msgraphtest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials and identifiers accepted by the fake server
+const (
+	ClientID          = "test-client-id"
+	ClientSecret      = "test-client-secret"
+	ClientAssertion   = "test-client-assertion" // Federated token accepted as a JWT client assertion
+	AuthorizationCode = "test-authorization-code"
+	AccessToken       = "test-access-token"
+	UserID            = "test-user-id"
+	UserPrincipalName = "test.user@example.com"
+	DeviceCode        = "test-device-code"
+	UserCode          = "TESTCODE"
+)
+
+// Request is a Graph or token endpoint request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+}
+
+type notebook struct {
+	id      string
+	name    string
+	created time.Time
+}
+
+type section struct {
+	id         string
+	name       string
+	notebookID string
+	created    time.Time
+}
+
+type page struct {
+	id        string
+	title     string
+	sectionID string
+	content   string
+	created   time.Time
+	modified  time.Time
+}
+
+type resource struct {
+	contentType string
+	data        []byte
+}
+
+// Server is a fake Microsoft Graph and identity platform server
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	pageSize  int
+	notebooks []*notebook
+	sections  []*section
+	pages     []*page
+	resources map[string]resource
+	failures  map[string]int
+
+	accessTokens  map[string]bool
+	refreshTokens map[string]bool
+	tokenCount    int
+	codeChallenge string
+
+	devicePending  int
+	deviceDeclined bool
+
+	throttleRemaining int
+	throttleAfter     string
+	throttled         int
+	throttledPaths    map[string]int
+
+	requests []Request
+}
+
+// NewServer starts a fake server with no OneNote content. AccessToken is accepted
+// until it is expired; call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		resources:      make(map[string]resource),
+		failures:       make(map[string]int),
+		throttledPaths: make(map[string]int),
+		accessTokens:   map[string]bool{AccessToken: true},
+		refreshTokens:  make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.0/", s.handleGraph)
+	mux.HandleFunc("/", s.handleLogin)
+	s.server = httptest.NewServer(s.record(mux))
+
+	return s
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// GraphURL returns the Graph API base URL, to use as the Graph endpoint of a client
+func (s *Server) GraphURL() string {
+	return s.server.URL + "/v1.0"
+}
+
+// LoginURL returns the identity platform host, to use as the login endpoint of a client
+func (s *Server) LoginURL() string {
+	return s.server.URL
+}
+
+// ResourceURL returns the URL page content uses to reference a resource
+func (s *Server) ResourceURL(id string) string {
+	return fmt.Sprintf("%s/users/%s/onenote/resources/%s/$value", s.GraphURL(), UserID, id)
+}
+
+// SetPageSize limits the items returned per collection page, so larger collections are
+// split across @odata.nextLink pages. Zero (the default) only honours $top.
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+// AddNotebook adds a notebook
+func (s *Server) AddNotebook(id, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notebooks = append(s.notebooks, &notebook{id: id, name: name, created: time.Now().UTC()})
+}
+
+// AddSection adds a section to a notebook
+func (s *Server) AddSection(notebookID, id, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sections = append(s.sections, &section{id: id, name: name, notebookID: notebookID, created: time.Now().UTC()})
+}
+
+// AddPage adds a page with the given HTML content to a section
+func (s *Server) AddPage(sectionID, id, title, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	s.pages = append(s.pages, &page{id: id, title: title, sectionID: sectionID, content: content, created: now, modified: now})
+}
+
+// UpdatePage replaces the content of a page and sets its lastModifiedDateTime to now
+func (s *Server) UpdatePage(id, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.pages {
+		if p.id == id {
+			p.content = content
+			p.modified = time.Now().UTC()
+		}
+	}
+}
+
+// DeletePage removes a page
+func (s *Server) DeletePage(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.pages {
+		if p.id == id {
+			s.pages = append(s.pages[:i], s.pages[i+1:]...)
+			return
+		}
+	}
+}
+
+// AddResource adds a page attachment or image, served from ResourceURL(id)
+func (s *Server) AddResource(id, contentType string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources[id] = resource{contentType: contentType, data: data}
+}
+
+// Fail makes every Graph request whose path ends with pathSuffix fail with statusCode,
+// e.g. Fail("/pages/page-1/content", http.StatusInternalServerError)
+func (s *Server) Fail(pathSuffix string, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[pathSuffix] = statusCode
+}
+
+// Throttle answers the next count Graph requests with 429 Too Many Requests and the
+// given Retry-After header (omitted when empty)
+func (s *Server) Throttle(count int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttleRemaining = count
+	s.throttleAfter = retryAfter
+}
+
+// ThrottleNextPage answers the next count requests for a following page of a collection
+// whose path ends with pathSuffix, i.e. requests made from an @odata.nextLink, with
+// 429 Too Many Requests and the given Retry-After header (omitted when empty)
+func (s *Server) ThrottleNextPage(pathSuffix string, count int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttledPaths[pathSuffix] = count
+	s.throttleAfter = retryAfter
+}
+
+// ThrottledCount returns the number of requests answered with 429
+func (s *Server) ThrottledCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.throttled
+}
+
+// ExpireToken makes Graph reject an access token with 401 Unauthorized
+func (s *Server) ExpireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.accessTokens, token)
+}
+
+// RequireCodeChallenge makes the authorization code grant require a PKCE code verifier
+// matching an S256 code challenge
+func (s *Server) RequireCodeChallenge(codeChallenge string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codeChallenge = codeChallenge
+}
+
+// SetDeviceCodePending makes the device code grant answer authorization_pending for the
+// next polls, as if the user had not signed in yet
+func (s *Server) SetDeviceCodePending(polls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devicePending = polls
+}
+
+// DeclineDeviceCode makes the device code grant answer authorization_declined
+func (s *Server) DeclineDeviceCode() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviceDeclined = true
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// record stores every request before handling it
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()})
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGraph serves the Graph user and OneNote endpoints under /me and /users/{id}
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		writeGraphError(w, http.StatusMethodNotAllowed, "BadRequest", "only GET is supported")
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !s.accessTokens[token] {
+		writeGraphError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "access token is missing, invalid or expired")
+		return
+	}
+
+	throttle := s.throttleRemaining > 0
+	if throttle {
+		s.throttleRemaining--
+	} else if r.URL.Query().Get("$skip") != "" {
+		for suffix, remaining := range s.throttledPaths {
+			if remaining > 0 && strings.HasSuffix(r.URL.Path, suffix) {
+				s.throttledPaths[suffix]--
+				throttle = true
+				break
+			}
+		}
+	}
+	if throttle {
+		s.throttled++
+		if s.throttleAfter != "" {
+			w.Header().Set("Retry-After", s.throttleAfter)
+		}
+		writeGraphError(w, http.StatusTooManyRequests, "TooManyRequests", "too many requests")
+		return
+	}
+
+	for suffix, statusCode := range s.failures {
+		if strings.HasSuffix(r.URL.Path, suffix) {
+			writeGraphError(w, statusCode, "InjectedFailure", "injected failure for "+suffix)
+			return
+		}
+	}
+
+	// Strip the version and the /me or /users/{id} prefix
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1.0"), "/"), "/")
+	switch {
+	case segments[0] == "me":
+		segments = segments[1:]
+	case segments[0] == "users" && len(segments) > 1:
+		if segments[1] != UserID {
+			writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "user not found")
+			return
+		}
+		segments = segments[2:]
+	default:
+		writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "unknown path")
+		return
+	}
+
+	if len(segments) == 0 {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":                UserID,
+			"displayName":       "Test User",
+			"userPrincipalName": UserPrincipalName,
+		})
+		return
+	}
+	if segments[0] != "onenote" {
+		writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "unknown path")
+		return
+	}
+	s.handleOneNote(w, r, segments[1:])
+}
+
+// handleOneNote serves the OneNote notebook, section, page and resource endpoints
+func (s *Server) handleOneNote(w http.ResponseWriter, r *http.Request, segments []string) {
+	path := strings.Join(segments, "/")
+
+	switch {
+	case path == "notebooks":
+		items := make([]interface{}, 0, len(s.notebooks))
+		for _, nb := range s.notebooks {
+			items = append(items, notebookJSON(nb))
+		}
+		s.writeCollection(w, r, items)
+
+	case path == "sections":
+		items := make([]interface{}, 0, len(s.sections))
+		for _, sec := range s.sections {
+			items = append(items, s.sectionJSON(sec))
+		}
+		s.writeCollection(w, r, items)
+
+	case len(segments) == 3 && segments[0] == "notebooks" && segments[2] == "sections":
+		items := make([]interface{}, 0)
+		for _, sec := range s.sections {
+			if sec.notebookID == segments[1] {
+				items = append(items, s.sectionJSON(sec))
+			}
+		}
+		s.writeCollection(w, r, items)
+
+	case len(segments) == 3 && segments[0] == "sections" && segments[2] == "pages":
+		items := make([]interface{}, 0)
+		for _, p := range s.pages {
+			if p.sectionID == segments[1] {
+				items = append(items, s.pageJSON(p))
+			}
+		}
+		s.writeCollection(w, r, items)
+
+	case len(segments) == 3 && segments[0] == "pages" && segments[2] == "content":
+		for _, p := range s.pages {
+			if p.id == segments[1] {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(p.content))
+				return
+			}
+		}
+		writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "page not found")
+
+	case len(segments) == 3 && segments[0] == "resources" && (segments[2] == "content" || segments[2] == "$value"):
+		res, ok := s.resources[segments[1]]
+		if !ok {
+			writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "resource not found")
+			return
+		}
+		w.Header().Set("Content-Type", res.contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(res.data)
+
+	default:
+		writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "unknown OneNote path")
+	}
+}
+
+// writeCollection writes one page of items, honouring $top and $skip and the configured
+// page size, with an absolute @odata.nextLink when more items remain
+func (s *Server) writeCollection(w http.ResponseWriter, r *http.Request, items []interface{}) {
+	query := r.URL.Query()
+	skip, _ := strconv.Atoi(query.Get("$skip"))
+	top, _ := strconv.Atoi(query.Get("$top"))
+
+	size := s.pageSize
+	if top > 0 && (size == 0 || top < size) {
+		size = top
+	}
+
+	if skip > len(items) {
+		skip = len(items)
+	}
+	end := len(items)
+	if size > 0 && skip+size < end {
+		end = skip + size
+	}
+
+	body := map[string]interface{}{"value": items[skip:end]}
+	if end < len(items) {
+		next := url.Values{}
+		next.Set("$skip", strconv.Itoa(end))
+		if top > 0 {
+			next.Set("$top", strconv.Itoa(top))
+		}
+		body["@odata.nextLink"] = s.server.URL + r.URL.Path + "?" + next.Encode()
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func notebookJSON(nb *notebook) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              nb.id,
+		"displayName":     nb.name,
+		"createdDateTime": nb.created.Format(time.RFC3339),
+	}
+}
+
+func (s *Server) sectionJSON(sec *section) map[string]interface{} {
+	body := map[string]interface{}{
+		"id":              sec.id,
+		"displayName":     sec.name,
+		"createdDateTime": sec.created.Format(time.RFC3339),
+	}
+	for _, nb := range s.notebooks {
+		if nb.id == sec.notebookID {
+			body["parentNotebook"] = map[string]interface{}{"id": nb.id, "displayName": nb.name}
+		}
+	}
+	return body
+}
+
+func (s *Server) pageJSON(p *page) map[string]interface{} {
+	body := map[string]interface{}{
+		"id":                   p.id,
+		"title":                p.title,
+		"createdDateTime":      p.created.Format(time.RFC3339Nano),
+		"lastModifiedDateTime": p.modified.Format(time.RFC3339Nano),
+		"contentUrl":           fmt.Sprintf("%s/users/%s/onenote/pages/%s/content", s.GraphURL(), UserID, p.id),
+	}
+	for _, sec := range s.sections {
+		if sec.id == p.sectionID {
+			body["parentSection"] = map[string]interface{}{"id": sec.id, "displayName": sec.name}
+		}
+	}
+	return body
+}
+
+// handleLogin serves the identity platform token and OpenID configuration endpoints of any tenant
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 {
+		http.NotFound(w, r)
+		return
+	}
+	tenant := segments[0]
+	rest := strings.Join(segments[1:], "/")
+
+	switch rest {
+	case "v2.0/.well-known/openid-configuration":
+		base := s.server.URL + "/" + tenant
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                 base + "/v2.0",
+			"authorization_endpoint": base + "/oauth2/v2.0/authorize",
+			"token_endpoint":         base + "/oauth2/v2.0/token",
+		})
+	case "oauth2/v2.0/token":
+		s.handleToken(w, r)
+	case "oauth2/v2.0/devicecode":
+		s.handleDeviceCode(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleDeviceCode starts a device authorization with DeviceCode and UserCode
+func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeTokenError(w, http.StatusMethodNotAllowed, "invalid_request", "device code requests must use POST")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("client_id") != ClientID {
+		writeTokenError(w, http.StatusBadRequest, "unauthorized_client", "unknown client")
+		return
+	}
+
+	verificationURI := s.server.URL + "/devicelogin"
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"device_code":      DeviceCode,
+		"user_code":        UserCode,
+		"verification_uri": verificationURI,
+		"expires_in":       900,
+		"interval":         1,
+		"message":          fmt.Sprintf("To sign in, use a web browser to open the page %s and enter the code %s to authenticate.", verificationURI, UserCode),
+	})
+}
+
+// handleToken issues tokens for the client_credentials, authorization_code, device_code and refresh_token grants
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeTokenError(w, http.StatusMethodNotAllowed, "invalid_request", "token requests must use POST")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("client_id") != ClientID || !validClientCredential(r.PostForm) {
+		writeTokenError(w, http.StatusUnauthorized, "invalid_client", "invalid client credentials")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	withRefreshToken := true
+	switch r.PostForm.Get("grant_type") {
+	case "client_credentials":
+		withRefreshToken = false
+	case "authorization_code":
+		if r.PostForm.Get("code") != AuthorizationCode {
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant", "invalid authorization code")
+			return
+		}
+		if s.codeChallenge != "" {
+			sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if base64.RawURLEncoding.EncodeToString(sum[:]) != s.codeChallenge {
+				writeTokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match code_challenge")
+				return
+			}
+		}
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		switch {
+		case r.PostForm.Get("device_code") != DeviceCode:
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant", "invalid device code")
+			return
+		case s.deviceDeclined:
+			writeTokenError(w, http.StatusBadRequest, "authorization_declined", "the user declined the authorization")
+			return
+		case s.devicePending > 0:
+			s.devicePending--
+			writeTokenError(w, http.StatusBadRequest, "authorization_pending", "the user has not signed in yet")
+			return
+		}
+	case "refresh_token":
+		refreshToken := r.PostForm.Get("refresh_token")
+		if !s.refreshTokens[refreshToken] {
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant", "invalid refresh token")
+			return
+		}
+		// Refresh tokens are single use, as the identity platform rotates them
+		delete(s.refreshTokens, refreshToken)
+	default:
+		writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type", "unsupported grant type")
+		return
+	}
+
+	s.tokenCount++
+	accessToken := fmt.Sprintf("access-token-%d", s.tokenCount)
+	s.accessTokens[accessToken] = true
+
+	body := map[string]interface{}{
+		"token_type":     "Bearer",
+		"scope":          r.PostForm.Get("scope"),
+		"expires_in":     3600,
+		"ext_expires_in": 3600,
+		"access_token":   accessToken,
+	}
+	if withRefreshToken {
+		refreshToken := fmt.Sprintf("refresh-token-%d", s.tokenCount)
+		s.refreshTokens[refreshToken] = true
+		body["refresh_token"] = refreshToken
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// validClientCredential reports whether a token request carries the client secret or the
+// federated client assertion
+func validClientCredential(form url.Values) bool {
+	if form.Get("client_assertion_type") != "" {
+		return form.Get("client_assertion_type") == "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" &&
+			form.Get("client_assertion") == ClientAssertion
+	}
+	return form.Get("client_secret") == ClientSecret
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeGraphError(w http.ResponseWriter, statusCode int, code, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"error": map[string]interface{}{"code": code, "message": message},
+	})
+}
+
+func writeTokenError(w http.ResponseWriter, statusCode int, code, description string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"error":             code,
+		"error_description": description,
+	})
+}
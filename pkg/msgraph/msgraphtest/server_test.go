@@ -0,0 +1,286 @@
+package msgraphtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// get sends an authenticated Graph request and decodes a JSON response into body
+func get(t *testing.T, rawURL, token string, body interface{}) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if body != nil {
+		if err := json.NewDecoder(resp.Body).Decode(body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return resp
+}
+
+type collection struct {
+	Value []struct {
+		ID             string `json:"id"`
+		ParentNotebook struct {
+			ID string `json:"id"`
+		} `json:"parentNotebook"`
+	} `json:"value"`
+	NextLink string `json:"@odata.nextLink"`
+}
+
+// TestServerPagination tests that collections are split across nextLink pages
+func TestServerPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.AddNotebook("nb-1", "Work")
+	for _, id := range []string{"sec-1", "sec-2", "sec-3"} {
+		server.AddSection("nb-1", id, id)
+	}
+	server.SetPageSize(2)
+
+	var ids []string
+	next := server.GraphURL() + "/me/onenote/sections"
+	requests := 0
+	for next != "" {
+		var page collection
+		resp := get(t, next, AccessToken, &page)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		for _, item := range page.Value {
+			ids = append(ids, item.ID)
+			if item.ParentNotebook.ID != "nb-1" {
+				t.Errorf("Expected parent notebook nb-1 on %s, got %q", item.ID, item.ParentNotebook.ID)
+			}
+		}
+		next = page.NextLink
+		requests++
+	}
+
+	if strings.Join(ids, ",") != "sec-1,sec-2,sec-3" || requests != 2 {
+		t.Errorf("Expected 3 sections over 2 pages, got %v over %d", ids, requests)
+	}
+
+	// $top smaller than the page size wins
+	var page collection
+	get(t, server.GraphURL()+"/users/"+UserID+"/onenote/sections?%24top=1", AccessToken, &page)
+	if len(page.Value) != 1 || !strings.Contains(page.NextLink, "%24top=1") {
+		t.Errorf("Expected 1 section and a nextLink keeping $top, got %d and %q", len(page.Value), page.NextLink)
+	}
+}
+
+// TestServerPageContent tests serving page content and resources
+func TestServerPageContent(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.AddNotebook("nb-1", "Work")
+	server.AddSection("nb-1", "sec-1", "Meetings")
+	server.AddPage("sec-1", "page-1", "Standup", "<html><body><p>Notes</p></body></html>")
+	server.AddResource("res-1", "text/plain", []byte("attachment"))
+
+	resp := get(t, server.GraphURL()+"/me/onenote/pages/page-1/content", AccessToken, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/html" {
+		t.Errorf("Expected HTML page content, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	resp = get(t, server.ResourceURL("res-1"), AccessToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected resource to be served, got %d", resp.StatusCode)
+	}
+
+	resp = get(t, server.GraphURL()+"/me/onenote/pages/missing/content", AccessToken, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing page, got %d", resp.StatusCode)
+	}
+}
+
+// TestServerThrottleAndFailures tests injected 429, 401 and failure responses
+func TestServerThrottleAndFailures(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Throttle(1, "2")
+	resp := get(t, server.GraphURL()+"/me", AccessToken, nil)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("Expected 429 with Retry-After 2, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	if resp := get(t, server.GraphURL()+"/me", AccessToken, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 once throttling ends, got %d", resp.StatusCode)
+	}
+	if server.ThrottledCount() != 1 {
+		t.Errorf("Expected 1 throttled request, got %d", server.ThrottledCount())
+	}
+
+	server.AddNotebook("nb-1", "Work")
+	server.AddNotebook("nb-2", "Personal")
+	server.ThrottleNextPage("/notebooks", 1, "")
+	if resp := get(t, server.GraphURL()+"/me/onenote/notebooks?$top=1", AccessToken, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the first page not to be throttled, got %d", resp.StatusCode)
+	}
+	if resp := get(t, server.GraphURL()+"/me/onenote/notebooks?$top=1&$skip=1", AccessToken, nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected the next page to be throttled, got %d", resp.StatusCode)
+	}
+	if resp := get(t, server.GraphURL()+"/me/onenote/notebooks?$top=1&$skip=1", AccessToken, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 once next page throttling ends, got %d", resp.StatusCode)
+	}
+	if server.ThrottledCount() != 2 {
+		t.Errorf("Expected 2 throttled requests, got %d", server.ThrottledCount())
+	}
+
+	server.Fail("/notebooks", http.StatusServiceUnavailable)
+	if resp := get(t, server.GraphURL()+"/me/onenote/notebooks", AccessToken, nil); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected injected 503, got %d", resp.StatusCode)
+	}
+
+	server.ExpireToken(AccessToken)
+	if resp := get(t, server.GraphURL()+"/me", AccessToken, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an expired token, got %d", resp.StatusCode)
+	}
+}
+
+// TestServerTokenEndpoint tests the authorization code and refresh token grants
+func TestServerTokenEndpoint(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	tokenURL := server.LoginURL() + "/common/oauth2/v2.0/token"
+	requestToken := func(form url.Values) (int, map[string]interface{}) {
+		form.Set("client_id", ClientID)
+		form.Set("client_secret", ClientSecret)
+		resp, err := http.PostForm(tokenURL, form)
+		if err != nil {
+			t.Fatalf("Token request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		body := map[string]interface{}{}
+		_ = json.Unmarshal(data, &body)
+		return resp.StatusCode, body
+	}
+
+	status, body := requestToken(url.Values{"grant_type": {"authorization_code"}, "code": {AuthorizationCode}})
+	if status != http.StatusOK || body["access_token"] == nil || body["refresh_token"] == nil {
+		t.Fatalf("Expected tokens for a valid code, got %d %v", status, body)
+	}
+	accessToken := body["access_token"].(string)
+	refreshToken := body["refresh_token"].(string)
+
+	if resp := get(t, server.GraphURL()+"/me", accessToken, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected issued token to be accepted, got %d", resp.StatusCode)
+	}
+
+	status, body = requestToken(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	if status != http.StatusOK || body["access_token"] == accessToken {
+		t.Errorf("Expected a new access token on refresh, got %d %v", status, body)
+	}
+
+	// Refresh tokens are rotated
+	if status, _ := requestToken(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}); status != http.StatusBadRequest {
+		t.Errorf("Expected a reused refresh token to be rejected, got %d", status)
+	}
+	if status, _ := requestToken(url.Values{"grant_type": {"authorization_code"}, "code": {"wrong"}}); status != http.StatusBadRequest {
+		t.Errorf("Expected an invalid code to be rejected, got %d", status)
+	}
+
+	status, body = requestToken(url.Values{"grant_type": {"client_credentials"}})
+	if status != http.StatusOK || body["refresh_token"] != nil {
+		t.Errorf("Expected an access token without refresh token, got %d %v", status, body)
+	}
+
+	// RFC 7636 appendix B verifier and challenge
+	server.RequireCodeChallenge("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+	if status, _ := requestToken(url.Values{"grant_type": {"authorization_code"}, "code": {AuthorizationCode}}); status != http.StatusBadRequest {
+		t.Errorf("Expected a code without verifier to be rejected, got %d", status)
+	}
+	status, _ = requestToken(url.Values{"grant_type": {"authorization_code"}, "code": {AuthorizationCode}, "code_verifier": {"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"}})
+	if status != http.StatusOK {
+		t.Errorf("Expected a matching verifier to be accepted, got %d", status)
+	}
+}
+
+// TestServerClientAssertion tests authenticating token requests with a client assertion
+func TestServerClientAssertion(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	tokenURL := server.LoginURL() + "/common/oauth2/v2.0/token"
+	for assertion, expected := range map[string]int{ClientAssertion: http.StatusOK, "forged": http.StatusUnauthorized} {
+		resp, err := http.PostForm(tokenURL, url.Values{
+			"client_id":             {ClientID},
+			"grant_type":            {"client_credentials"},
+			"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+			"client_assertion":      {assertion},
+		})
+		if err != nil {
+			t.Fatalf("Token request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("Expected %d for assertion %q, got %d", expected, assertion, resp.StatusCode)
+		}
+	}
+}
+
+// TestServerDeviceCode tests the device authorization endpoint and device code grant
+func TestServerDeviceCode(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	resp, err := http.PostForm(server.LoginURL()+"/common/oauth2/v2.0/devicecode", url.Values{"client_id": {ClientID}, "scope": {"User.Read"}})
+	if err != nil {
+		t.Fatalf("Device code request failed: %v", err)
+	}
+	var deviceCode map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&deviceCode)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || deviceCode["device_code"] != DeviceCode || deviceCode["user_code"] != UserCode {
+		t.Fatalf("Unexpected device code response: %d %v", resp.StatusCode, deviceCode)
+	}
+
+	poll := func() (int, string) {
+		form := url.Values{
+			"client_id":     {ClientID},
+			"client_secret": {ClientSecret},
+			"grant_type":    {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code":   {DeviceCode},
+		}
+		resp, err := http.PostForm(server.LoginURL()+"/common/oauth2/v2.0/token", form)
+		if err != nil {
+			t.Fatalf("Token request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		errorCode, _ := body["error"].(string)
+		return resp.StatusCode, errorCode
+	}
+
+	server.SetDeviceCodePending(1)
+	if status, errorCode := poll(); status != http.StatusBadRequest || errorCode != "authorization_pending" {
+		t.Errorf("Expected authorization_pending, got %d %s", status, errorCode)
+	}
+	if status, _ := poll(); status != http.StatusOK {
+		t.Errorf("Expected tokens once signed in, got %d", status)
+	}
+
+	server.DeclineDeviceCode()
+	if status, errorCode := poll(); status != http.StatusBadRequest || errorCode != "authorization_declined" {
+		t.Errorf("Expected authorization_declined, got %d %s", status, errorCode)
+	}
+}
@@ -1,6 +1,7 @@
 package msgraph
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -13,6 +14,13 @@ import (
 	"time"
 )
 
+// oauthRequestTimeout bounds each request to the identity platform, so a stalled token
+// endpoint cannot hang sign-in
+const oauthRequestTimeout = 30 * time.Second
+
+// oauthHTTPClient makes the token and device code requests
+var oauthHTTPClient = &http.Client{Timeout: oauthRequestTimeout}
+
 // OAuthConfig represents OAuth configuration for Microsoft Graph
 type OAuthConfig struct {
 	ClientID     string
@@ -20,6 +28,14 @@ type OAuthConfig struct {
 	TenantID     string // Use "common" for personal accounts, specific tenant ID for work/school accounts
 	RedirectURI  string
 	Scopes       []string
+	// Client assertion credentials used instead of the client secret on token requests
+	CertificatePath     string // PEM or PKCS#12 (PFX) file with certificate and RSA private key
+	CertificatePassword string // Password of an encrypted certificate file
+	FederatedTokenFile  string // Federated workload identity token file
+	// Identity platform host (defaults to DefaultLoginEndpoint; Config.OAuthConfig sets the cloud's)
+	LoginEndpoint string
+	// Graph API base URL used to test tokens (defaults to DefaultGraphEndpoint)
+	GraphEndpoint string
 }
 
 // NewPersonalAccountOAuthConfig creates OAuth config for personal Microsoft accounts
@@ -59,6 +75,35 @@ func (c OAuthConfig) IsPersonalAccountConfig() bool {
 	return c.TenantID == "common" || c.TenantID == ""
 }
 
+// tenant returns the tenant used in identity platform URLs.
+// "common" allows both personal and work/school accounts.
+func (c OAuthConfig) tenant() string {
+	if c.TenantID == "" {
+		return "common"
+	}
+	return c.TenantID
+}
+
+// endpointURL returns the URL of an OAuth 2.0 endpoint ("authorize", "token" or "devicecode") of the tenant
+func (c OAuthConfig) endpointURL(endpoint string) string {
+	loginEndpoint := c.LoginEndpoint
+	if loginEndpoint == "" {
+		loginEndpoint = DefaultLoginEndpoint
+	}
+	return fmt.Sprintf("%s/%s/oauth2/v2.0/%s", strings.TrimSuffix(loginEndpoint, "/"), c.tenant(), endpoint)
+}
+
+// withEndpoints returns oauthConfig with unset endpoints taken from the client's cloud
+func (c *Client) withEndpoints(oauthConfig OAuthConfig) OAuthConfig {
+	if oauthConfig.LoginEndpoint == "" {
+		oauthConfig.LoginEndpoint = c.loginEndpoint
+	}
+	if oauthConfig.GraphEndpoint == "" {
+		oauthConfig.GraphEndpoint = c.graphEndpoint
+	}
+	return oauthConfig
+}
+
 // TokenResponse represents the response from the token endpoint
 type TokenResponse struct {
 	TokenType    string `json:"token_type"`
@@ -71,6 +116,12 @@ type TokenResponse struct {
 
 // AuthorizationURL generates the authorization URL for OAuth 2.0 flow
 func (c *Client) GenerateAuthorizationURL(oauthConfig OAuthConfig, state string) (string, error) {
+	return c.GenerateAuthorizationURLWithPKCE(oauthConfig, state, "")
+}
+
+// GenerateAuthorizationURLWithPKCE generates the authorization URL with an S256 PKCE code
+// challenge (RFC 7636). An empty codeChallenge omits PKCE.
+func (c *Client) GenerateAuthorizationURLWithPKCE(oauthConfig OAuthConfig, state, codeChallenge string) (string, error) {
 	if oauthConfig.ClientID == "" || oauthConfig.RedirectURI == "" {
 		return "", errors.New("client_id and redirect_uri are required")
 	}
@@ -81,15 +132,8 @@ func (c *Client) GenerateAuthorizationURL(oauthConfig OAuthConfig, state string)
 		scopes = []string{"offline_access", "User.Read", "Mail.Read"}
 	}
 
-	// Use "common" as tenant if not specified or if explicitly set to "common"
-	// This allows both personal and work/school accounts
-	tenant := oauthConfig.TenantID
-	if tenant == "" || tenant == "common" {
-		tenant = "common"
-	}
-
 	// Build authorization URL
-	baseURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/authorize", tenant)
+	baseURL := c.withEndpoints(oauthConfig).endpointURL("authorize")
 
 	params := url.Values{}
 	params.Set("client_id", oauthConfig.ClientID)
@@ -98,24 +142,33 @@ func (c *Client) GenerateAuthorizationURL(oauthConfig OAuthConfig, state string)
 	params.Set("response_mode", "query")
 	params.Set("scope", strings.Join(scopes, " "))
 	params.Set("state", state)
+	if codeChallenge != "" {
+		params.Set("code_challenge", codeChallenge)
+		params.Set("code_challenge_method", "S256")
+	}
 
 	return fmt.Sprintf("%s?%s", baseURL, params.Encode()), nil
 }
 
 // ExchangeCodeForToken exchanges authorization code for access token
 func (c *Client) ExchangeCodeForToken(oauthConfig OAuthConfig, code string) (*TokenResponse, error) {
+	return c.ExchangeCodeForTokenWithPKCE(oauthConfig, code, "")
+}
+
+// ExchangeCodeForTokenWithPKCE exchanges an authorization code requested with a PKCE code
+// challenge, proving possession with its code verifier. An empty codeVerifier omits PKCE.
+func (c *Client) ExchangeCodeForTokenWithPKCE(oauthConfig OAuthConfig, code, codeVerifier string) (*TokenResponse, error) {
+	return c.ExchangeCodeForTokenWithContext(context.Background(), oauthConfig, code, codeVerifier)
+}
+
+// ExchangeCodeForTokenWithContext exchanges an authorization code like ExchangeCodeForTokenWithPKCE,
+// giving up when ctx is done
+func (c *Client) ExchangeCodeForTokenWithContext(ctx context.Context, oauthConfig OAuthConfig, code, codeVerifier string) (*TokenResponse, error) {
 	if code == "" {
 		return nil, errors.New("authorization code is required")
 	}
 
-	// Use "common" as tenant if not specified or if explicitly set to "common"
-	// This allows both personal and work/school accounts
-	tenant := oauthConfig.TenantID
-	if tenant == "" || tenant == "common" {
-		tenant = "common"
-	}
-
-	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenant)
+	tokenURL := c.withEndpoints(oauthConfig).endpointURL("token")
 
 	// Set default scopes if not provided
 	scopes := oauthConfig.Scopes
@@ -130,10 +183,15 @@ func (c *Client) ExchangeCodeForToken(oauthConfig OAuthConfig, code string) (*To
 	data.Set("code", code)
 	data.Set("redirect_uri", oauthConfig.RedirectURI)
 	data.Set("grant_type", "authorization_code")
-	data.Set("client_secret", oauthConfig.ClientSecret)
+	if err := oauthConfig.setClientCredential(data, tokenURL); err != nil {
+		return nil, err
+	}
+	if codeVerifier != "" {
+		data.Set("code_verifier", codeVerifier)
+	}
 
 	// Make POST request
-	resp, err := http.PostForm(tokenURL, data)
+	resp, err := postForm(ctx, tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make token request: %w", err)
 	}
@@ -161,18 +219,16 @@ func (c *Client) ExchangeCodeForToken(oauthConfig OAuthConfig, code string) (*To
 
 // RefreshToken refreshes an expired access token using refresh token
 func (c *Client) RefreshToken(oauthConfig OAuthConfig, refreshToken string) (*TokenResponse, error) {
+	return c.RefreshTokenWithContext(context.Background(), oauthConfig, refreshToken)
+}
+
+// RefreshTokenWithContext refreshes an expired access token like RefreshToken, giving up when ctx is done
+func (c *Client) RefreshTokenWithContext(ctx context.Context, oauthConfig OAuthConfig, refreshToken string) (*TokenResponse, error) {
 	if refreshToken == "" {
 		return nil, errors.New("refresh token is required")
 	}
 
-	// Use "common" as tenant if not specified or if explicitly set to "common"
-	// This allows both personal and work/school accounts
-	tenant := oauthConfig.TenantID
-	if tenant == "" || tenant == "common" {
-		tenant = "common"
-	}
-
-	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenant)
+	tokenURL := c.withEndpoints(oauthConfig).endpointURL("token")
 
 	// Set default scopes if not provided
 	scopes := oauthConfig.Scopes
@@ -186,10 +242,12 @@ func (c *Client) RefreshToken(oauthConfig OAuthConfig, refreshToken string) (*To
 	data.Set("scope", strings.Join(scopes, " "))
 	data.Set("refresh_token", refreshToken)
 	data.Set("grant_type", "refresh_token")
-	data.Set("client_secret", oauthConfig.ClientSecret)
+	if err := oauthConfig.setClientCredential(data, tokenURL); err != nil {
+		return nil, err
+	}
 
 	// Make POST request
-	resp, err := http.PostForm(tokenURL, data)
+	resp, err := postForm(ctx, tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make refresh token request: %w", err)
 	}
@@ -215,16 +273,58 @@ func (c *Client) RefreshToken(oauthConfig OAuthConfig, refreshToken string) (*To
 	return &tokenResponse, nil
 }
 
+// postForm posts a form to an identity platform endpoint, giving up when ctx is done or the
+// request timeout passes
+func postForm(ctx context.Context, endpoint string, data url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return oauthHTTPClient.Do(req)
+}
+
 // TestAccessToken tests if an access token is valid by making a request to Microsoft Graph
 func (c *Client) TestAccessToken(accessToken string) error {
+	_, err := c.getSignedInUser(accessToken)
+	return err
+}
+
+// GetSignedInUserID returns the Graph user ID of the user an access token was issued to
+func (c *Client) GetSignedInUserID(accessToken string) (string, error) {
+	body, err := c.getSignedInUser(accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return "", fmt.Errorf("failed to parse signed-in user: %w", err)
+	}
+	if user.ID == "" {
+		return "", errors.New("signed-in user has no ID")
+	}
+	return user.ID, nil
+}
+
+// getSignedInUser requests the signed-in user from Microsoft Graph with an access token
+// and returns the response body
+func (c *Client) getSignedInUser(accessToken string) ([]byte, error) {
 	if accessToken == "" {
-		return errors.New("access token is required")
+		return nil, errors.New("access token is required")
 	}
 
-	// Create a simple GET request to test the token
-	req, err := http.NewRequest("GET", "https://graph.microsoft.com/v1.0/me", nil)
+	graphEndpoint := c.graphEndpoint
+	if graphEndpoint == "" {
+		graphEndpoint = DefaultGraphEndpoint
+	}
+
+	// Request the signed-in user to test the token
+	req, err := http.NewRequest("GET", strings.TrimSuffix(graphEndpoint, "/")+"/me", nil)
 	if err != nil {
-		return fmt.Errorf("failed to create test request: %w", err)
+		return nil, fmt.Errorf("failed to create test request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+accessToken)
@@ -232,16 +332,20 @@ func (c *Client) TestAccessToken(accessToken string) error {
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to test access token: %w", err)
+		return nil, fmt.Errorf("failed to test access token: %w", err)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("access token test failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("access token test failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	return body, nil
 }
 
 // GenerateStateParameter generates a cryptographically secure random state parameter for OAuth
@@ -272,20 +376,49 @@ func (oc *OAuthClient) GetAuthorizationURL(state string) (string, error) {
 	return client.GenerateAuthorizationURL(oc.config, state)
 }
 
+// GetAuthorizationURLWithPKCE generates authorization URL with an S256 PKCE code challenge
+func (oc *OAuthClient) GetAuthorizationURLWithPKCE(state, codeChallenge string) (string, error) {
+	client := &Client{} // Create temporary client for method access
+	return client.GenerateAuthorizationURLWithPKCE(oc.config, state, codeChallenge)
+}
+
 // ExchangeCode exchanges authorization code for tokens
 func (oc *OAuthClient) ExchangeCode(code string) (*TokenResponse, error) {
 	client := &Client{} // Create temporary client for method access
 	return client.ExchangeCodeForToken(oc.config, code)
 }
 
+// ExchangeCodeWithPKCE exchanges authorization code for tokens, sending the PKCE code verifier
+func (oc *OAuthClient) ExchangeCodeWithPKCE(code, codeVerifier string) (*TokenResponse, error) {
+	return oc.ExchangeCodeContext(context.Background(), code, codeVerifier)
+}
+
+// ExchangeCodeContext exchanges authorization code for tokens, sending the PKCE code verifier
+// if set and giving up when ctx is done
+func (oc *OAuthClient) ExchangeCodeContext(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	client := &Client{} // Create temporary client for method access
+	return client.ExchangeCodeForTokenWithContext(ctx, oc.config, code, codeVerifier)
+}
+
 // RefreshAccessToken refreshes access token using refresh token
 func (oc *OAuthClient) RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
+	return oc.RefreshAccessTokenContext(context.Background(), refreshToken)
+}
+
+// RefreshAccessTokenContext refreshes access token using refresh token, giving up when ctx is done
+func (oc *OAuthClient) RefreshAccessTokenContext(ctx context.Context, refreshToken string) (*TokenResponse, error) {
 	client := &Client{} // Create temporary client for method access
-	return client.RefreshToken(oc.config, refreshToken)
+	return client.RefreshTokenWithContext(ctx, oc.config, refreshToken)
 }
 
 // TestToken tests if access token is valid
 func (oc *OAuthClient) TestToken(accessToken string) error {
-	client := &Client{} // Create temporary client for method access
+	client := &Client{graphEndpoint: oc.config.GraphEndpoint} // Create temporary client for method access
 	return client.TestAccessToken(accessToken)
 }
+
+// GetUserID returns the Graph user ID of the user an access token was issued to
+func (oc *OAuthClient) GetUserID(accessToken string) (string, error) {
+	client := &Client{graphEndpoint: oc.config.GraphEndpoint} // Create temporary client for method access
+	return client.GetSignedInUserID(accessToken)
+}
@@ -0,0 +1,203 @@
+package msgraph
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidOAuthState is returned for a state that was never issued, has expired, was already
+// used or belongs to another session
+var ErrInvalidOAuthState = errors.New("invalid or expired OAuth state")
+
+// DefaultOAuthStateTTL is how long an issued state may be used to complete sign-in
+const DefaultOAuthStateTTL = 10 * time.Minute
+
+// OAuthStateStore persists issued OAuth states until they are used or expire
+type OAuthStateStore interface {
+	// SaveState stores the record of a newly issued state
+	SaveState(ctx context.Context, state string, record []byte, expiresAt time.Time) error
+	// ConsumeState removes a state and returns its record, or nil when the state is unknown.
+	// A state must only be returned once, even to concurrent callers.
+	ConsumeState(ctx context.Context, state string) ([]byte, error)
+}
+
+// AuthorizationRequest is a newly issued state with its PKCE code verifier and challenge
+type AuthorizationRequest struct {
+	State         string
+	CodeVerifier  string
+	CodeChallenge string
+}
+
+// oauthStateRecord is what is stored for an issued state
+type oauthStateRecord struct {
+	SessionHash  string    `json:"session_hash"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// OAuthStateManager issues single-use OAuth states bound to the session that started sign-in,
+// each with a PKCE code verifier
+type OAuthStateManager struct {
+	store OAuthStateStore
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewOAuthStateManager creates a state manager persisting states in store (nil keeps them in memory)
+func NewOAuthStateManager(store OAuthStateStore) *OAuthStateManager {
+	if store == nil {
+		store = NewMemoryOAuthStateStore()
+	}
+	return &OAuthStateManager{
+		store: store,
+		ttl:   DefaultOAuthStateTTL,
+		now:   time.Now,
+	}
+}
+
+// TTL returns how long an issued state stays valid
+func (m *OAuthStateManager) TTL() time.Duration {
+	return m.ttl
+}
+
+// Issue creates a state and PKCE code verifier for the session starting sign-in
+func (m *OAuthStateManager) Issue(ctx context.Context, sessionID string) (*AuthorizationRequest, error) {
+	if sessionID == "" {
+		return nil, errors.New("session ID is required")
+	}
+
+	state, err := GenerateStateParameter()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := m.now().Add(m.ttl)
+	record, err := json.Marshal(oauthStateRecord{
+		SessionHash:  hashSessionID(sessionID),
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OAuth state: %w", err)
+	}
+	if err := m.store.SaveState(ctx, state, record, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to store OAuth state: %w", err)
+	}
+
+	return &AuthorizationRequest{
+		State:         state,
+		CodeVerifier:  codeVerifier,
+		CodeChallenge: CodeChallengeS256(codeVerifier),
+	}, nil
+}
+
+// Validate consumes a state returned to the callback and returns its PKCE code verifier.
+// The state can only be used once, before it expires, by the session it was issued to.
+func (m *OAuthStateManager) Validate(ctx context.Context, state, sessionID string) (string, error) {
+	if state == "" || sessionID == "" {
+		return "", ErrInvalidOAuthState
+	}
+
+	data, err := m.store.ConsumeState(ctx, state)
+	if err != nil {
+		return "", fmt.Errorf("failed to load OAuth state: %w", err)
+	}
+	if data == nil {
+		return "", ErrInvalidOAuthState
+	}
+
+	var record oauthStateRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return "", fmt.Errorf("failed to decode OAuth state: %w", err)
+	}
+	if !m.now().Before(record.ExpiresAt) {
+		return "", ErrInvalidOAuthState
+	}
+	if subtle.ConstantTimeCompare([]byte(record.SessionHash), []byte(hashSessionID(sessionID))) != 1 {
+		return "", ErrInvalidOAuthState
+	}
+	return record.CodeVerifier, nil
+}
+
+// hashSessionID keeps session IDs out of the state store
+func hashSessionID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateCodeVerifier generates a random PKCE code verifier of 43 URL-safe characters
+func GenerateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallengeS256 derives the S256 PKCE code challenge of a code verifier
+func CodeChallengeS256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// memoryOAuthState is an issued state held by MemoryOAuthStateStore
+type memoryOAuthState struct {
+	record    []byte
+	expiresAt time.Time
+}
+
+// MemoryOAuthStateStore keeps issued states in memory for the lifetime of the process
+type MemoryOAuthStateStore struct {
+	mu     sync.Mutex
+	states map[string]memoryOAuthState
+	now    func() time.Time
+}
+
+// NewMemoryOAuthStateStore creates an empty in-memory state store
+func NewMemoryOAuthStateStore() *MemoryOAuthStateStore {
+	return &MemoryOAuthStateStore{
+		states: make(map[string]memoryOAuthState),
+		now:    time.Now,
+	}
+}
+
+// SaveState stores an issued state, dropping states that expired without being used
+func (s *MemoryOAuthStateStore) SaveState(ctx context.Context, state string, record []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, stored := range s.states {
+		if !now.Before(stored.expiresAt) {
+			delete(s.states, key)
+		}
+	}
+	s.states[state] = memoryOAuthState{record: append([]byte(nil), record...), expiresAt: expiresAt}
+	return nil
+}
+
+// ConsumeState removes a state and returns its record
+func (s *MemoryOAuthStateStore) ConsumeState(ctx context.Context, state string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.states[state]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.states, state)
+	return stored.record, nil
+}
@@ -0,0 +1,76 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestOAuthStateManager tests that states are single use and bound to their session
+func TestOAuthStateManager(t *testing.T) {
+	manager := NewOAuthStateManager(nil)
+	ctx := context.Background()
+
+	request, err := manager.Issue(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if request.CodeChallenge != CodeChallengeS256(request.CodeVerifier) {
+		t.Error("Expected the code challenge to derive from the code verifier")
+	}
+
+	if _, err := manager.Validate(ctx, "unknown-state", "session-1"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Expected an unknown state to be rejected, got %v", err)
+	}
+
+	codeVerifier, err := manager.Validate(ctx, request.State, "session-1")
+	if err != nil || codeVerifier != request.CodeVerifier {
+		t.Errorf("Expected the code verifier of the state, got %q (%v)", codeVerifier, err)
+	}
+
+	if _, err := manager.Validate(ctx, request.State, "session-1"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Expected a reused state to be rejected, got %v", err)
+	}
+
+	// A state returned to another session is rejected and can no longer be used
+	other, _ := manager.Issue(ctx, "session-1")
+	if _, err := manager.Validate(ctx, other.State, "session-2"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Expected a state of another session to be rejected, got %v", err)
+	}
+	if _, err := manager.Validate(ctx, other.State, "session-1"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Expected a state presented by another session to be consumed, got %v", err)
+	}
+}
+
+// TestOAuthStateManagerExpiry tests that expired states are rejected
+func TestOAuthStateManagerExpiry(t *testing.T) {
+	manager := NewOAuthStateManager(nil)
+	ctx := context.Background()
+
+	request, err := manager.Issue(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	manager.now = func() time.Time { return time.Now().Add(DefaultOAuthStateTTL) }
+	if _, err := manager.Validate(ctx, request.State, "session-1"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Expected an expired state to be rejected, got %v", err)
+	}
+}
+
+// TestCodeChallengeS256 tests the S256 transform with the example of RFC 7636 appendix B
+func TestCodeChallengeS256(t *testing.T) {
+	challenge := CodeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if challenge != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("Unexpected code challenge: %s", challenge)
+	}
+
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Errorf("Expected a verifier of 43 to 128 characters, got %d", len(verifier))
+	}
+}
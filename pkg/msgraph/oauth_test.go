@@ -79,6 +79,33 @@ func TestGenerateAuthorizationURL_DefaultScopes(t *testing.T) {
 	}
 }
 
+func TestGenerateAuthorizationURLWithPKCE(t *testing.T) {
+	client := &Client{}
+	config := OAuthConfig{
+		ClientID:    "test-client-id",
+		TenantID:    "test-tenant-id",
+		RedirectURI: "https://localhost/callback",
+	}
+
+	authURL, err := client.GenerateAuthorizationURLWithPKCE(config, "test-state", "test-challenge")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	parsedURL, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Failed to parse authorization URL: %v", err)
+	}
+
+	params := parsedURL.Query()
+	if params.Get("code_challenge") != "test-challenge" {
+		t.Errorf("Expected code_challenge test-challenge, got %s", params.Get("code_challenge"))
+	}
+	if params.Get("code_challenge_method") != "S256" {
+		t.Errorf("Expected code_challenge_method S256, got %s", params.Get("code_challenge_method"))
+	}
+}
+
 func TestGenerateAuthorizationURL_MissingConfig(t *testing.T) {
 	client := &Client{}
 
@@ -2,7 +2,6 @@ package msgraph
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"log"
 	"sync"
@@ -12,6 +11,7 @@ import (
 
 	"github.com/ishank09/data-extraction-service/internal/types"
 	"github.com/ishank09/data-extraction-service/internal/utils"
+	"github.com/ishank09/data-extraction-service/pkg/static"
 )
 
 // OneNoteRawData represents raw data fetched from OneNote API
@@ -20,8 +20,19 @@ type OneNoteRawData struct {
 	Sections  map[string][]msgraphmodels.OnenoteSectionable
 	Pages     map[string][]msgraphmodels.OnenotePageable
 	Content   map[string][]byte
+	Resources map[string][]ResourceContent // Downloaded attachments keyed by page ID
+	// ListingComplete is true when every section and page was listed, so pages missing
+	// from Pages no longer exist
+	ListingComplete bool
+	ListedSections  map[string]bool // Every listed section, including those excluded by the filter
+	SkippedPages    map[string]bool // Listed pages excluded by the page title filter
+	Errors          []types.Issue   // Items that could not be listed or downloaded
+	Warnings        []types.Issue   // Items that were skipped
 }
 
+// pageFilter selects the pages of a section whose content is downloaded
+type pageFilter func(sectionID string, page msgraphmodels.OnenotePageable) bool
+
 // SectionJob represents a section processing job
 type SectionJob struct {
 	NotebookID    string
@@ -33,7 +44,8 @@ type SectionJob struct {
 // SectionResult represents the result of section processing
 type SectionResult struct {
 	SectionID string
-	Pages     []msgraphmodels.OnenotePageable
+	Pages     []msgraphmodels.OnenotePageable // Pages listed, including those listed before an error
+	Truncated bool                            // Pages were dropped because of the per-run item limit
 	Error     error
 }
 
@@ -69,16 +81,25 @@ func (c *Client) GetOneNoteDataAsJSON(ctx context.Context) (*types.DocumentColle
 // combineOneNoteData orchestrates the data fetching and combines it into a DocumentCollection
 // This layer handles the business logic of how OneNote data should be processed and combined
 func (c *Client) combineOneNoteData(ctx context.Context) (*types.DocumentCollection, error) {
-	// Create document collection
-	collection := types.NewDocumentCollection("OneNote")
-
 	// Fetch raw OneNote data using concurrent implementation
 	rawData, err := c.fetchOneNoteRawDataConcurrent(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch OneNote data: %w", err)
 	}
 
-	// Process and combine the raw data into documents
+	return c.buildOneNoteCollection(ctx, rawData), nil
+}
+
+// buildOneNoteCollection converts the pages with downloaded content into a DocumentCollection
+func (c *Client) buildOneNoteCollection(ctx context.Context, rawData *OneNoteRawData) *types.DocumentCollection {
+	// Create document collection, carrying over the items that failed while fetching
+	collection := types.NewDocumentCollection("OneNote")
+	collection.Errors = append(collection.Errors, rawData.Errors...)
+	collection.Warnings = append(collection.Warnings, rawData.Warnings...)
+
+	// Process and combine the raw data into documents, adding attachment documents after all pages
+	staticClient := static.NewClient()
+	var children []types.Document
 	for _, notebook := range rawData.Notebooks {
 		notebookID := getStringValue(notebook.GetId())
 		sections, exists := rawData.Sections[notebookID]
@@ -97,9 +118,10 @@ func (c *Client) combineOneNoteData(ctx context.Context) (*types.DocumentCollect
 
 			for _, page := range pages {
 				pageID := getStringValue(page.GetId())
+				// Pages without content were skipped as unchanged or failed to download,
+				// which the content workers already logged
 				content, exists := rawData.Content[pageID]
 				if !exists {
-					log.Printf("No content found for page %s", pageID)
 					continue
 				}
 
@@ -107,16 +129,37 @@ func (c *Client) combineOneNoteData(ctx context.Context) (*types.DocumentCollect
 				doc, err := c.processPageContent(page, notebook, section, content)
 				if err != nil {
 					log.Printf("Error processing page %s: %v", pageID, err)
+					collection.AddError(newIssue("page", pageID, stageProcessPage, err))
 					continue
 				}
 
+				// Convert downloaded attachments into child documents of the page
+				var childIDs []string
+				for _, resource := range rawData.Resources[pageID] {
+					child, err := c.processResourceContent(ctx, staticClient, page, notebook, section, resource)
+					if err != nil {
+						log.Printf("Error processing attachment %s on page %s: %v", resource.Resource.Name, pageID, err)
+						collection.AddError(newIssue("attachment", resource.Resource.ResourceID, stageProcessAttachment, err))
+						continue
+					}
+					childIDs = append(childIDs, child.ID)
+					children = append(children, child)
+				}
+				if len(childIDs) > 0 {
+					doc.Metadata["attachment_document_ids"] = childIDs
+				}
+
 				// Add document to collection
 				collection.AddDocument(doc)
 			}
 		}
 	}
 
-	return collection, nil
+	for _, child := range children {
+		collection.AddDocument(child)
+	}
+
+	return collection
 }
 
 // processPageContent converts a OneNote page and its content into a Document
@@ -133,14 +176,16 @@ func (c *Client) processPageContent(page msgraphmodels.OnenotePageable, notebook
 		textContent = jsonContent
 	}
 
+	// Extract OneNote-specific structure: to-dos, tags, tables, outlines and embedded resources
+	pageContent := utils.ParseOneNotePage(string(content))
+
 	// Create document location (was OriginalPath)
 	location := fmt.Sprintf("OneNote/%s/%s",
 		getStringValue(notebook.GetDisplayName()),
 		getStringValue(section.GetDisplayName()))
 
 	// Create version hash
-	hash := sha256.Sum256([]byte(textContent))
-	versionHash := fmt.Sprintf("sha256:%x", hash)
+	versionHash := utils.GenerateVersionHash([]byte(textContent))
 
 	// Create metadata with OneNote-specific information
 	metadata := map[string]interface{}{
@@ -151,6 +196,14 @@ func (c *Client) processPageContent(page msgraphmodels.OnenotePageable, notebook
 		"page_id":         getStringValue(page.GetId()),
 		"content_format":  "html",
 		"has_images":      contentJSON["has_images"],
+		"has_tables":      contentJSON["has_tables"],
+		"links":           contentJSON["links"],
+		"todos":           pageContent.ToDos,
+		"tags":            pageContent.Tags,
+		"tables":          pageContent.Tables,
+		"outlines":        pageContent.Outlines,
+		"images":          pageContent.Images,
+		"attachments":     pageContent.Attachments,
 		"word_count":      contentJSON["word_count"],
 		"character_count": contentJSON["character_count"],
 	}
@@ -184,71 +237,112 @@ func (c *Client) fetchOneNoteRawDataConcurrent(ctx context.Context) (*OneNoteRaw
 
 // fetchOneNoteRawDataConcurrentWithConfig fetches OneNote data with custom concurrency configuration
 func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, config ConcurrencyConfig) (*OneNoteRawData, error) {
+	return c.fetchOneNoteRawDataFiltered(ctx, config, nil)
+}
+
+// fetchOneNoteRawDataFiltered lists the notebooks, sections and pages allowed by the crawl filter
+// but only downloads the content and attachments of pages accepted by shouldFetch (all pages when
+// it is nil)
+func (c *Client) fetchOneNoteRawDataFiltered(ctx context.Context, config ConcurrencyConfig, shouldFetch pageFilter) (*OneNoteRawData, error) {
 	log.Printf("🚀 Starting concurrent OneNote data fetching process...")
-	log.Printf("⚙️  Concurrency config: %d section workers, %d content workers", config.MaxSectionWorkers, config.MaxContentWorkers)
+	log.Printf("⚙️  Concurrency config: %d section workers, %d content workers, %d resource workers", config.MaxSectionWorkers, config.MaxContentWorkers, config.MaxResourceWorkers)
+	log.Printf("⚙️  Paging config: %d items per page, %d max items per run", c.pagination.PageSize, c.pagination.MaxItems)
 
 	rawData := &OneNoteRawData{
-		Sections: make(map[string][]msgraphmodels.OnenoteSectionable),
-		Pages:    make(map[string][]msgraphmodels.OnenotePageable),
-		Content:  make(map[string][]byte),
+		Sections:  make(map[string][]msgraphmodels.OnenoteSectionable),
+		Pages:     make(map[string][]msgraphmodels.OnenotePageable),
+		Content:   make(map[string][]byte),
+		Resources: make(map[string][]ResourceContent),
+
+		ListedSections: make(map[string]bool),
+		SkippedPages:   make(map[string]bool),
 	}
 
 	// Use mutex to protect shared data structures
 	var dataMutex sync.RWMutex
 
-	// Step 1: Fetch all notebooks (sequential as it's typically few items)
-	log.Printf("🔍 Fetching OneNote notebooks...")
-	var notebooks msgraphmodels.NotebookCollectionResponseable
-	var err error
+	// Notebooks, sections and pages are each capped at MaxItems per run. The listing is
+	// only complete when nothing was dropped by a cap or lost to a failed request.
+	truncated := false
+	listingFailed := false
 
-	if c.IsDelegatedAuth() {
-		notebooks, err = c.graphClient.Me().Onenote().Notebooks().Get(ctx, nil)
-	} else {
-		userID := c.GetUserID()
-		if userID == "" {
-			return nil, fmt.Errorf("user ID is required for application authentication flow")
-		}
-		notebooks, err = c.graphClient.Users().ByUserId(userID).Onenote().Notebooks().Get(ctx, nil)
-	}
+	// Worker pools shrink their effective concurrency when Graph throttles requests
+	sectionLimiter := newAdaptiveLimiter("section", config.MaxSectionWorkers)
+	contentLimiter := newAdaptiveLimiter("content", config.MaxContentWorkers)
 
+	// Step 1: Fetch all notebooks (sequential as it's typically few items)
+	log.Printf("🔍 Fetching OneNote notebooks...")
+	notebookBudget := newItemBudget(c.pagination.MaxItems)
+	notebooks, err := c.listNotebooks(ctx, notebookBudget)
+	truncated = truncated || notebookBudget.Exhausted()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch notebooks: %w", err)
+		if len(notebooks) == 0 {
+			return nil, fmt.Errorf("failed to fetch notebooks: %w", err)
+		}
+		log.Printf("❌ Failed to fetch all notebooks, continuing with %d: %v", len(notebooks), err)
+		rawData.Errors = append(rawData.Errors, newIssue("notebook", "", stageListNotebooks, err))
+		listingFailed = true
 	}
 
-	if notebooks == nil || notebooks.GetValue() == nil {
+	if len(notebooks) == 0 {
 		log.Printf("⚠️  No notebooks found")
+		rawData.ListingComplete = !truncated
 		return rawData, nil
 	}
 
-	rawData.Notebooks = notebooks.GetValue()
-	log.Printf("✅ Found %d notebooks", len(rawData.Notebooks))
+	log.Printf("✅ Found %d notebooks", len(notebooks))
 
-	// Step 2: Fetch all sections (sequential as it's a single API call)
-	log.Printf("🔍 Fetching OneNote sections...")
-	var allSections msgraphmodels.OnenoteSectionCollectionResponseable
-	if c.IsDelegatedAuth() {
-		allSections, err = c.graphClient.Me().Onenote().Sections().Get(ctx, nil)
-	} else {
-		userID := c.GetUserID()
-		allSections, err = c.graphClient.Users().ByUserId(userID).Onenote().Sections().Get(ctx, nil)
+	// Notebooks, sections and pages excluded by the filter are dropped before any jobs are
+	// scheduled, so they cost no further API calls
+	filter := c.filterFor(ctx)
+	allowedNotebooks := make(map[string]bool)
+	for _, notebook := range notebooks {
+		notebookID := getStringValue(notebook.GetId())
+		if !filter.notebookAllowed(notebookID, getStringValue(notebook.GetDisplayName())) {
+			continue
+		}
+		allowedNotebooks[notebookID] = true
+		rawData.Notebooks = append(rawData.Notebooks, notebook)
+	}
+
+	// Sections are still listed when no notebook matches, so that sync can tell the sections
+	// excluded by the filter from deleted ones
+	if len(rawData.Notebooks) == 0 {
+		log.Printf("⚠️  No notebooks match the filter")
+	}
+	if skipped := len(notebooks) - len(rawData.Notebooks); skipped > 0 {
+		log.Printf("🔎 Filter skipped %d notebooks", skipped)
 	}
 
+	// Step 2: Fetch all sections (sequential, following pagination)
+	log.Printf("🔍 Fetching OneNote sections...")
+	sectionBudget := newItemBudget(c.pagination.MaxItems)
+	allSections, err := c.listSections(ctx, sectionBudget)
+	truncated = truncated || sectionBudget.Exhausted()
 	if err != nil {
-		log.Printf("❌ Failed to fetch sections: %v", err)
-		return rawData, nil
+		log.Printf("❌ Failed to fetch sections, continuing with %d: %v", len(allSections), err)
+		rawData.Errors = append(rawData.Errors, newIssue("section", "", stageListSections, err))
+		listingFailed = true
 	}
 
-	if allSections == nil || allSections.GetValue() == nil {
+	if len(allSections) == 0 {
 		log.Printf("⚠️  No sections found")
+		rawData.ListingComplete = !truncated && !listingFailed
 		return rawData, nil
 	}
 
 	// Group sections by notebook ID
-	for _, section := range allSections.GetValue() {
+	skippedSections := 0
+	for _, section := range allSections {
+		rawData.ListedSections[getStringValue(section.GetId())] = true
 		parentNotebook := section.GetParentNotebook()
 		if parentNotebook != nil {
 			notebookID := getStringValue(parentNotebook.GetId())
 			if notebookID != "" {
+				if !allowedNotebooks[notebookID] || !filter.sectionAllowed(section) {
+					skippedSections++
+					continue
+				}
 				if rawData.Sections[notebookID] == nil {
 					rawData.Sections[notebookID] = []msgraphmodels.OnenoteSectionable{}
 				}
@@ -257,14 +351,19 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 		}
 	}
 
-	log.Printf("✅ Found %d sections grouped by notebook", len(allSections.GetValue()))
+	log.Printf("✅ Found %d sections grouped by notebook", len(allSections))
+	if skippedSections > 0 {
+		log.Printf("🔎 Filter skipped %d sections", skippedSections)
+	}
 
 	// Step 3: Concurrent page fetching for each section
 	log.Printf("🔍 Starting concurrent page fetching for sections...")
 
-	// Collect all section jobs
+	// Collect all section jobs in listing order, which also decides the pages kept by the cap
 	var sectionJobs []SectionJob
-	for notebookID, sections := range rawData.Sections {
+	for _, notebook := range rawData.Notebooks {
+		notebookID := getStringValue(notebook.GetId())
+		sections := rawData.Sections[notebookID]
 		for i, section := range sections {
 			sectionJobs = append(sectionJobs, SectionJob{
 				NotebookID:    notebookID,
@@ -277,6 +376,7 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 
 	if len(sectionJobs) == 0 {
 		log.Printf("⚠️  No sections to process")
+		rawData.ListingComplete = !truncated && !listingFailed
 		return rawData, nil
 	}
 
@@ -288,7 +388,7 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	var sectionWG sync.WaitGroup
 	for i := 0; i < config.MaxSectionWorkers; i++ {
 		sectionWG.Add(1)
-		go c.sectionWorker(ctx, &sectionWG, sectionJobChan, sectionResultChan)
+		go c.sectionWorker(ctx, &sectionWG, sectionLimiter, sectionJobChan, sectionResultChan)
 	}
 
 	// Send section jobs
@@ -303,25 +403,68 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 		close(sectionResultChan)
 	}()
 
-	// Collect section results
+	// Collect section results, keeping the pages listed before a section failed
 	var sectionErrors []error
-	totalPages := 0
 	for result := range sectionResultChan {
 		if result.Error != nil {
 			sectionErrors = append(sectionErrors, result.Error)
+			rawData.Errors = append(rawData.Errors, newIssue("section", result.SectionID, stageListPages, result.Error))
 			log.Printf("❌ Section processing error: %v", result.Error)
-			continue
+			if len(result.Pages) == 0 {
+				continue
+			}
+		}
+		truncated = truncated || result.Truncated
+
+		pages := result.Pages
+		var skippedPages []string
+		if filter != nil && filter.pageTitle != nil {
+			pages = make([]msgraphmodels.OnenotePageable, 0, len(result.Pages))
+			for _, page := range result.Pages {
+				if filter.pageAllowed(page) {
+					pages = append(pages, page)
+				} else {
+					skippedPages = append(skippedPages, getStringValue(page.GetId()))
+				}
+			}
 		}
 
 		dataMutex.Lock()
-		rawData.Pages[result.SectionID] = result.Pages
+		rawData.Pages[result.SectionID] = pages
+		for _, pageID := range skippedPages {
+			rawData.SkippedPages[pageID] = true
+		}
 		dataMutex.Unlock()
 
-		totalPages += len(result.Pages)
-		log.Printf("✅ Section %s: Found %d pages", result.SectionID, len(result.Pages))
+		log.Printf("✅ Section %s: Found %d pages", result.SectionID, len(pages))
+	}
+
+	// Apply the page cap across sections in listing order
+	sectionIDs := make([]string, 0, len(sectionJobs))
+	for _, job := range sectionJobs {
+		sectionIDs = append(sectionIDs, getStringValue(job.Section.GetId()))
+	}
+	dataMutex.Lock()
+	if capSectionPages(sectionIDs, rawData.Pages, c.pagination.MaxItems) {
+		truncated = true
+	}
+	totalPages := 0
+	for _, pages := range rawData.Pages {
+		totalPages += len(pages)
 	}
+	dataMutex.Unlock()
 
 	log.Printf("📊 Concurrent page fetching completed: %d total pages found", totalPages)
+	if truncated {
+		log.Printf("⚠️  Listing stopped at the limit of %d items per run", c.pagination.MaxItems)
+		rawData.Errors = append(rawData.Errors, types.Issue{
+			ItemType:   "listing",
+			Stage:      stageListPages,
+			ErrorClass: errorClassLimit,
+			Message:    fmt.Sprintf("listing stopped at the limit of %d items per run", c.pagination.MaxItems),
+		})
+	}
+	rawData.ListingComplete = len(sectionErrors) == 0 && !truncated && !listingFailed
 
 	// Step 4: Concurrent content fetching for all pages
 	log.Printf("🔍 Starting concurrent content fetching for pages...")
@@ -330,10 +473,13 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	var contentJobs []ContentJob
 	pageIndex := 0
 	dataMutex.RLock()
-	for _, pages := range rawData.Pages {
+	for sectionID, pages := range rawData.Pages {
 		for _, page := range pages {
 			pageID := getStringValue(page.GetId())
 			pageTitle := getStringValue(page.GetTitle())
+			if shouldFetch != nil && !shouldFetch(sectionID, page) {
+				continue
+			}
 			if pageID != "" {
 				contentJobs = append(contentJobs, ContentJob{
 					PageID:     pageID,
@@ -360,7 +506,7 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	var contentWG sync.WaitGroup
 	for i := 0; i < config.MaxContentWorkers; i++ {
 		contentWG.Add(1)
-		go c.contentWorker(ctx, &contentWG, contentJobChan, contentResultChan)
+		go c.contentWorker(ctx, &contentWG, contentLimiter, contentJobChan, contentResultChan)
 	}
 
 	// Send content jobs
@@ -381,6 +527,7 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	for result := range contentResultChan {
 		if result.Error != nil {
 			contentErrors = append(contentErrors, result.Error)
+			rawData.Errors = append(rawData.Errors, newIssue("page", result.PageID, stageFetchContent, result.Error))
 			log.Printf("❌ Content fetching error for page %s: %v", result.PageID, result.Error)
 			continue
 		}
@@ -394,6 +541,9 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 
 	log.Printf("📊 Concurrent content fetching completed: %d/%d pages successful", successfulContent, len(contentJobs))
 
+	// Step 5: Concurrent attachment downloads for all fetched pages
+	resourceErrors := c.fetchPageResources(ctx, config, rawData)
+
 	// Log any errors but don't fail the entire operation
 	if len(sectionErrors) > 0 {
 		log.Printf("⚠️  Section errors encountered: %d", len(sectionErrors))
@@ -401,6 +551,9 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	if len(contentErrors) > 0 {
 		log.Printf("⚠️  Content errors encountered: %d", len(contentErrors))
 	}
+	if len(resourceErrors) > 0 {
+		log.Printf("⚠️  Attachment errors encountered: %d", len(resourceErrors))
+	}
 
 	// Final summary
 	dataMutex.RLock()
@@ -408,6 +561,10 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	totalSectionsFound := 0
 	totalPagesFound := 0
 	totalContentFound := len(rawData.Content)
+	totalResourcesFound := 0
+	for _, resources := range rawData.Resources {
+		totalResourcesFound += len(resources)
+	}
 
 	for _, sections := range rawData.Sections {
 		totalSectionsFound += len(sections)
@@ -423,19 +580,26 @@ func (c *Client) fetchOneNoteRawDataConcurrentWithConfig(ctx context.Context, co
 	log.Printf("  📂 Sections: %d", totalSectionsFound)
 	log.Printf("  📄 Pages: %d", totalPagesFound)
 	log.Printf("  📝 Content retrieved: %d", totalContentFound)
-	log.Printf("  ⚡ Performance: Used %d section workers, %d content workers", config.MaxSectionWorkers, config.MaxContentWorkers)
+	log.Printf("  📎 Attachments downloaded: %d", totalResourcesFound)
+	log.Printf("  ⚡ Performance: Used %d section workers, %d content workers, %d resource workers", config.MaxSectionWorkers, config.MaxContentWorkers, config.MaxResourceWorkers)
+	if limit := sectionLimiter.currentLimit(); limit < config.MaxSectionWorkers {
+		log.Printf("  🐢 Throttling reduced section requests to %d concurrent", limit)
+	}
+	if limit := contentLimiter.currentLimit(); limit < config.MaxContentWorkers {
+		log.Printf("  🐢 Throttling reduced content requests to %d concurrent", limit)
+	}
 
 	return rawData, nil
 }
 
 // sectionWorker processes section jobs concurrently
-func (c *Client) sectionWorker(ctx context.Context, wg *sync.WaitGroup, jobs <-chan SectionJob, results chan<- SectionResult) {
+func (c *Client) sectionWorker(ctx context.Context, wg *sync.WaitGroup, limiter *adaptiveLimiter, jobs <-chan SectionJob, results chan<- SectionResult) {
 	defer wg.Done()
 
 	for job := range jobs {
 		select {
 		case <-ctx.Done():
-			results <- SectionResult{Error: ctx.Err()}
+			results <- SectionResult{SectionID: getStringValue(job.Section.GetId()), Error: ctx.Err()}
 			return
 		default:
 		}
@@ -453,45 +617,36 @@ func (c *Client) sectionWorker(ctx context.Context, wg *sync.WaitGroup, jobs <-c
 
 		log.Printf("  🔍 Worker fetching pages for section '%s' (ID: %s)...", sectionName, sectionID)
 
-		var pages msgraphmodels.OnenotePageCollectionResponseable
-		var err error
-
-		if c.IsDelegatedAuth() {
-			pages, err = c.graphClient.Me().Onenote().Sections().ByOnenoteSectionId(sectionID).Pages().Get(ctx, nil)
-		} else {
-			userID := c.GetUserID()
-			pages, err = c.graphClient.Users().ByUserId(userID).Onenote().Sections().ByOnenoteSectionId(sectionID).Pages().Get(ctx, nil)
-		}
-
+		// No section can contribute more than MaxItems pages to the capped total
+		budget := newItemBudget(c.pagination.MaxItems)
+		pageList, err := c.listSectionPages(ctx, sectionID, budget, limiter)
 		if err != nil {
 			results <- SectionResult{
 				SectionID: sectionID,
+				Pages:     pageList,
+				Truncated: budget.Exhausted(),
 				Error:     fmt.Errorf("failed to fetch pages for section %s: %w", sectionName, err),
 			}
 			continue
 		}
 
-		var pageList []msgraphmodels.OnenotePageable
-		if pages != nil && pages.GetValue() != nil {
-			pageList = pages.GetValue()
-		}
-
 		results <- SectionResult{
 			SectionID: sectionID,
 			Pages:     pageList,
+			Truncated: budget.Exhausted(),
 			Error:     nil,
 		}
 	}
 }
 
 // contentWorker processes content jobs concurrently
-func (c *Client) contentWorker(ctx context.Context, wg *sync.WaitGroup, jobs <-chan ContentJob, results chan<- ContentResult) {
+func (c *Client) contentWorker(ctx context.Context, wg *sync.WaitGroup, limiter *adaptiveLimiter, jobs <-chan ContentJob, results chan<- ContentResult) {
 	defer wg.Done()
 
 	for job := range jobs {
 		select {
 		case <-ctx.Done():
-			results <- ContentResult{Error: ctx.Err()}
+			results <- ContentResult{PageID: job.PageID, Error: ctx.Err()}
 			return
 		default:
 		}
@@ -499,14 +654,16 @@ func (c *Client) contentWorker(ctx context.Context, wg *sync.WaitGroup, jobs <-c
 		log.Printf("  🔍 Worker fetching content for page '%s' (ID: %s)...", job.PageTitle, job.PageID)
 
 		var content []byte
-		var err error
-
-		if c.IsDelegatedAuth() {
-			content, err = c.graphClient.Me().Onenote().Pages().ByOnenotePageId(job.PageID).Content().Get(ctx, nil)
-		} else {
-			userID := c.GetUserID()
-			content, err = c.graphClient.Users().ByUserId(userID).Onenote().Pages().ByOnenotePageId(job.PageID).Content().Get(ctx, nil)
-		}
+		err := c.withRetry(ctx, limiter, "page_content", func() error {
+			var err error
+			if c.IsDelegatedAuth() {
+				content, err = c.graphClient.Me().Onenote().Pages().ByOnenotePageId(job.PageID).Content().Get(ctx, nil)
+			} else {
+				userID := c.GetUserID()
+				content, err = c.graphClient.Users().ByUserId(userID).Onenote().Pages().ByOnenotePageId(job.PageID).Content().Get(ctx, nil)
+			}
+			return err
+		})
 
 		if err != nil {
 			results <- ContentResult{
@@ -542,29 +699,17 @@ func (c *Client) fetchOneNoteRawData(ctx context.Context) (*OneNoteRawData, erro
 
 	// Step 1: Fetch all notebooks using flattened endpoint
 	log.Printf("🔍 Fetching OneNote notebooks...")
-	var notebooks msgraphmodels.NotebookCollectionResponseable
-	var err error
-
-	if c.IsDelegatedAuth() {
-		notebooks, err = c.graphClient.Me().Onenote().Notebooks().Get(ctx, nil)
-	} else {
-		userID := c.GetUserID()
-		if userID == "" {
-			return nil, fmt.Errorf("user ID is required for application authentication flow")
-		}
-		notebooks, err = c.graphClient.Users().ByUserId(userID).Onenote().Notebooks().Get(ctx, nil)
-	}
-
+	notebooks, err := c.listNotebooks(ctx, newItemBudget(c.pagination.MaxItems))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch notebooks: %w", err)
 	}
 
-	if notebooks == nil || notebooks.GetValue() == nil {
+	if len(notebooks) == 0 {
 		log.Printf("⚠️  No notebooks found")
 		return rawData, nil // No notebooks found
 	}
 
-	rawData.Notebooks = notebooks.GetValue()
+	rawData.Notebooks = notebooks
 	log.Printf("✅ Found %d notebooks", len(rawData.Notebooks))
 
 	for i, notebook := range rawData.Notebooks {
@@ -575,21 +720,15 @@ func (c *Client) fetchOneNoteRawData(ctx context.Context) (*OneNoteRawData, erro
 
 	// Step 2: Fetch all sections using flattened endpoint (instead of hierarchical)
 	log.Printf("🔍 Fetching OneNote sections...")
-	var allSections msgraphmodels.OnenoteSectionCollectionResponseable
-	if c.IsDelegatedAuth() {
-		allSections, err = c.graphClient.Me().Onenote().Sections().Get(ctx, nil)
-	} else {
-		userID := c.GetUserID()
-		allSections, err = c.graphClient.Users().ByUserId(userID).Onenote().Sections().Get(ctx, nil)
-	}
+	allSections, err := c.listSections(ctx, newItemBudget(c.pagination.MaxItems))
 
 	if err != nil {
 		log.Printf("❌ Failed to fetch sections: %v", err)
-	} else if allSections != nil && allSections.GetValue() != nil {
-		log.Printf("✅ Found %d sections", len(allSections.GetValue()))
+	} else if len(allSections) > 0 {
+		log.Printf("✅ Found %d sections", len(allSections))
 
 		// Group sections by notebook ID
-		for i, section := range allSections.GetValue() {
+		for i, section := range allSections {
 			sectionName := getStringValue(section.GetDisplayName())
 			sectionID := getStringValue(section.GetId())
 
@@ -640,26 +779,19 @@ func (c *Client) fetchOneNoteRawData(ctx context.Context) (*OneNoteRawData, erro
 			log.Printf("  🔍 Section %d/%d: Fetching pages for '%s' (ID: %s)...", sectionIndex+1, len(sections), sectionName, sectionID)
 			totalSections++
 
-			var pages msgraphmodels.OnenotePageCollectionResponseable
-			if c.IsDelegatedAuth() {
-				pages, err = c.graphClient.Me().Onenote().Sections().ByOnenoteSectionId(sectionID).Pages().Get(ctx, nil)
-			} else {
-				userID := c.GetUserID()
-				pages, err = c.graphClient.Users().ByUserId(userID).Onenote().Sections().ByOnenoteSectionId(sectionID).Pages().Get(ctx, nil)
-			}
-
+			pages, err := c.listSectionPages(ctx, sectionID, newItemBudget(c.pagination.MaxItems), nil)
 			if err != nil {
 				log.Printf("  ❌ Failed to fetch pages for section '%s' (ID: %s): %v", sectionName, sectionID, err)
 				continue
 			}
 
-			if pages != nil && pages.GetValue() != nil {
-				pageCount := len(pages.GetValue())
+			if len(pages) > 0 {
+				pageCount := len(pages)
 				totalPages += pageCount
-				rawData.Pages[sectionID] = pages.GetValue()
+				rawData.Pages[sectionID] = pages
 				log.Printf("  ✅ Section '%s': Found %d pages", sectionName, pageCount)
 
-				for i, page := range pages.GetValue() {
+				for i, page := range pages {
 					pageTitle := getStringValue(page.GetTitle())
 					pageID := getStringValue(page.GetId())
 					log.Printf("    📄 Page %d: '%s' (ID: %s)", i+1, pageTitle, pageID)
@@ -723,6 +855,10 @@ func (c *Client) fetchOneNoteRawData(ctx context.Context) (*OneNoteRawData, erro
 	totalSectionsFound := 0
 	totalPagesFound := 0
 	totalContentFound := len(rawData.Content)
+	totalResourcesFound := 0
+	for _, resources := range rawData.Resources {
+		totalResourcesFound += len(resources)
+	}
 
 	for _, sections := range rawData.Sections {
 		totalSectionsFound += len(sections)
@@ -0,0 +1,269 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/internal/utils"
+	"github.com/ishank09/data-extraction-service/pkg/static"
+)
+
+// DefaultMaxAttachmentSize is the largest attachment downloaded when MaxAttachmentSize is not set
+const DefaultMaxAttachmentSize int64 = 25 << 20
+
+// errAttachmentTooLarge is returned for attachments larger than MaxAttachmentSize
+var errAttachmentTooLarge = errors.New("attachment too large")
+
+// ResourceContent is a downloaded OneNote page attachment
+type ResourceContent struct {
+	Resource utils.OneNoteResource
+	Content  []byte
+}
+
+// ResourceJob represents an attachment download job
+type ResourceJob struct {
+	PageID    string
+	PageTitle string
+	Resource  utils.OneNoteResource
+}
+
+// ResourceResult represents the result of an attachment download
+type ResourceResult struct {
+	PageID   string
+	Resource utils.OneNoteResource
+	Content  []byte
+	Error    error
+}
+
+// collectResourceJobs returns a download job for every attachment the static processors can extract text from
+func collectResourceJobs(rawData *OneNoteRawData, staticClient *static.Client) []ResourceJob {
+	titles := make(map[string]string)
+	for _, pages := range rawData.Pages {
+		for _, page := range pages {
+			titles[getStringValue(page.GetId())] = getStringValue(page.GetTitle())
+		}
+	}
+
+	var jobs []ResourceJob
+	for pageID, content := range rawData.Content {
+		for _, attachment := range utils.ParseOneNotePage(string(content)).Attachments {
+			if attachment.ResourceID == "" {
+				continue
+			}
+			if _, err := staticClient.DetectFileType(attachment.Name, attachment.ContentType); err != nil {
+				log.Printf("  ⏭️  Skipping unsupported attachment '%s' (%s) on page %s", attachment.Name, attachment.ContentType, pageID)
+				rawData.Warnings = append(rawData.Warnings, types.Issue{
+					ItemID:     attachment.ResourceID,
+					ItemType:   "attachment",
+					Stage:      stageFetchAttachment,
+					ErrorClass: errorClassUnsupported,
+					Message:    fmt.Sprintf("unsupported attachment %s (%s) on page %s", attachment.Name, attachment.ContentType, pageID),
+				})
+				continue
+			}
+
+			jobs = append(jobs, ResourceJob{
+				PageID:    pageID,
+				PageTitle: titles[pageID],
+				Resource:  attachment,
+			})
+		}
+	}
+
+	return jobs
+}
+
+// fetchPageResources downloads the supported attachments of every fetched page into rawData.Resources
+// using a worker pool sized by MaxResourceWorkers, records failed downloads in rawData.Errors and
+// returns the download errors encountered
+func (c *Client) fetchPageResources(ctx context.Context, config ConcurrencyConfig, rawData *OneNoteRawData) []error {
+	workers, maxSize := config.resourceLimits()
+	if workers == 0 {
+		log.Printf("⏭️  Attachment downloads disabled")
+		return nil
+	}
+
+	log.Printf("🔍 Starting concurrent attachment downloads for pages...")
+
+	resourceJobs := collectResourceJobs(rawData, static.NewClient())
+	if len(resourceJobs) == 0 {
+		log.Printf("⚠️  No supported attachments to download")
+		return nil
+	}
+
+	// Create channels for resource worker pool
+	resourceJobChan := make(chan ResourceJob, len(resourceJobs))
+	resourceResultChan := make(chan ResourceResult, len(resourceJobs))
+
+	// Start resource workers
+	limiter := newAdaptiveLimiter("resource", workers)
+	var resourceWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		resourceWG.Add(1)
+		go c.resourceWorker(ctx, &resourceWG, limiter, maxSize, resourceJobChan, resourceResultChan)
+	}
+
+	// Send resource jobs
+	for _, job := range resourceJobs {
+		resourceJobChan <- job
+	}
+	close(resourceJobChan)
+
+	// Wait for all resource workers to complete
+	go func() {
+		resourceWG.Wait()
+		close(resourceResultChan)
+	}()
+
+	// Collect resource results
+	var resourceErrors []error
+	successfulResources := 0
+	for result := range resourceResultChan {
+		if result.Error != nil {
+			resourceErrors = append(resourceErrors, result.Error)
+			rawData.Errors = append(rawData.Errors, newIssue("attachment", result.Resource.ResourceID, stageFetchAttachment, result.Error))
+			log.Printf("❌ Attachment download error for page %s: %v", result.PageID, result.Error)
+			continue
+		}
+
+		rawData.Resources[result.PageID] = append(rawData.Resources[result.PageID], ResourceContent{
+			Resource: result.Resource,
+			Content:  result.Content,
+		})
+		successfulResources++
+	}
+
+	log.Printf("📊 Concurrent attachment downloads completed: %d/%d attachments successful", successfulResources, len(resourceJobs))
+
+	return resourceErrors
+}
+
+// resourceWorker processes attachment download jobs concurrently, rejecting attachments larger than maxSize bytes
+func (c *Client) resourceWorker(ctx context.Context, wg *sync.WaitGroup, limiter *adaptiveLimiter, maxSize int64, jobs <-chan ResourceJob, results chan<- ResourceResult) {
+	defer wg.Done()
+
+	handler := abstractions.NewRequestHandlerOption()
+	handler.SetResponseHandler(attachmentResponseHandler(maxSize))
+	requestConfig := &users.ItemOnenoteResourcesItemContentRequestBuilderGetRequestConfiguration{
+		Options: []abstractions.RequestOption{handler},
+	}
+
+	for job := range jobs {
+		select {
+		case <-ctx.Done():
+			results <- ResourceResult{PageID: job.PageID, Resource: job.Resource, Error: ctx.Err()}
+			return
+		default:
+		}
+
+		log.Printf("  🔍 Worker downloading attachment '%s' for page '%s' (ID: %s)...", job.Resource.Name, job.PageTitle, job.PageID)
+
+		var content []byte
+		err := c.withRetry(ctx, limiter, "resource_content", func() error {
+			var err error
+			if c.IsDelegatedAuth() {
+				content, err = c.graphClient.Me().Onenote().Resources().ByOnenoteResourceId(job.Resource.ResourceID).Content().Get(ctx, requestConfig)
+			} else {
+				userID := c.GetUserID()
+				content, err = c.graphClient.Users().ByUserId(userID).Onenote().Resources().ByOnenoteResourceId(job.Resource.ResourceID).Content().Get(ctx, requestConfig)
+			}
+			return err
+		})
+
+		if err != nil {
+			results <- ResourceResult{
+				PageID:   job.PageID,
+				Resource: job.Resource,
+				Error:    fmt.Errorf("failed to download attachment %s: %w", job.Resource.Name, err),
+			}
+			continue
+		}
+
+		results <- ResourceResult{
+			PageID:   job.PageID,
+			Resource: job.Resource,
+			Content:  content,
+			Error:    nil,
+		}
+	}
+}
+
+// attachmentResponseHandler reads an attachment download, failing as soon as the body exceeds
+// maxSize bytes instead of buffering it whole. Error responses are returned as an ApiError with
+// the status code and headers so that throttled downloads are still retried.
+func attachmentResponseHandler(maxSize int64) abstractions.ResponseHandler {
+	return func(response interface{}, _ abstractions.ErrorMappings) (interface{}, error) {
+		resp, ok := response.(*http.Response)
+		if !ok || resp == nil {
+			return nil, fmt.Errorf("unexpected attachment response %T", response)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			apiErr := abstractions.NewApiError()
+			apiErr.Message = fmt.Sprintf("attachment download failed with status %d", resp.StatusCode)
+			apiErr.ResponseStatusCode = resp.StatusCode
+			apiErr.ResponseHeaders = abstractions.NewResponseHeaders()
+			for key, values := range resp.Header {
+				for _, value := range values {
+					apiErr.ResponseHeaders.Add(key, value)
+				}
+			}
+			return nil, apiErr
+		}
+
+		if resp.ContentLength > maxSize {
+			return nil, fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", errAttachmentTooLarge, resp.ContentLength, maxSize)
+		}
+		content, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment: %w", err)
+		}
+		if int64(len(content)) > maxSize {
+			return nil, fmt.Errorf("%w: exceeds the limit of %d bytes", errAttachmentTooLarge, maxSize)
+		}
+		return content, nil
+	}
+}
+
+// processResourceContent converts a downloaded attachment into a child document of its page
+// using the static file processors
+func (c *Client) processResourceContent(ctx context.Context, staticClient *static.Client, page msgraphmodels.OnenotePageable, notebook msgraphmodels.Notebookable, section msgraphmodels.OnenoteSectionable, resource ResourceContent) (types.Document, error) {
+	doc, err := staticClient.ProcessFile(ctx, resource.Resource.Name, resource.Resource.ContentType, resource.Content)
+	if err != nil {
+		return types.Document{}, fmt.Errorf("failed to process attachment %s: %w", resource.Resource.Name, err)
+	}
+
+	pageID := getStringValue(page.GetId())
+
+	doc.Source = "onenote"
+	doc.Location = fmt.Sprintf("OneNote/%s/%s/%s/%s",
+		getStringValue(notebook.GetDisplayName()),
+		getStringValue(section.GetDisplayName()),
+		getStringValue(page.GetTitle()),
+		resource.Resource.Name)
+	doc.ID = utils.GenerateDocumentID(doc.Type, doc.Source, pageID+"/"+resource.Resource.ResourceID)
+	doc.CreatedAt = getTimeValue(page.GetCreatedDateTime())
+	if doc.Metadata == nil {
+		doc.Metadata = map[string]interface{}{}
+	}
+	doc.Metadata["parent_page_id"] = pageID
+	doc.Metadata["parent_page_title"] = getStringValue(page.GetTitle())
+	doc.Metadata["notebook_id"] = getStringValue(notebook.GetId())
+	doc.Metadata["section_id"] = getStringValue(section.GetId())
+	doc.Metadata["resource_id"] = resource.Resource.ResourceID
+	doc.Metadata["resource_url"] = resource.Resource.URL
+	doc.Metadata["content_type"] = resource.Resource.ContentType
+
+	return *doc, nil
+}
@@ -0,0 +1,162 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/ishank09/data-extraction-service/internal/utils"
+	"github.com/ishank09/data-extraction-service/pkg/static"
+)
+
+// TestDefaultConcurrencyConfigResourceWorkers tests the default attachment worker pool size
+func TestDefaultConcurrencyConfigResourceWorkers(t *testing.T) {
+	if workers := DefaultConcurrencyConfig().MaxResourceWorkers; workers != 5 {
+		t.Errorf("Expected default MaxResourceWorkers to be 5, got %d", workers)
+	}
+}
+
+// TestCollectResourceJobs tests that only supported attachments are queued for download
+func TestCollectResourceJobs(t *testing.T) {
+	page := createMockPage("page-1", "Meeting", time.Now())
+	rawData := &OneNoteRawData{
+		Pages: map[string][]msgraphmodels.OnenotePageable{"section-1": {page}},
+		Content: map[string][]byte{
+			"page-1": []byte(`<html><body><div>` +
+				`<object data-attachment="notes.txt" type="text/plain" data="https://graph.microsoft.com/v1.0/me/onenote/resources/res-1/$value"></object>` +
+				`<object data-attachment="movie.mp4" type="video/mp4" data="https://graph.microsoft.com/v1.0/me/onenote/resources/res-2/$value"></object>` +
+				`</div></body></html>`),
+		},
+	}
+
+	jobs := collectResourceJobs(rawData, static.NewClient())
+	if len(jobs) != 1 {
+		t.Fatalf("Expected 1 resource job, got %d", len(jobs))
+	}
+	if jobs[0].PageID != "page-1" || jobs[0].PageTitle != "Meeting" || jobs[0].Resource.ResourceID != "res-1" {
+		t.Errorf("Unexpected resource job: %+v", jobs[0])
+	}
+}
+
+// TestConcurrencyConfigResourceLimits tests the defaults applied to unset resource limits
+func TestConcurrencyConfigResourceLimits(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          ConcurrencyConfig
+		expectedWorkers int
+		expectedSize    int64
+	}{
+		{name: "unset", config: ConcurrencyConfig{MaxSectionWorkers: 3, MaxContentWorkers: 6}, expectedWorkers: 5, expectedSize: DefaultMaxAttachmentSize},
+		{name: "configured", config: ConcurrencyConfig{MaxResourceWorkers: 2, MaxAttachmentSize: 1024}, expectedWorkers: 2, expectedSize: 1024},
+		{name: "disabled", config: ConcurrencyConfig{MaxResourceWorkers: -1}, expectedWorkers: 0, expectedSize: DefaultMaxAttachmentSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workers, maxSize := tt.config.resourceLimits()
+			if workers != tt.expectedWorkers || maxSize != tt.expectedSize {
+				t.Errorf("Expected %d workers and %d bytes, got %d and %d", tt.expectedWorkers, tt.expectedSize, workers, maxSize)
+			}
+		})
+	}
+}
+
+// TestFetchPageResourcesDisabled tests that no downloads happen with a negative worker count
+func TestFetchPageResourcesDisabled(t *testing.T) {
+	client := &Client{}
+	rawData := &OneNoteRawData{Resources: make(map[string][]ResourceContent)}
+
+	errs := client.fetchPageResources(context.Background(), ConcurrencyConfig{MaxResourceWorkers: -1}, rawData)
+	if len(errs) != 0 || len(rawData.Resources) != 0 {
+		t.Errorf("Expected no downloads, got errors %v and resources %v", errs, rawData.Resources)
+	}
+}
+
+// TestAttachmentResponseHandler tests that attachment downloads are capped and error responses stay retryable
+func TestAttachmentResponseHandler(t *testing.T) {
+	newResponse := func(status int, contentLength int64, body string) *http.Response {
+		header := http.Header{}
+		header.Set("Retry-After", "2")
+		return &http.Response{
+			StatusCode:    status,
+			Header:        header,
+			ContentLength: contentLength,
+			Body:          io.NopCloser(strings.NewReader(body)),
+		}
+	}
+	handler := attachmentResponseHandler(5)
+
+	content, err := handler(newResponse(http.StatusOK, 5, "hello"), nil)
+	if err != nil || string(content.([]byte)) != "hello" {
+		t.Errorf("Expected the attachment content, got %v and %v", content, err)
+	}
+
+	if _, err := handler(newResponse(http.StatusOK, 6, "hello!"), nil); !errors.Is(err, errAttachmentTooLarge) {
+		t.Errorf("Expected a too large error for the declared length, got %v", err)
+	}
+	if _, err := handler(newResponse(http.StatusOK, -1, "hello!"), nil); !errors.Is(err, errAttachmentTooLarge) {
+		t.Errorf("Expected a too large error for an unknown length, got %v", err)
+	}
+	if class, retryable := classifyError(errAttachmentTooLarge); class != errorClassLimit || retryable {
+		t.Errorf("Expected a non-retryable limit error, got %s (%t)", class, retryable)
+	}
+
+	_, err = handler(newResponse(http.StatusTooManyRequests, 0, ""), nil)
+	if throttled, retryAfter := throttleInfo(err); !throttled || retryAfter != 2*time.Second {
+		t.Errorf("Expected a throttling error with a 2s Retry-After, got %v", err)
+	}
+}
+
+// TestProcessResourceContent tests converting an attachment into a child document of its page
+func TestProcessResourceContent(t *testing.T) {
+	client := &Client{}
+
+	notebook := createMockNotebook("notebook-123", "Test Notebook")
+	section := createMockSection("section-456", "Test Section")
+	page := createMockPage("page-789", "Test Page", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	resource := ResourceContent{
+		Resource: utils.OneNoteResource{
+			Kind:        "attachment",
+			ResourceID:  "res-1",
+			URL:         "https://graph.microsoft.com/v1.0/me/onenote/resources/res-1/$value",
+			ContentType: "text/plain",
+			Name:        "notes.txt",
+		},
+		Content: []byte("Attachment text"),
+	}
+
+	doc, err := client.processResourceContent(context.Background(), static.NewClient(), page, notebook, section, resource)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if doc.Source != "onenote" {
+		t.Errorf("Expected source 'onenote', got '%s'", doc.Source)
+	}
+	if doc.Content != "Attachment text" {
+		t.Errorf("Expected attachment text as content, got '%s'", doc.Content)
+	}
+	if doc.Location != "OneNote/Test Notebook/Test Section/Test Page/notes.txt" {
+		t.Errorf("Unexpected location '%s'", doc.Location)
+	}
+	if doc.Metadata["parent_page_id"] != "page-789" {
+		t.Errorf("Expected parent_page_id 'page-789', got '%v'", doc.Metadata["parent_page_id"])
+	}
+	if doc.Metadata["resource_id"] != "res-1" {
+		t.Errorf("Expected resource_id 'res-1', got '%v'", doc.Metadata["resource_id"])
+	}
+
+	other, err := client.processResourceContent(context.Background(), static.NewClient(), page, notebook, section, resource)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if other.ID != doc.ID {
+		t.Errorf("Expected a stable document ID, got '%s' and '%s'", doc.ID, other.ID)
+	}
+}
@@ -0,0 +1,427 @@
+package msgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+)
+
+// SyncCheckpoint records what the last successful OneNote sync of a user saw
+type SyncCheckpoint struct {
+	UserKey    string                    `json:"user_key"`
+	LastSyncAt time.Time                 `json:"last_sync_at"`
+	Sections   map[string]time.Time      `json:"sections"` // Section ID to the latest page lastModifiedDateTime synced
+	Pages      map[string]PageCheckpoint `json:"pages"`    // Page ID to the page as last synced
+}
+
+// PageCheckpoint is a page as seen by the last successful sync
+type PageCheckpoint struct {
+	SectionID     string    `json:"section_id"`
+	LastModified  time.Time `json:"last_modified"`
+	AttachmentIDs []string  `json:"attachment_document_ids,omitempty"`
+}
+
+// newSyncCheckpoint creates an empty checkpoint for a user
+func newSyncCheckpoint(userKey string) *SyncCheckpoint {
+	return &SyncCheckpoint{
+		UserKey:  userKey,
+		Sections: make(map[string]time.Time),
+		Pages:    make(map[string]PageCheckpoint),
+	}
+}
+
+// needsContent reports whether a listed page is new, moved, or modified since its section was last synced
+func (cp *SyncCheckpoint) needsContent(sectionID string, page msgraphmodels.OnenotePageable) bool {
+	known, ok := cp.Pages[getStringValue(page.GetId())]
+	if !ok || known.SectionID != sectionID {
+		return true
+	}
+
+	since, ok := cp.Sections[sectionID]
+	if !ok {
+		return true
+	}
+	return getTimeValue(page.GetLastModifiedDateTime()).After(since)
+}
+
+// CheckpointStore persists OneNote sync checkpoints per user
+type CheckpointStore interface {
+	// LoadCheckpoint returns the checkpoint of a user, or nil when the user was never synced
+	LoadCheckpoint(ctx context.Context, userKey string) (*SyncCheckpoint, error)
+	// SaveCheckpoint stores the checkpoint of a user, replacing any previous one
+	SaveCheckpoint(ctx context.Context, checkpoint *SyncCheckpoint) error
+	// DeleteCheckpoint removes the checkpoint of a user so that the next sync is a full crawl
+	DeleteCheckpoint(ctx context.Context, userKey string) error
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory for the lifetime of the process
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string][]byte
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string][]byte)}
+}
+
+// LoadCheckpoint returns a copy of the stored checkpoint of a user
+func (s *MemoryCheckpointStore) LoadCheckpoint(ctx context.Context, userKey string) (*SyncCheckpoint, error) {
+	s.mu.Lock()
+	data, ok := s.checkpoints[userKey]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+	return decodeCheckpoint(data)
+}
+
+// SaveCheckpoint stores a copy of the checkpoint
+func (s *MemoryCheckpointStore) SaveCheckpoint(ctx context.Context, checkpoint *SyncCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync checkpoint: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[checkpoint.UserKey] = data
+	return nil
+}
+
+// DeleteCheckpoint removes the checkpoint of a user
+func (s *MemoryCheckpointStore) DeleteCheckpoint(ctx context.Context, userKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, userKey)
+	return nil
+}
+
+// FileCheckpointStore keeps one JSON checkpoint file per user in a directory
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCheckpointStore creates a checkpoint store in dir, creating the directory if needed
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create sync checkpoint directory: %w", err)
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+// path returns the checkpoint file of a user
+func (s *FileCheckpointStore) path(userKey string) string {
+	return filepath.Join(s.dir, "onenote-"+url.PathEscape(userKey)+".json")
+}
+
+// LoadCheckpoint reads the checkpoint file of a user
+func (s *FileCheckpointStore) LoadCheckpoint(ctx context.Context, userKey string) (*SyncCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(userKey))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync checkpoint: %w", err)
+	}
+	return decodeCheckpoint(data)
+}
+
+// SaveCheckpoint writes the checkpoint file of a user, replacing it atomically
+func (s *FileCheckpointStore) SaveCheckpoint(ctx context.Context, checkpoint *SyncCheckpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync checkpoint: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(checkpoint.UserKey)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write sync checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteCheckpoint removes the checkpoint file of a user
+func (s *FileCheckpointStore) DeleteCheckpoint(ctx context.Context, userKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(userKey)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+// decodeCheckpoint decodes a stored checkpoint, initialising missing maps
+func decodeCheckpoint(data []byte) (*SyncCheckpoint, error) {
+	var checkpoint SyncCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode sync checkpoint: %w", err)
+	}
+	if checkpoint.Sections == nil {
+		checkpoint.Sections = make(map[string]time.Time)
+	}
+	if checkpoint.Pages == nil {
+		checkpoint.Pages = make(map[string]PageCheckpoint)
+	}
+	return &checkpoint, nil
+}
+
+// OneNoteSyncResult describes what changed since the previous sync of a user
+type OneNoteSyncResult struct {
+	Collection         *types.DocumentCollection `json:"collection"` // Documents of added and changed pages and their attachments
+	FullSync           bool                      `json:"full_sync"`  // True when every page was downloaded, on the first or a forced sync
+	Since              time.Time                 `json:"since"`      // Start of the previous successful sync
+	AddedPageIDs       []string                  `json:"added_page_ids"`
+	ChangedPageIDs     []string                  `json:"changed_page_ids"`
+	DeletedPageIDs     []string                  `json:"deleted_page_ids"`
+	DeletedDocumentIDs []string                  `json:"deleted_document_ids"` // Deleted pages and attachment documents that no longer exist
+	FailedPageIDs      []string                  `json:"failed_page_ids,omitempty"`
+	UnchangedPages     int                       `json:"unchanged_pages"`
+	DeletionsDetected  bool                      `json:"deletions_detected"` // False when the listing was incomplete and deletions were not checked
+
+	// Checkpoint is the checkpoint after this sync, saved by CommitSync once the changes are applied
+	Checkpoint *SyncCheckpoint `json:"-"`
+}
+
+// SyncOneNoteData downloads only the OneNote pages added or modified since the previous sync
+// of the user and reports added, changed and deleted pages. With full set, or when the user was
+// never synced, every page is downloaded. The checkpoint is not saved: call CommitSync once the
+// changes have been stored, so that a failed store reports the same changes again next time.
+func (c *Client) SyncOneNoteData(ctx context.Context, full bool) (*OneNoteSyncResult, error) {
+	userKey, err := c.syncUserKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify user for sync: %w", err)
+	}
+
+	store := c.checkpoints
+	checkpoint, err := store.LoadCheckpoint(ctx, userKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync checkpoint: %w", err)
+	}
+
+	result := &OneNoteSyncResult{FullSync: full || checkpoint == nil}
+	if checkpoint == nil {
+		checkpoint = newSyncCheckpoint(userKey)
+	} else if full {
+		// Forget the section checkpoints so every page is downloaded, but keep the known
+		// pages so that deleted pages are still reported
+		checkpoint.Sections = make(map[string]time.Time)
+	}
+	result.Since = checkpoint.LastSyncAt
+
+	if result.FullSync {
+		log.Printf("🔄 Starting full OneNote sync for user %s", userKey)
+	} else {
+		log.Printf("🔄 Starting incremental OneNote sync for user %s (since %s)", userKey, checkpoint.LastSyncAt.Format(time.RFC3339))
+	}
+
+	startedAt := time.Now()
+	rawData, err := c.fetchOneNoteRawDataFiltered(ctx, c.oneNoteConcurrency, checkpoint.needsContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OneNote data: %w", err)
+	}
+
+	result.Collection = c.buildOneNoteCollection(ctx, rawData)
+	applySyncChanges(checkpoint, rawData, result)
+	checkpoint.LastSyncAt = startedAt
+	result.Checkpoint = checkpoint
+
+	log.Printf("📊 OneNote sync completed: %d added, %d changed, %d deleted, %d unchanged, %d failed",
+		len(result.AddedPageIDs), len(result.ChangedPageIDs), len(result.DeletedPageIDs), result.UnchangedPages, len(result.FailedPageIDs))
+
+	return result, nil
+}
+
+// CommitSync saves the checkpoint of a sync result, so that the next sync only reports
+// changes made after it. Call it only once the result's changes have been stored.
+func (c *Client) CommitSync(ctx context.Context, result *OneNoteSyncResult) error {
+	if result == nil || result.Checkpoint == nil {
+		return fmt.Errorf("sync result has no checkpoint")
+	}
+	if err := c.checkpoints.SaveCheckpoint(ctx, result.Checkpoint); err != nil {
+		return fmt.Errorf("failed to save sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+// applySyncChanges compares the listed pages with the checkpoint, records the changes in result
+// and advances the checkpoint. A section's checkpoint only advances when all its changed pages
+// were downloaded, so failed pages are retried by the next sync.
+func applySyncChanges(checkpoint *SyncCheckpoint, rawData *OneNoteRawData, result *OneNoteSyncResult) {
+	result.AddedPageIDs = []string{}
+	result.ChangedPageIDs = []string{}
+	result.DeletedPageIDs = []string{}
+	result.DeletedDocumentIDs = []string{}
+
+	pageDocuments := make(map[string]types.Document)
+	for _, doc := range result.Collection.Documents {
+		if doc.Type == "page" {
+			pageDocuments[doc.ID] = doc
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, sectionID := range sortedKeys(rawData.Pages) {
+		pages := rawData.Pages[sectionID]
+		complete := true
+		latest := checkpoint.Sections[sectionID]
+
+		for _, page := range pages {
+			pageID := getStringValue(page.GetId())
+			if pageID == "" {
+				continue
+			}
+			listed[pageID] = true
+
+			lastModified := getTimeValue(page.GetLastModifiedDateTime())
+			if lastModified.After(latest) {
+				latest = lastModified
+			}
+
+			if !checkpoint.needsContent(sectionID, page) {
+				result.UnchangedPages++
+				continue
+			}
+
+			doc, ok := pageDocuments[pageID]
+			if !ok {
+				result.FailedPageIDs = append(result.FailedPageIDs, pageID)
+				complete = false
+				continue
+			}
+
+			attachmentIDs := documentAttachmentIDs(doc)
+			previous, known := checkpoint.Pages[pageID]
+			if known {
+				result.ChangedPageIDs = append(result.ChangedPageIDs, pageID)
+				result.DeletedDocumentIDs = append(result.DeletedDocumentIDs, missingIDs(previous.AttachmentIDs, attachmentIDs)...)
+			} else {
+				result.AddedPageIDs = append(result.AddedPageIDs, pageID)
+			}
+
+			checkpoint.Pages[pageID] = PageCheckpoint{
+				SectionID:     sectionID,
+				LastModified:  lastModified,
+				AttachmentIDs: attachmentIDs,
+			}
+		}
+
+		// Leave the section at its previous checkpoint so failed pages are fetched again
+		if complete {
+			checkpoint.Sections[sectionID] = latest
+		}
+	}
+
+	// Pages missing from a complete listing were deleted or moved out of reach. Pages skipped by
+	// the page title filter and pages of sections excluded by the filter still exist, so changing
+	// the filter never reports them as deleted.
+	result.DeletionsDetected = rawData.ListingComplete
+	if !rawData.ListingComplete {
+		log.Printf("⚠️  OneNote listing incomplete, skipping deletion detection")
+		return
+	}
+
+	for _, pageID := range sortedKeys(checkpoint.Pages) {
+		if listed[pageID] || rawData.SkippedPages[pageID] || sectionFiltered(rawData, checkpoint.Pages[pageID].SectionID) {
+			continue
+		}
+		result.DeletedPageIDs = append(result.DeletedPageIDs, pageID)
+		result.DeletedDocumentIDs = append(result.DeletedDocumentIDs, pageID)
+		result.DeletedDocumentIDs = append(result.DeletedDocumentIDs, checkpoint.Pages[pageID].AttachmentIDs...)
+		delete(checkpoint.Pages, pageID)
+	}
+	for sectionID := range checkpoint.Sections {
+		if _, ok := rawData.Pages[sectionID]; !ok && !sectionFiltered(rawData, sectionID) {
+			delete(checkpoint.Sections, sectionID)
+		}
+	}
+}
+
+// sectionFiltered reports whether a section still exists but its pages were not listed
+// because the filter excluded it
+func sectionFiltered(rawData *OneNoteRawData, sectionID string) bool {
+	_, crawled := rawData.Pages[sectionID]
+	return !crawled && rawData.ListedSections[sectionID]
+}
+
+// syncUserKey identifies the user whose checkpoint is used: the configured user ID for
+// application authentication, or the signed-in user for delegated authentication
+func (c *Client) syncUserKey(ctx context.Context) (string, error) {
+	if !c.IsDelegatedAuth() {
+		if c.userID == "" {
+			return "", fmt.Errorf("user ID is required for application authentication flow")
+		}
+		return c.userID, nil
+	}
+
+	var user msgraphmodels.Userable
+	err := c.withRetry(ctx, nil, "get_me", func() error {
+		var err error
+		user, err = c.graphClient.Me().Get(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if user == nil || getStringValue(user.GetId()) == "" {
+		return "", fmt.Errorf("signed-in user has no ID")
+	}
+	return getStringValue(user.GetId()), nil
+}
+
+// documentAttachmentIDs returns the attachment document IDs recorded on a page document
+func documentAttachmentIDs(doc types.Document) []string {
+	ids, _ := doc.Metadata["attachment_document_ids"].([]string)
+	return ids
+}
+
+// missingIDs returns the IDs of previous that are not in current
+func missingIDs(previous, current []string) []string {
+	present := make(map[string]bool, len(current))
+	for _, id := range current {
+		present[id] = true
+	}
+
+	var missing []string
+	for _, id := range previous {
+		if !present[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// sortedKeys returns the keys of a map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,260 @@
+package msgraph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+)
+
+// createModifiedPage creates a mock page with a lastModifiedDateTime
+func createModifiedPage(id string, lastModified time.Time) msgraphmodels.OnenotePageable {
+	page := createMockPage(id, "Page "+id, lastModified)
+	page.SetLastModifiedDateTime(&lastModified)
+	return page
+}
+
+// syncCollection creates a collection with a page document for each page ID
+func syncCollection(pageIDs ...string) *types.DocumentCollection {
+	collection := types.NewDocumentCollection("OneNote")
+	for _, pageID := range pageIDs {
+		collection.AddDocument(types.Document{ID: pageID, Type: "page", Metadata: map[string]interface{}{}})
+	}
+	return collection
+}
+
+// TestSyncCheckpointNeedsContent tests which listed pages are downloaded
+func TestSyncCheckpointNeedsContent(t *testing.T) {
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	checkpoint := newSyncCheckpoint("user-1")
+	checkpoint.Sections["section-1"] = synced
+	checkpoint.Pages["page-1"] = PageCheckpoint{SectionID: "section-1", LastModified: synced}
+
+	tests := []struct {
+		name      string
+		sectionID string
+		page      msgraphmodels.OnenotePageable
+		expected  bool
+	}{
+		{name: "unchanged page", sectionID: "section-1", page: createModifiedPage("page-1", synced), expected: false},
+		{name: "modified page", sectionID: "section-1", page: createModifiedPage("page-1", synced.Add(time.Minute)), expected: true},
+		{name: "new page", sectionID: "section-1", page: createModifiedPage("page-2", synced.Add(-time.Hour)), expected: true},
+		{name: "moved page", sectionID: "section-2", page: createModifiedPage("page-1", synced), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkpoint.needsContent(tt.sectionID, tt.page); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestApplySyncChanges tests classifying added, changed, deleted and unchanged pages
+func TestApplySyncChanges(t *testing.T) {
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	modified := synced.Add(time.Hour)
+
+	checkpoint := newSyncCheckpoint("user-1")
+	checkpoint.Sections["section-1"] = synced
+	checkpoint.Pages["unchanged"] = PageCheckpoint{SectionID: "section-1", LastModified: synced}
+	checkpoint.Pages["changed"] = PageCheckpoint{SectionID: "section-1", LastModified: synced, AttachmentIDs: []string{"old-attachment", "kept-attachment"}}
+	checkpoint.Pages["deleted"] = PageCheckpoint{SectionID: "section-1", LastModified: synced, AttachmentIDs: []string{"deleted-attachment"}}
+
+	rawData := &OneNoteRawData{
+		Pages: map[string][]msgraphmodels.OnenotePageable{
+			"section-1": {
+				createModifiedPage("unchanged", synced),
+				createModifiedPage("changed", modified),
+				createModifiedPage("added", modified),
+			},
+		},
+		ListingComplete: true,
+	}
+
+	collection := syncCollection("changed", "added")
+	collection.Documents[0].Metadata["attachment_document_ids"] = []string{"kept-attachment"}
+	result := &OneNoteSyncResult{Collection: collection}
+
+	applySyncChanges(checkpoint, rawData, result)
+
+	assertIDs(t, "added", []string{"added"}, result.AddedPageIDs)
+	assertIDs(t, "changed", []string{"changed"}, result.ChangedPageIDs)
+	assertIDs(t, "deleted pages", []string{"deleted"}, result.DeletedPageIDs)
+	assertIDs(t, "deleted documents", []string{"old-attachment", "deleted", "deleted-attachment"}, result.DeletedDocumentIDs)
+	if result.UnchangedPages != 1 {
+		t.Errorf("Expected 1 unchanged page, got %d", result.UnchangedPages)
+	}
+	if !result.DeletionsDetected {
+		t.Error("Expected deletions to be detected for a complete listing")
+	}
+
+	if !checkpoint.Sections["section-1"].Equal(modified) {
+		t.Errorf("Expected section checkpoint %s, got %s", modified, checkpoint.Sections["section-1"])
+	}
+	if _, ok := checkpoint.Pages["deleted"]; ok {
+		t.Error("Expected deleted page to be removed from the checkpoint")
+	}
+	if _, ok := checkpoint.Pages["added"]; !ok {
+		t.Error("Expected added page to be recorded in the checkpoint")
+	}
+}
+
+// TestApplySyncChangesFailedPage tests that a failed download keeps the section checkpoint
+func TestApplySyncChangesFailedPage(t *testing.T) {
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	modified := synced.Add(time.Hour)
+
+	checkpoint := newSyncCheckpoint("user-1")
+	checkpoint.Sections["section-1"] = synced
+
+	rawData := &OneNoteRawData{
+		Pages: map[string][]msgraphmodels.OnenotePageable{
+			"section-1": {createModifiedPage("ok", modified), createModifiedPage("failed", modified)},
+		},
+		ListingComplete: true,
+	}
+	result := &OneNoteSyncResult{Collection: syncCollection("ok")}
+
+	applySyncChanges(checkpoint, rawData, result)
+
+	assertIDs(t, "failed", []string{"failed"}, result.FailedPageIDs)
+	if !checkpoint.Sections["section-1"].Equal(synced) {
+		t.Errorf("Expected section checkpoint to stay at %s, got %s", synced, checkpoint.Sections["section-1"])
+	}
+	if _, ok := checkpoint.Pages["failed"]; ok {
+		t.Error("Expected failed page not to be recorded in the checkpoint")
+	}
+}
+
+// TestApplySyncChangesIncompleteListing tests that deletions are not reported for a partial listing
+func TestApplySyncChangesIncompleteListing(t *testing.T) {
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	checkpoint := newSyncCheckpoint("user-1")
+	checkpoint.Sections["section-1"] = synced
+	checkpoint.Pages["missing"] = PageCheckpoint{SectionID: "section-1", LastModified: synced}
+
+	rawData := &OneNoteRawData{Pages: map[string][]msgraphmodels.OnenotePageable{}}
+	result := &OneNoteSyncResult{Collection: syncCollection()}
+
+	applySyncChanges(checkpoint, rawData, result)
+
+	if result.DeletionsDetected || len(result.DeletedPageIDs) != 0 {
+		t.Errorf("Expected no deletions for an incomplete listing, got %v", result.DeletedPageIDs)
+	}
+	if _, ok := checkpoint.Pages["missing"]; !ok {
+		t.Error("Expected unlisted page to stay in the checkpoint")
+	}
+}
+
+// TestApplySyncChangesFiltered tests that pages excluded by the filter are not reported as deleted
+func TestApplySyncChangesFiltered(t *testing.T) {
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	checkpoint := newSyncCheckpoint("user-1")
+	checkpoint.Sections["included"] = synced
+	checkpoint.Sections["excluded"] = synced
+	checkpoint.Sections["removed"] = synced
+	checkpoint.Pages["listed"] = PageCheckpoint{SectionID: "included", LastModified: synced}
+	checkpoint.Pages["title-skipped"] = PageCheckpoint{SectionID: "included", LastModified: synced}
+	checkpoint.Pages["deleted"] = PageCheckpoint{SectionID: "included", LastModified: synced}
+	checkpoint.Pages["in-excluded-section"] = PageCheckpoint{SectionID: "excluded", LastModified: synced}
+	checkpoint.Pages["in-removed-section"] = PageCheckpoint{SectionID: "removed", LastModified: synced}
+
+	rawData := &OneNoteRawData{
+		Pages: map[string][]msgraphmodels.OnenotePageable{
+			"included": {createModifiedPage("listed", synced)},
+		},
+		ListedSections:  map[string]bool{"included": true, "excluded": true},
+		SkippedPages:    map[string]bool{"title-skipped": true},
+		ListingComplete: true,
+	}
+	result := &OneNoteSyncResult{Collection: syncCollection()}
+
+	applySyncChanges(checkpoint, rawData, result)
+
+	assertIDs(t, "deleted pages", []string{"deleted", "in-removed-section"}, result.DeletedPageIDs)
+	for _, pageID := range []string{"title-skipped", "in-excluded-section"} {
+		if _, ok := checkpoint.Pages[pageID]; !ok {
+			t.Errorf("Expected filtered page %s to stay in the checkpoint", pageID)
+		}
+	}
+	if _, ok := checkpoint.Sections["excluded"]; !ok {
+		t.Error("Expected the excluded section to keep its checkpoint")
+	}
+	if _, ok := checkpoint.Sections["removed"]; ok {
+		t.Error("Expected the removed section checkpoint to be dropped")
+	}
+}
+
+// TestCheckpointStores tests saving, loading and deleting checkpoints
+func TestCheckpointStores(t *testing.T) {
+	fileStore, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stores := map[string]CheckpointStore{
+		"memory": NewMemoryCheckpointStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			loaded, err := store.LoadCheckpoint(ctx, "user/1")
+			if err != nil || loaded != nil {
+				t.Fatalf("Expected no checkpoint, got %v (%v)", loaded, err)
+			}
+
+			checkpoint := newSyncCheckpoint("user/1")
+			checkpoint.LastSyncAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			checkpoint.Pages["page-1"] = PageCheckpoint{SectionID: "section-1", AttachmentIDs: []string{"attachment-1"}}
+			if err := store.SaveCheckpoint(ctx, checkpoint); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Changes after saving must not leak into the stored checkpoint
+			checkpoint.Pages["page-2"] = PageCheckpoint{}
+
+			loaded, err = store.LoadCheckpoint(ctx, "user/1")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !loaded.LastSyncAt.Equal(checkpoint.LastSyncAt) || len(loaded.Pages) != 1 {
+				t.Errorf("Unexpected checkpoint: %+v", loaded)
+			}
+			if ids := loaded.Pages["page-1"].AttachmentIDs; len(ids) != 1 || ids[0] != "attachment-1" {
+				t.Errorf("Expected attachment IDs to be stored, got %v", ids)
+			}
+
+			if err := store.DeleteCheckpoint(ctx, "user/1"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if loaded, _ := store.LoadCheckpoint(ctx, "user/1"); loaded != nil {
+				t.Error("Expected checkpoint to be deleted")
+			}
+		})
+	}
+}
+
+// assertIDs compares ID lists in order
+func assertIDs(t *testing.T, name string, expected, actual []string) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Errorf("Expected %s %v, got %v", name, expected, actual)
+		return
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("Expected %s %v, got %v", name, expected, actual)
+			return
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
 
 	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/internal/utils"
 )
 
 // TestConcurrencyConfig tests the concurrency configuration
@@ -340,6 +341,56 @@ func TestProcessPageContentWithNilValues(t *testing.T) {
 	}
 }
 
+// TestProcessPageContentStructure tests extraction of OneNote to-dos, tags, tables and resources
+func TestProcessPageContentStructure(t *testing.T) {
+	client := &Client{}
+
+	notebook := createMockNotebook("notebook-123", "Test Notebook")
+	section := createMockSection("section-456", "Test Section")
+	page := createMockPage("page-789", "Test Page", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	content := []byte(`<html><body><div data-id="div:{1}">` +
+		`<p data-tag="to-do">Open task</p><p data-tag="to-do:completed">Done task</p>` +
+		`<p data-tag="important">Key point</p>` +
+		`<table><tr><td>a</td><td>b</td></tr></table>` +
+		`<img src="https://graph.microsoft.com/v1.0/me/onenote/resources/img-1/$value" data-src-type="image/png" />` +
+		`<object data-attachment="notes.pdf" type="application/pdf" data="https://graph.microsoft.com/v1.0/me/onenote/resources/file-1/$value"></object>` +
+		`</div></body></html>`)
+
+	doc, err := client.processPageContent(page, notebook, section, content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	todos, ok := doc.Metadata["todos"].([]utils.OneNoteToDo)
+	if !ok || len(todos) != 2 {
+		t.Fatalf("Expected 2 to-dos, got %v", doc.Metadata["todos"])
+	}
+	if todos[0].Completed || !todos[1].Completed {
+		t.Errorf("Expected only the second to-do to be completed, got %+v", todos)
+	}
+
+	tags, ok := doc.Metadata["tags"].([]utils.OneNoteTag)
+	if !ok || len(tags) != 1 || tags[0].Tag != "important" || tags[0].Text != "Key point" {
+		t.Errorf("Expected an 'important' tag on 'Key point', got %v", doc.Metadata["tags"])
+	}
+
+	tables, ok := doc.Metadata["tables"].([]utils.OneNoteTable)
+	if !ok || len(tables) != 1 || len(tables[0].Rows) != 1 || len(tables[0].Rows[0]) != 2 {
+		t.Errorf("Expected one 1x2 table, got %v", doc.Metadata["tables"])
+	}
+
+	images, ok := doc.Metadata["images"].([]utils.OneNoteResource)
+	if !ok || len(images) != 1 || images[0].ResourceID != "img-1" {
+		t.Errorf("Expected image resource 'img-1', got %v", doc.Metadata["images"])
+	}
+
+	attachments, ok := doc.Metadata["attachments"].([]utils.OneNoteResource)
+	if !ok || len(attachments) != 1 || attachments[0].Name != "notes.pdf" || attachments[0].ResourceID != "file-1" {
+		t.Errorf("Expected attachment 'notes.pdf' with resource 'file-1', got %v", doc.Metadata["attachments"])
+	}
+}
+
 // TestCombineOneNoteDataEmptyData tests the business logic with empty data
 func TestCombineOneNoteDataEmptyData(t *testing.T) {
 	// Create empty raw data
@@ -0,0 +1,239 @@
+package msgraph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// PaginationConfig controls how Graph collections are paged
+type PaginationConfig struct {
+	PageSize int // Items requested per Graph page via $top (0 uses the Graph default)
+	MaxItems int // Maximum notebooks, sections and pages listed per run (0 = unlimited)
+}
+
+// DefaultPaginationConfig returns the default Graph paging settings
+func DefaultPaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		PageSize: 100, // Graph allows up to 100 OneNote items per page
+		MaxItems: 0,
+	}
+}
+
+// itemBudget limits how many items of a single listing a run may collect.
+// Every listing gets its own budget, so the items kept never depend on the order
+// in which concurrent workers run.
+type itemBudget struct {
+	limited   bool
+	remaining int
+	exhausted bool
+}
+
+// newItemBudget creates a budget of maxItems items, or an unlimited one when maxItems <= 0
+func newItemBudget(maxItems int) *itemBudget {
+	return &itemBudget{limited: maxItems > 0, remaining: maxItems}
+}
+
+// take claims one item, returning false once the budget is used up
+func (b *itemBudget) take() bool {
+	if b == nil || !b.limited {
+		return true
+	}
+	if b.remaining > 0 {
+		b.remaining--
+		return true
+	}
+	if !b.exhausted {
+		b.exhausted = true
+		log.Printf("⚠️  Reached the limit of items per run, remaining items are skipped")
+	}
+	return false
+}
+
+// Exhausted reports whether any item was skipped because the budget ran out
+func (b *itemBudget) Exhausted() bool {
+	return b != nil && b.exhausted
+}
+
+// capSectionPages keeps at most maxItems pages across the sections, taken in the given order,
+// and reports whether any page was dropped. The cap is applied once all sections are listed
+// so that the pages kept do not depend on which section worker finished first.
+func capSectionPages(sectionIDs []string, pages map[string][]msgraphmodels.OnenotePageable, maxItems int) bool {
+	if maxItems <= 0 {
+		return false
+	}
+
+	remaining := maxItems
+	truncated := false
+	for _, sectionID := range sectionIDs {
+		sectionPages, ok := pages[sectionID]
+		if !ok {
+			continue
+		}
+		if len(sectionPages) > remaining {
+			pages[sectionID] = sectionPages[:remaining]
+			truncated = true
+		}
+		remaining -= len(pages[sectionID])
+	}
+	return truncated
+}
+
+// pageSize returns the $top value for Graph requests, or nil to use the Graph default
+func (c *Client) pageSize() *int32 {
+	if c.pagination.PageSize <= 0 {
+		return nil
+	}
+	top := int32(c.pagination.PageSize)
+	return &top
+}
+
+// retryingAdapter sends the requests of a page iterator through withRetry, so that every
+// following page holds a slot of the limiter and is retried when throttled, like the first request
+type retryingAdapter struct {
+	abstractions.RequestAdapter
+	client    *Client
+	limiter   *adaptiveLimiter
+	operation string
+}
+
+// Send requests a collection page, mapping Graph errors to OData errors as the generated
+// request builders do when the page iterator has no error mappings registered
+func (a *retryingAdapter) Send(ctx context.Context, requestInfo *abstractions.RequestInformation, constructor serialization.ParsableFactory, errorMappings abstractions.ErrorMappings) (serialization.Parsable, error) {
+	if len(errorMappings) == 0 {
+		errorMappings = abstractions.ErrorMappings{
+			"XXX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		}
+	}
+
+	var response serialization.Parsable
+	err := a.client.withRetry(ctx, a.limiter, a.operation, func() error {
+		var err error
+		response, err = a.RequestAdapter.Send(ctx, requestInfo, constructor, errorMappings)
+		return err
+	})
+	return response, err
+}
+
+// collectAllPages walks every page of a Graph collection response with the SDK page iterator
+// and returns all items, stopping early once the budget is used up. When a page fails, the
+// items collected so far are returned along with the error.
+func collectAllPages[T any](ctx context.Context, c *Client, limiter *adaptiveLimiter, operation string, response serialization.Parsable, factory serialization.ParsableFactory, budget *itemBudget) ([]T, error) {
+	adapter := &retryingAdapter{
+		RequestAdapter: c.graphClient.GetAdapter(),
+		client:         c,
+		limiter:        limiter,
+		operation:      operation,
+	}
+	iterator, err := msgraphcore.NewPageIterator[T](response, adapter, factory)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected collection response %T: %w", response, err)
+	}
+
+	var items []T
+	err = iterator.Iterate(ctx, func(item T) bool {
+		if !budget.take() {
+			return false
+		}
+		items = append(items, item)
+		return true
+	})
+	if err != nil {
+		return items, fmt.Errorf("failed to fetch next page: %w", err)
+	}
+
+	return items, nil
+}
+
+// listNotebooks returns every notebook of the user, following pagination
+func (c *Client) listNotebooks(ctx context.Context, budget *itemBudget) ([]msgraphmodels.Notebookable, error) {
+	if !c.IsDelegatedAuth() && c.GetUserID() == "" {
+		return nil, fmt.Errorf("user ID is required for application authentication flow")
+	}
+
+	var response msgraphmodels.NotebookCollectionResponseable
+	err := c.withRetry(ctx, nil, "list_notebooks", func() error {
+		var err error
+		if c.IsDelegatedAuth() {
+			response, err = c.graphClient.Me().Onenote().Notebooks().Get(ctx, &users.ItemOnenoteNotebooksRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemOnenoteNotebooksRequestBuilderGetQueryParameters{Top: c.pageSize()},
+			})
+		} else {
+			response, err = c.graphClient.Users().ByUserId(c.GetUserID()).Onenote().Notebooks().Get(ctx, &users.ItemOnenoteNotebooksRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemOnenoteNotebooksRequestBuilderGetQueryParameters{Top: c.pageSize()},
+			})
+		}
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, nil
+	}
+
+	return collectAllPages[msgraphmodels.Notebookable](ctx, c, nil, "list_notebooks", response, msgraphmodels.CreateNotebookCollectionResponseFromDiscriminatorValue, budget)
+}
+
+// listSections returns every section across all notebooks of the user, following pagination
+func (c *Client) listSections(ctx context.Context, budget *itemBudget) ([]msgraphmodels.OnenoteSectionable, error) {
+	var response msgraphmodels.OnenoteSectionCollectionResponseable
+	err := c.withRetry(ctx, nil, "list_sections", func() error {
+		var err error
+		if c.IsDelegatedAuth() {
+			response, err = c.graphClient.Me().Onenote().Sections().Get(ctx, &users.ItemOnenoteSectionsRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemOnenoteSectionsRequestBuilderGetQueryParameters{Top: c.pageSize()},
+			})
+		} else {
+			response, err = c.graphClient.Users().ByUserId(c.GetUserID()).Onenote().Sections().Get(ctx, &users.ItemOnenoteSectionsRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemOnenoteSectionsRequestBuilderGetQueryParameters{Top: c.pageSize()},
+			})
+		}
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, nil
+	}
+
+	return collectAllPages[msgraphmodels.OnenoteSectionable](ctx, c, nil, "list_sections", response, msgraphmodels.CreateOnenoteSectionCollectionResponseFromDiscriminatorValue, budget)
+}
+
+// listSectionPages returns every page of a section, following pagination.
+// Every request holds a slot of the limiter and is retried when throttled.
+func (c *Client) listSectionPages(ctx context.Context, sectionID string, budget *itemBudget, limiter *adaptiveLimiter) ([]msgraphmodels.OnenotePageable, error) {
+	var response msgraphmodels.OnenotePageCollectionResponseable
+	err := c.withRetry(ctx, limiter, "list_pages", func() error {
+		var err error
+		if c.IsDelegatedAuth() {
+			response, err = c.graphClient.Me().Onenote().Sections().ByOnenoteSectionId(sectionID).Pages().Get(ctx, &users.ItemOnenoteSectionsItemPagesRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemOnenoteSectionsItemPagesRequestBuilderGetQueryParameters{Top: c.pageSize()},
+			})
+		} else {
+			response, err = c.graphClient.Users().ByUserId(c.GetUserID()).Onenote().Sections().ByOnenoteSectionId(sectionID).Pages().Get(ctx, &users.ItemOnenoteSectionsItemPagesRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemOnenoteSectionsItemPagesRequestBuilderGetQueryParameters{Top: c.pageSize()},
+			})
+		}
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, nil
+	}
+
+	return collectAllPages[msgraphmodels.OnenotePageable](ctx, c, limiter, "list_pages", response, msgraphmodels.CreateOnenotePageCollectionResponseFromDiscriminatorValue, budget)
+}
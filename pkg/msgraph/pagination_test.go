@@ -0,0 +1,109 @@
+package msgraph
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	msgraphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// TestDefaultPaginationConfig tests the default Graph paging settings
+func TestDefaultPaginationConfig(t *testing.T) {
+	config := DefaultPaginationConfig()
+	if config.PageSize != 100 {
+		t.Errorf("Expected default PageSize to be 100, got %d", config.PageSize)
+	}
+	if config.MaxItems != 0 {
+		t.Errorf("Expected default MaxItems to be 0, got %d", config.MaxItems)
+	}
+}
+
+// TestItemBudget tests that a limited budget stops after MaxItems items
+func TestItemBudget(t *testing.T) {
+	budget := newItemBudget(2)
+
+	if !budget.take() || !budget.take() {
+		t.Fatal("Expected the first two items to be taken")
+	}
+	if budget.Exhausted() {
+		t.Error("Expected budget not to be exhausted before an item is skipped")
+	}
+	if budget.take() {
+		t.Error("Expected the third item to be refused")
+	}
+	if !budget.Exhausted() {
+		t.Error("Expected budget to be exhausted after an item is skipped")
+	}
+}
+
+// TestItemBudgetUnlimited tests that a zero limit never refuses items
+func TestItemBudgetUnlimited(t *testing.T) {
+	budget := newItemBudget(0)
+	for i := 0; i < 1000; i++ {
+		if !budget.take() {
+			t.Fatalf("Expected unlimited budget to take item %d", i)
+		}
+	}
+	if budget.Exhausted() {
+		t.Error("Expected unlimited budget never to be exhausted")
+	}
+}
+
+// TestCapSectionPages tests that the page cap keeps pages in section listing order
+func TestCapSectionPages(t *testing.T) {
+	newPages := func(ids ...string) []msgraphmodels.OnenotePageable {
+		var pages []msgraphmodels.OnenotePageable
+		for _, id := range ids {
+			pages = append(pages, createMockPage(id, id, time.Now()))
+		}
+		return pages
+	}
+	pageIDs := func(pages []msgraphmodels.OnenotePageable) []string {
+		ids := []string{}
+		for _, page := range pages {
+			ids = append(ids, getStringValue(page.GetId()))
+		}
+		return ids
+	}
+
+	// Workers may finish in any order, the cap only depends on the section order
+	pages := map[string][]msgraphmodels.OnenotePageable{
+		"section-3": newPages("p5"),
+		"section-1": newPages("p1", "p2"),
+		"section-2": newPages("p3", "p4"),
+	}
+	if !capSectionPages([]string{"section-1", "section-2", "section-3"}, pages, 3) {
+		t.Error("Expected pages to be dropped")
+	}
+	if ids := pageIDs(pages["section-1"]); !reflect.DeepEqual(ids, []string{"p1", "p2"}) {
+		t.Errorf("Expected section-1 to keep all pages, got %v", ids)
+	}
+	if ids := pageIDs(pages["section-2"]); !reflect.DeepEqual(ids, []string{"p3"}) {
+		t.Errorf("Expected section-2 to keep p3, got %v", ids)
+	}
+	if ids := pageIDs(pages["section-3"]); len(ids) != 0 {
+		t.Errorf("Expected section-3 to keep no pages, got %v", ids)
+	}
+
+	pages = map[string][]msgraphmodels.OnenotePageable{"section-1": newPages("p1", "p2")}
+	if capSectionPages([]string{"section-1"}, pages, 0) || len(pages["section-1"]) != 2 {
+		t.Error("Expected no cap without MaxItems")
+	}
+	if capSectionPages([]string{"section-1"}, pages, 2) || len(pages["section-1"]) != 2 {
+		t.Error("Expected no pages to be dropped at the limit")
+	}
+}
+
+// TestPageSize tests the $top value sent to Graph
+func TestPageSize(t *testing.T) {
+	client := &Client{pagination: PaginationConfig{PageSize: 25}}
+	if top := client.pageSize(); top == nil || *top != 25 {
+		t.Errorf("Expected $top 25, got %v", top)
+	}
+
+	client = &Client{pagination: PaginationConfig{PageSize: 0}}
+	if top := client.pageSize(); top != nil {
+		t.Errorf("Expected no $top for the Graph default, got %d", *top)
+	}
+}
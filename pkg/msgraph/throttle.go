@@ -0,0 +1,258 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RetryConfig controls how throttled Graph requests are retried
+type RetryConfig struct {
+	MaxRetries int           // Retries after a throttled response before giving up (0 disables retries)
+	BaseDelay  time.Duration // Initial backoff when Graph sends no Retry-After header
+	MaxDelay   time.Duration // Upper bound for the exponential backoff
+}
+
+// DefaultRetryConfig returns the default retry settings for throttled Graph requests
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries: 5,
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Minute,
+	}
+}
+
+// Prometheus metrics for Graph throttling, labelled by operation or worker pool
+var (
+	throttledRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "msgraph_throttled_requests_total",
+		Help: "Number of Graph requests answered with 429 or 503.",
+	}, []string{"operation"})
+	retriedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "msgraph_retried_requests_total",
+		Help: "Number of Graph requests retried after throttling.",
+	}, []string{"operation"})
+	exhaustedRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "msgraph_retries_exhausted_total",
+		Help: "Number of Graph requests that stayed throttled after all retries.",
+	}, []string{"operation"})
+	effectiveWorkers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "msgraph_effective_workers",
+		Help: "Current number of concurrent Graph requests allowed per worker pool.",
+	}, []string{"pool"})
+)
+
+// throttleInfo reports whether err is a Graph throttling response (429 or 503)
+// and the delay requested by its Retry-After header, if any
+func throttleInfo(err error) (bool, time.Duration) {
+	statusCode, headers, ok := graphResponse(err)
+	if !ok {
+		return false, 0
+	}
+
+	if statusCode != http.StatusTooManyRequests && statusCode != http.StatusServiceUnavailable {
+		return false, 0
+	}
+	if headers == nil {
+		return true, 0
+	}
+
+	for _, value := range headers.Get("Retry-After") {
+		if delay, ok := parseRetryAfter(value, time.Now()); ok {
+			return true, delay
+		}
+	}
+	return true, 0
+}
+
+// graphResponse returns the status code and headers of a Graph API error response
+func graphResponse(err error) (int, *abstractions.ResponseHeaders, bool) {
+	if err == nil {
+		return 0, nil, false
+	}
+
+	var odataErr *odataerrors.ODataError
+	var apiErr *abstractions.ApiError
+	switch {
+	case errors.As(err, &odataErr):
+		return odataErr.ResponseStatusCode, odataErr.ResponseHeaders, true
+	case errors.As(err, &apiErr):
+		return apiErr.ResponseStatusCode, apiErr.ResponseHeaders, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// backoff returns the delay before the given retry attempt (starting at 0). A Retry-After
+// value from Graph is honoured as is; otherwise the delay grows exponentially up to MaxDelay
+// with jitter so that throttled workers do not retry in lockstep.
+func (r RetryConfig) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	delay := r.BaseDelay
+	for i := 0; i < attempt && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, r.MaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: wait between half and the full delay
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// withRetry runs a Graph call, retrying it while Graph answers with 429 or 503.
+// The call holds a slot of the limiter, which shrinks whenever throttling is observed.
+func (c *Client) withRetry(ctx context.Context, limiter *adaptiveLimiter, operation string, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := limiter.acquire(ctx); err != nil {
+			return err
+		}
+		err := call()
+		throttled, retryAfter := throttleInfo(err)
+		limiter.release(throttled)
+
+		if !throttled {
+			return err
+		}
+
+		throttledRequestsTotal.WithLabelValues(operation).Inc()
+		if attempt >= c.retry.MaxRetries {
+			exhaustedRetriesTotal.WithLabelValues(operation).Inc()
+			return fmt.Errorf("still throttled after %d retries: %w", attempt, err)
+		}
+
+		delay := c.retry.backoff(attempt, retryAfter)
+		log.Printf("⏳ Graph throttled %s (retry %d/%d), waiting %s", operation, attempt+1, c.retry.MaxRetries, delay)
+		retriedRequestsTotal.WithLabelValues(operation).Inc()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// adaptiveLimiter bounds the number of concurrent Graph requests of a worker pool.
+// The limit is halved whenever a request is throttled and grows back by one after
+// twice as many consecutive successful requests as the current limit, so that a single
+// successful retry does not undo the throttle.
+type adaptiveLimiter struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	pool      string
+	max       int
+	limit     int
+	inFlight  int
+	successes int
+}
+
+// newAdaptiveLimiter creates a limiter allowing up to maxConcurrent requests
+func newAdaptiveLimiter(pool string, maxConcurrent int) *adaptiveLimiter {
+	maxConcurrent = max(maxConcurrent, 1)
+	l := &adaptiveLimiter{pool: pool, max: maxConcurrent, limit: maxConcurrent}
+	l.cond = sync.NewCond(&l.mu)
+	effectiveWorkers.WithLabelValues(pool).Set(float64(maxConcurrent))
+	return l
+}
+
+// acquire waits for a free request slot or for ctx to be done
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inFlight >= l.limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l.cond.Wait()
+	}
+	l.inFlight++
+	return nil
+}
+
+// release frees a request slot and adapts the limit to the outcome of the request
+func (l *adaptiveLimiter) release(throttled bool) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	switch {
+	case throttled:
+		l.successes = 0
+		if limit := max(l.limit/2, 1); limit < l.limit {
+			l.limit = limit
+			log.Printf("🐢 Graph throttling observed, reducing %s workers to %d", l.pool, l.limit)
+			effectiveWorkers.WithLabelValues(l.pool).Set(float64(l.limit))
+		}
+	case l.limit < l.max:
+		l.successes++
+		if l.successes >= 2*l.limit {
+			l.successes = 0
+			l.limit++
+			effectiveWorkers.WithLabelValues(l.pool).Set(float64(l.limit))
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// currentLimit returns the number of concurrent requests currently allowed
+func (l *adaptiveLimiter) currentLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
@@ -0,0 +1,186 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+)
+
+// throttledError builds a Graph API error with the given status code and Retry-After header
+func throttledError(statusCode int, retryAfter string) error {
+	headers := abstractions.NewResponseHeaders()
+	if retryAfter != "" {
+		headers.Add("Retry-After", retryAfter)
+	}
+	return &abstractions.ApiError{ResponseStatusCode: statusCode, ResponseHeaders: headers}
+}
+
+// TestThrottleInfo tests detecting throttled responses and their Retry-After delay
+func TestThrottleInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		throttled  bool
+		retryAfter time.Duration
+	}{
+		{name: "nil error", err: nil},
+		{name: "plain error", err: errors.New("boom")},
+		{name: "not found", err: throttledError(404, "")},
+		{name: "too many requests", err: throttledError(429, "7"), throttled: true, retryAfter: 7 * time.Second},
+		{name: "service unavailable", err: throttledError(503, ""), throttled: true},
+		{name: "wrapped", err: fmt.Errorf("failed: %w", throttledError(429, "2")), throttled: true, retryAfter: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttled, retryAfter := throttleInfo(tt.err)
+			if throttled != tt.throttled || retryAfter != tt.retryAfter {
+				t.Errorf("Expected (%v, %s), got (%v, %s)", tt.throttled, tt.retryAfter, throttled, retryAfter)
+			}
+		})
+	}
+}
+
+// TestParseRetryAfter tests both Retry-After formats
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if delay, ok := parseRetryAfter("30", now); !ok || delay != 30*time.Second {
+		t.Errorf("Expected 30s, got %s (%v)", delay, ok)
+	}
+	if delay, ok := parseRetryAfter("Mon, 01 Jan 2024 12:01:00 GMT", now); !ok || delay != time.Minute {
+		t.Errorf("Expected 1m, got %s (%v)", delay, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("Expected an invalid Retry-After to be rejected")
+	}
+}
+
+// TestRetryBackoff tests that backoff honours Retry-After and stays within bounds
+func TestRetryBackoff(t *testing.T) {
+	config := RetryConfig{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 8 * time.Second}
+
+	if delay := config.backoff(3, 20*time.Second); delay != 20*time.Second {
+		t.Errorf("Expected Retry-After to be honoured, got %s", delay)
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		expected := min(time.Second<<attempt, 8*time.Second)
+		delay := config.backoff(attempt, 0)
+		if delay < expected/2 || delay > expected {
+			t.Errorf("Attempt %d: expected delay between %s and %s, got %s", attempt, expected/2, expected, delay)
+		}
+	}
+}
+
+// TestWithRetry tests retrying a throttled call until it succeeds
+func TestWithRetry(t *testing.T) {
+	client := &Client{retry: RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}}
+	limiter := newAdaptiveLimiter("test", 4)
+
+	calls := 0
+	err := client.withRetry(context.Background(), limiter, "test", func() error {
+		calls++
+		if calls < 3 {
+			return throttledError(429, "")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if limit := limiter.currentLimit(); limit != 1 {
+		t.Errorf("Expected limit to shrink to 1 after two throttled calls, got %d", limit)
+	}
+}
+
+// TestWithRetryExhausted tests giving up once MaxRetries is reached
+func TestWithRetryExhausted(t *testing.T) {
+	client := &Client{retry: RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}}
+
+	calls := 0
+	err := client.withRetry(context.Background(), nil, "test", func() error {
+		calls++
+		return throttledError(503, "")
+	})
+
+	if err == nil {
+		t.Fatal("Expected an error after exhausting retries")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+// TestWithRetryNotThrottled tests that other errors are returned without retrying
+func TestWithRetryNotThrottled(t *testing.T) {
+	client := &Client{retry: DefaultRetryConfig()}
+	expected := errors.New("not found")
+
+	calls := 0
+	err := client.withRetry(context.Background(), nil, "test", func() error {
+		calls++
+		return expected
+	})
+
+	if !errors.Is(err, expected) || calls != 1 {
+		t.Errorf("Expected a single call returning %v, got %d calls and %v", expected, calls, err)
+	}
+}
+
+// TestAdaptiveLimiter tests shrinking on throttling and recovering after successes
+func TestAdaptiveLimiter(t *testing.T) {
+	limiter := newAdaptiveLimiter("test", 8)
+	ctx := context.Background()
+
+	for _, expected := range []int{4, 2, 1, 1} {
+		if err := limiter.acquire(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		limiter.release(true)
+		if limit := limiter.currentLimit(); limit != expected {
+			t.Errorf("Expected limit %d after throttling, got %d", expected, limit)
+		}
+	}
+
+	release := func(successes int) {
+		for i := 0; i < successes; i++ {
+			if err := limiter.acquire(ctx); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			limiter.release(false)
+		}
+	}
+
+	release(1)
+	if limit := limiter.currentLimit(); limit != 1 {
+		t.Errorf("Expected a single success not to grow the limit, got %d", limit)
+	}
+	release(5)
+	if limit := limiter.currentLimit(); limit != 3 {
+		t.Errorf("Expected limit to recover to 3, got %d", limit)
+	}
+}
+
+// TestAdaptiveLimiterCancel tests that waiting for a slot stops when the context is done
+func TestAdaptiveLimiterCancel(t *testing.T) {
+	limiter := newAdaptiveLimiter("test", 1)
+	if err := limiter.acquire(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+package msgraph
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTokenNotFound is returned when no token is stored for a user
+var ErrTokenNotFound = errors.New("no stored token for user")
+
+// ErrInvalidUserSecret is returned when the secret given for a stored token is missing or wrong
+var ErrInvalidUserSecret = errors.New("invalid user secret")
+
+// UserSecretHeader is the request header carrying the user secret that unlocks a stored token
+const UserSecretHeader = "X-User-Secret"
+
+// DefaultTokenRefreshMargin is how long before expiry a stored access token is refreshed
+const DefaultTokenRefreshMargin = 5 * time.Minute
+
+// TokenStore persists encrypted OAuth tokens per user. Stores only ever see sealed tokens.
+type TokenStore interface {
+	// LoadToken returns the sealed token of a user, or nil when the user has no token
+	LoadToken(ctx context.Context, userKey string) ([]byte, error)
+	// SaveToken stores the sealed token of a user, replacing any previous one
+	SaveToken(ctx context.Context, userKey string, sealed []byte) error
+	// DeleteToken removes the token of a user
+	DeleteToken(ctx context.Context, userKey string) error
+}
+
+// StoredToken is the decrypted token set of a user
+type StoredToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Scope        string    `json:"scope"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	SecretHash   string    `json:"secret_hash"` // SHA-256 of the user secret issued at sign-in
+}
+
+// TokenVault stores OAuth tokens per user, encrypted with AES-256-GCM, and refreshes
+// access tokens shortly before they expire
+type TokenVault struct {
+	store         TokenStore
+	aead          cipher.AEAD
+	oauthClient   *OAuthClient
+	refreshMargin time.Duration
+	now           func() time.Time
+	mu            sync.Mutex               // Guards refreshLocks
+	refreshLocks  map[string]chan struct{} // Per user, so a rotated refresh token is only used once
+}
+
+// NewTokenVault creates a vault sealing tokens with a 32-byte key and refreshing them with oauthClient
+func NewTokenVault(store TokenStore, key []byte, oauthClient *OAuthClient) (*TokenVault, error) {
+	if store == nil {
+		return nil, errors.New("token store is required")
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("token encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token cipher: %w", err)
+	}
+
+	return &TokenVault{
+		store:         store,
+		aead:          aead,
+		oauthClient:   oauthClient,
+		refreshMargin: DefaultTokenRefreshMargin,
+		now:           time.Now,
+		refreshLocks:  make(map[string]chan struct{}),
+	}, nil
+}
+
+// ParseTokenKey decodes a 32-byte token encryption key given as base64 or hex
+func ParseTokenKey(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if key, err := base64.StdEncoding.DecodeString(value); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(value); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, errors.New("token encryption key must be 32 bytes encoded as base64 or hex")
+}
+
+// Store saves the tokens of a token endpoint response for a user and issues a new user secret,
+// which AccessToken requires from then on. Only a hash of the secret is kept, so signing in
+// again is the only way to get a new one.
+func (v *TokenVault) Store(ctx context.Context, userKey string, tokens *TokenResponse) (string, error) {
+	if userKey == "" {
+		return "", errors.New("user key is required")
+	}
+	if tokens == nil || tokens.AccessToken == "" {
+		return "", errors.New("access token is required")
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", fmt.Errorf("failed to generate user secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	if err := v.save(ctx, userKey, v.newStoredToken(tokens, hashUserSecret(secret))); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// AccessToken returns a valid access token for a user holding the secret issued by Store,
+// refreshing the stored token first when it expires within the refresh margin. Refreshes
+// of one user are serialised without holding up requests of other users.
+func (v *TokenVault) AccessToken(ctx context.Context, userKey, secret string) (string, error) {
+	token, err := v.loadWithSecret(ctx, userKey, secret)
+	if err != nil {
+		return "", err
+	}
+	if v.fresh(token) {
+		return token.AccessToken, nil
+	}
+
+	unlock, err := v.lockRefresh(ctx, userKey)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Another request may have refreshed the token while this one waited
+	token, err = v.loadWithSecret(ctx, userKey, secret)
+	if err != nil {
+		return "", err
+	}
+	if v.fresh(token) {
+		return token.AccessToken, nil
+	}
+
+	if token.RefreshToken == "" || v.oauthClient == nil {
+		return "", fmt.Errorf("stored token for %s expired and cannot be refreshed", userKey)
+	}
+
+	log.Printf("🔑 Refreshing stored access token for %s", userKey)
+	refreshed, err := v.oauthClient.RefreshAccessTokenContext(ctx, token.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh stored token for %s: %w", userKey, err)
+	}
+
+	// The identity platform may not rotate the refresh token
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	if err := v.save(ctx, userKey, v.newStoredToken(refreshed, token.SecretHash)); err != nil {
+		return "", err
+	}
+	return refreshed.AccessToken, nil
+}
+
+// loadWithSecret loads the stored token of a user and checks the secret issued by Store
+func (v *TokenVault) loadWithSecret(ctx context.Context, userKey, secret string) (*StoredToken, error) {
+	if secret == "" {
+		return nil, ErrInvalidUserSecret
+	}
+	token, err := v.load(ctx, userKey)
+	if err != nil {
+		return nil, err
+	}
+	if token.SecretHash == "" || subtle.ConstantTimeCompare([]byte(hashUserSecret(secret)), []byte(token.SecretHash)) != 1 {
+		return nil, ErrInvalidUserSecret
+	}
+	return token, nil
+}
+
+// fresh reports whether a stored access token is valid beyond the refresh margin
+func (v *TokenVault) fresh(token *StoredToken) bool {
+	return v.now().Add(v.refreshMargin).Before(token.ExpiresAt)
+}
+
+// lockRefresh waits until no other refresh of the user runs, or for ctx to be done,
+// and returns the function releasing the lock
+func (v *TokenVault) lockRefresh(ctx context.Context, userKey string) (func(), error) {
+	v.mu.Lock()
+	lock, ok := v.refreshLocks[userKey]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		v.refreshLocks[userKey] = lock
+	}
+	v.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Delete removes the stored token of a user
+func (v *TokenVault) Delete(ctx context.Context, userKey string) error {
+	return v.store.DeleteToken(ctx, userKey)
+}
+
+// newStoredToken converts a token endpoint response into a stored token
+func (v *TokenVault) newStoredToken(tokens *TokenResponse, secretHash string) *StoredToken {
+	now := v.now()
+	return &StoredToken{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		Scope:        tokens.Scope,
+		ExpiresAt:    now.Add(time.Duration(tokens.ExpiresIn) * time.Second),
+		UpdatedAt:    now,
+		SecretHash:   secretHash,
+	}
+}
+
+// hashUserSecret returns the hex SHA-256 of a user secret. The secret is random, so an
+// unsalted hash is enough.
+func hashUserSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// save seals and stores a token. The user key is authenticated with the token so that
+// a sealed token cannot be moved to another user.
+func (v *TokenVault) save(ctx context.Context, userKey string, token *StoredToken) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate token nonce: %w", err)
+	}
+	sealed := v.aead.Seal(nonce, nonce, plaintext, []byte(userKey))
+
+	if err := v.store.SaveToken(ctx, userKey, sealed); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+	return nil
+}
+
+// load reads and opens the stored token of a user
+func (v *TokenVault) load(ctx context.Context, userKey string) (*StoredToken, error) {
+	sealed, err := v.store.LoadToken(ctx, userKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token: %w", err)
+	}
+	if sealed == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	nonceSize := v.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("stored token is corrupt")
+	}
+	plaintext, err := v.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(userKey))
+	if err != nil {
+		return nil, errors.New("failed to decrypt stored token (wrong key or corrupt token)")
+	}
+
+	var token StoredToken
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode stored token: %w", err)
+	}
+	return &token, nil
+}
+
+// MemoryTokenStore keeps sealed tokens in memory for the lifetime of the process
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string][]byte
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string][]byte)}
+}
+
+// LoadToken returns the sealed token of a user
+func (s *MemoryTokenStore) LoadToken(ctx context.Context, userKey string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sealed, ok := s.tokens[userKey]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), sealed...), nil
+}
+
+// SaveToken stores a copy of the sealed token of a user
+func (s *MemoryTokenStore) SaveToken(ctx context.Context, userKey string, sealed []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[userKey] = append([]byte(nil), sealed...)
+	return nil
+}
+
+// DeleteToken removes the token of a user
+func (s *MemoryTokenStore) DeleteToken(ctx context.Context, userKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, userKey)
+	return nil
+}
+
+// FileTokenStore keeps one sealed token file per user in a directory
+type FileTokenStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileTokenStore creates a token store in dir, creating the directory if needed
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create token directory: %w", err)
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+// path returns the token file of a user
+func (s *FileTokenStore) path(userKey string) string {
+	return filepath.Join(s.dir, "token-"+url.PathEscape(userKey)+".bin")
+}
+
+// LoadToken reads the token file of a user
+func (s *FileTokenStore) LoadToken(ctx context.Context, userKey string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed, err := os.ReadFile(s.path(userKey))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	return sealed, nil
+}
+
+// SaveToken writes the token file of a user, replacing it atomically
+func (s *FileTokenStore) SaveToken(ctx context.Context, userKey string, sealed []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(userKey)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0o600); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	return nil
+}
+
+// DeleteToken removes the token file of a user
+func (s *FileTokenStore) DeleteToken(ctx context.Context, userKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(userKey)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
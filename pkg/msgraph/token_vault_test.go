@@ -0,0 +1,237 @@
+package msgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ishank09/data-extraction-service/pkg/msgraph/msgraphtest"
+)
+
+var testTokenKey = bytes.Repeat([]byte{7}, 32)
+
+// TestTokenVaultStoreAndLoad tests that tokens are sealed at rest and returned while fresh
+func TestTokenVaultStoreAndLoad(t *testing.T) {
+	store := NewMemoryTokenStore()
+	vault, err := NewTokenVault(store, testTokenKey, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	secret, err := vault.Store(ctx, "user-1", &TokenResponse{AccessToken: "access-secret", RefreshToken: "refresh-secret", ExpiresIn: 3600})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sealed, _ := store.LoadToken(ctx, "user-1")
+	if bytes.Contains(sealed, []byte("access-secret")) || bytes.Contains(sealed, []byte("refresh-secret")) {
+		t.Error("Expected tokens to be encrypted at rest")
+	}
+
+	token, err := vault.AccessToken(ctx, "user-1", secret)
+	if err != nil || token != "access-secret" {
+		t.Errorf("Expected stored access token, got %q (%v)", token, err)
+	}
+
+	if _, err := vault.AccessToken(ctx, "user-2", secret); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Expected ErrTokenNotFound, got %v", err)
+	}
+
+	// A sealed token is bound to its user
+	_ = store.SaveToken(ctx, "user-2", sealed)
+	if _, err := vault.AccessToken(ctx, "user-2", secret); err == nil {
+		t.Error("Expected a token moved to another user to be rejected")
+	}
+
+	// A different key cannot open the token
+	otherVault, _ := NewTokenVault(store, bytes.Repeat([]byte{8}, 32), nil)
+	if _, err := otherVault.AccessToken(ctx, "user-1", secret); err == nil {
+		t.Error("Expected a different key to be rejected")
+	}
+}
+
+// TestTokenVaultUserSecret tests that stored tokens are only returned for the secret issued at sign-in
+func TestTokenVaultUserSecret(t *testing.T) {
+	vault, _ := NewTokenVault(NewMemoryTokenStore(), testTokenKey, nil)
+	ctx := context.Background()
+
+	first, err := vault.Store(ctx, "user-1", &TokenResponse{AccessToken: "access", ExpiresIn: 3600})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(first) < 40 {
+		t.Errorf("Expected a random secret of at least 32 bytes, got %q", first)
+	}
+
+	for name, secret := range map[string]string{"missing": "", "wrong": first + "x"} {
+		if _, err := vault.AccessToken(ctx, "user-1", secret); !errors.Is(err, ErrInvalidUserSecret) {
+			t.Errorf("Expected ErrInvalidUserSecret for a %s secret, got %v", name, err)
+		}
+	}
+
+	// Signing in again replaces the secret
+	second, _ := vault.Store(ctx, "user-1", &TokenResponse{AccessToken: "access", ExpiresIn: 3600})
+	if _, err := vault.AccessToken(ctx, "user-1", first); !errors.Is(err, ErrInvalidUserSecret) {
+		t.Errorf("Expected the previous secret to be rejected, got %v", err)
+	}
+	if _, err := vault.AccessToken(ctx, "user-1", second); err != nil {
+		t.Errorf("Expected the new secret to be accepted, got %v", err)
+	}
+}
+
+// TestTokenVaultRefresh tests refreshing a token that expires within the refresh margin
+func TestTokenVaultRefresh(t *testing.T) {
+	server := msgraphtest.NewServer()
+	defer server.Close()
+
+	oauthClient := NewOAuthClient(OAuthConfig{
+		ClientID:      msgraphtest.ClientID,
+		ClientSecret:  msgraphtest.ClientSecret,
+		LoginEndpoint: server.LoginURL(),
+		GraphEndpoint: server.GraphURL(),
+	})
+	tokens, err := oauthClient.ExchangeCode(msgraphtest.AuthorizationCode)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	vault, _ := NewTokenVault(NewMemoryTokenStore(), testTokenKey, oauthClient)
+	ctx := context.Background()
+	tokens.ExpiresIn = 60 // Within the refresh margin
+	secret, err := vault.Store(ctx, "user-1", tokens)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	refreshed, err := vault.AccessToken(ctx, "user-1", secret)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if refreshed == tokens.AccessToken {
+		t.Error("Expected the access token to be refreshed")
+	}
+	if err := oauthClient.TestToken(refreshed); err != nil {
+		t.Errorf("Expected refreshed token to be valid: %v", err)
+	}
+
+	// The refreshed token is stored under the same secret and reused until it nears expiry
+	again, err := vault.AccessToken(ctx, "user-1", secret)
+	if err != nil || again != refreshed {
+		t.Errorf("Expected the stored refreshed token, got %q (%v)", again, err)
+	}
+}
+
+// TestTokenVaultRefreshStalled tests that a stalled refresh gives up with the caller's context
+func TestTokenVaultRefreshStalled(t *testing.T) {
+	unblock := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer stalled.Close()
+	defer close(unblock)
+
+	oauthClient := NewOAuthClient(OAuthConfig{ClientID: msgraphtest.ClientID, LoginEndpoint: stalled.URL})
+	vault, _ := NewTokenVault(NewMemoryTokenStore(), testTokenKey, oauthClient)
+	secret, _ := vault.Store(context.Background(), "user-1", &TokenResponse{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 60})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := vault.AccessToken(ctx, "user-1", secret); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// TestTokenVaultRefreshPerUser tests that a stalled refresh only holds up requests of the same user
+func TestTokenVaultRefreshPerUser(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested <- struct{}{}
+		<-unblock
+	}))
+	defer stalled.Close()
+	defer close(unblock)
+
+	oauthClient := NewOAuthClient(OAuthConfig{ClientID: msgraphtest.ClientID, LoginEndpoint: stalled.URL})
+	vault, _ := NewTokenVault(NewMemoryTokenStore(), testTokenKey, oauthClient)
+	expiringSecret, _ := vault.Store(context.Background(), "user-1", &TokenResponse{AccessToken: "access-1", RefreshToken: "refresh", ExpiresIn: 60})
+	freshSecret, _ := vault.Store(context.Background(), "user-2", &TokenResponse{AccessToken: "access-2", ExpiresIn: 3600})
+
+	refreshCtx, cancelRefresh := context.WithCancel(context.Background())
+	defer cancelRefresh()
+	go vault.AccessToken(refreshCtx, "user-1", expiringSecret)
+	<-requested
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if token, err := vault.AccessToken(ctx, "user-2", freshSecret); err != nil || token != "access-2" {
+		t.Errorf("Expected the fresh token of another user, got %q (%v)", token, err)
+	}
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelWait()
+	if _, err := vault.AccessToken(waitCtx, "user-1", expiringSecret); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the same user to wait for the running refresh, got %v", err)
+	}
+	if _, err := vault.AccessToken(waitCtx, "user-1", "wrong-secret"); !errors.Is(err, ErrInvalidUserSecret) {
+		t.Errorf("Expected a wrong secret to be rejected without waiting, got %v", err)
+	}
+}
+
+// TestTokenVaultExpiredWithoutRefreshToken tests that an expired token without refresh token fails
+func TestTokenVaultExpiredWithoutRefreshToken(t *testing.T) {
+	vault, _ := NewTokenVault(NewMemoryTokenStore(), testTokenKey, nil)
+	ctx := context.Background()
+	vault.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	secret, _ := vault.Store(ctx, "user-1", &TokenResponse{AccessToken: "access", ExpiresIn: 3600})
+	vault.now = time.Now
+
+	if _, err := vault.AccessToken(ctx, "user-1", secret); err == nil || !strings.Contains(err.Error(), "cannot be refreshed") {
+		t.Errorf("Expected an expired token error, got %v", err)
+	}
+}
+
+// TestFileTokenStore tests persisting sealed tokens in files
+func TestFileTokenStore(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	if sealed, err := store.LoadToken(ctx, "user/1"); sealed != nil || err != nil {
+		t.Errorf("Expected no token, got %v (%v)", sealed, err)
+	}
+	if err := store.SaveToken(ctx, "user/1", []byte("sealed")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sealed, _ := store.LoadToken(ctx, "user/1"); string(sealed) != "sealed" {
+		t.Errorf("Expected stored token, got %q", sealed)
+	}
+	if err := store.DeleteToken(ctx, "user/1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sealed, _ := store.LoadToken(ctx, "user/1"); sealed != nil {
+		t.Error("Expected token to be deleted")
+	}
+}
+
+// TestParseTokenKey tests decoding keys given as base64 or hex
+func TestParseTokenKey(t *testing.T) {
+	if key, err := ParseTokenKey(base64.StdEncoding.EncodeToString(testTokenKey)); err != nil || !bytes.Equal(key, testTokenKey) {
+		t.Errorf("Expected base64 key to decode, got %v", err)
+	}
+	if key, err := ParseTokenKey(strings.Repeat("07", 32)); err != nil || !bytes.Equal(key, testTokenKey) {
+		t.Errorf("Expected hex key to decode, got %v", err)
+	}
+	if _, err := ParseTokenKey("too-short"); err == nil {
+		t.Error("Expected a short key to be rejected")
+	}
+}
@@ -3,60 +3,57 @@ package static
 import (
 	"context"
 	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
 
 	"github.com/ishank09/data-extraction-service/internal/types"
-	"github.com/ishank09/data-extraction-service/pkg/static/csv"
-	"github.com/ishank09/data-extraction-service/pkg/static/html"
-	"github.com/ishank09/data-extraction-service/pkg/static/json"
-	"github.com/ishank09/data-extraction-service/pkg/static/pdf"
-	"github.com/ishank09/data-extraction-service/pkg/static/txt"
-	"github.com/ishank09/data-extraction-service/pkg/static/xml"
 )
 
 // FileProcessor interface for all file type processors
 type FileProcessor interface {
 	GetDocuments(ctx context.Context) ([]types.Document, error)
 	ListFiles(ctx context.Context) ([]string, error)
+	ProcessFile(ctx context.Context, filePath string, content []byte) (*types.Document, error)
 }
 
 // Client handles static file operations
 type Client struct {
-	csvProcessor  *csv.Processor
-	jsonProcessor *json.Processor
-	txtProcessor  *txt.Processor
-	pdfProcessor  *pdf.Processor
-	xmlProcessor  *xml.Processor
-	htmlProcessor *html.Processor
+	registrations []Registration
+	processors    map[string]FileProcessor // Keyed by registration name
+	byExtension   map[string]string        // Extension to registration name
+	byMIMEType    map[string]string        // MIME type to registration name
 }
 
-// NewClient creates a new static file client
+// NewClient creates a new static file client using every registered processor
 func NewClient() *Client {
-	return &Client{
-		csvProcessor:  csv.NewProcessor(),
-		jsonProcessor: json.NewProcessor(),
-		txtProcessor:  txt.NewProcessor(),
-		pdfProcessor:  pdf.NewProcessor(),
-		xmlProcessor:  xml.NewProcessor(),
-		htmlProcessor: html.NewProcessor(),
+	client := &Client{
+		registrations: Registrations(),
+		processors:    make(map[string]FileProcessor),
+		byExtension:   make(map[string]string),
+		byMIMEType:    make(map[string]string),
 	}
+
+	for _, registration := range client.registrations {
+		client.processors[registration.Name] = registration.New()
+		for _, ext := range registration.Extensions {
+			client.byExtension[ext] = registration.Name
+		}
+		for _, mimeType := range registration.MIMETypes {
+			client.byMIMEType[mimeType] = registration.Name
+		}
+	}
+
+	return client
 }
 
 // GetAllDataAsJSON returns all embedded files as JSON documents
 func (c *Client) GetAllDataAsJSON(ctx context.Context) (*types.DocumentCollection, error) {
 	collection := types.NewDocumentCollection("static_files")
 
-	// Get documents from all processors
-	processors := []FileProcessor{
-		c.csvProcessor,
-		c.jsonProcessor,
-		c.txtProcessor,
-		c.pdfProcessor,
-		c.xmlProcessor,
-		c.htmlProcessor,
-	}
-
-	for _, processor := range processors {
-		docs, err := processor.GetDocuments(ctx)
+	// Get documents from all processors in registration order
+	for _, registration := range c.registrations {
+		docs, err := c.processors[registration.Name].GetDocuments(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get documents: %w", err)
 		}
@@ -71,23 +68,9 @@ func (c *Client) GetAllDataAsJSON(ctx context.Context) (*types.DocumentCollectio
 
 // GetFilesByType returns documents for a specific file type
 func (c *Client) GetFilesByType(ctx context.Context, fileType string) ([]types.Document, error) {
-	var processor FileProcessor
-
-	switch fileType {
-	case "csv":
-		processor = c.csvProcessor
-	case "json":
-		processor = c.jsonProcessor
-	case "txt":
-		processor = c.txtProcessor
-	case "pdf":
-		processor = c.pdfProcessor
-	case "xml":
-		processor = c.xmlProcessor
-	case "html":
-		processor = c.htmlProcessor
-	default:
-		return nil, fmt.Errorf("unsupported file type: %s", fileType)
+	processor, err := c.getProcessor(fileType)
+	if err != nil {
+		return nil, err
 	}
 
 	return processor.GetDocuments(ctx)
@@ -95,23 +78,9 @@ func (c *Client) GetFilesByType(ctx context.Context, fileType string) ([]types.D
 
 // ListFilesByType returns filenames for a specific file type
 func (c *Client) ListFilesByType(ctx context.Context, fileType string) ([]string, error) {
-	var processor FileProcessor
-
-	switch fileType {
-	case "csv":
-		processor = c.csvProcessor
-	case "json":
-		processor = c.jsonProcessor
-	case "txt":
-		processor = c.txtProcessor
-	case "pdf":
-		processor = c.pdfProcessor
-	case "xml":
-		processor = c.xmlProcessor
-	case "html":
-		processor = c.htmlProcessor
-	default:
-		return nil, fmt.Errorf("unsupported file type: %s", fileType)
+	processor, err := c.getProcessor(fileType)
+	if err != nil {
+		return nil, err
 	}
 
 	return processor.ListFiles(ctx)
@@ -119,5 +88,49 @@ func (c *Client) ListFilesByType(ctx context.Context, fileType string) ([]string
 
 // GetSupportedFileTypes returns list of supported file types
 func (c *Client) GetSupportedFileTypes() []string {
-	return []string{"csv", "json", "txt", "pdf", "xml", "html"}
+	fileTypes := make([]string, 0, len(c.registrations))
+	for _, registration := range c.registrations {
+		fileTypes = append(fileTypes, registration.Name)
+	}
+	return fileTypes
+}
+
+// GetRegistrations returns the processors known to this client
+func (c *Client) GetRegistrations() []Registration {
+	return c.registrations
+}
+
+// DetectFileType resolves the supported file type for a file by extension, falling back to its MIME type
+func (c *Client) DetectFileType(filename, contentType string) (string, error) {
+	if fileType, ok := c.byExtension[strings.ToLower(filepath.Ext(filename))]; ok {
+		return fileType, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		if fileType, ok := c.byMIMEType[mediaType]; ok {
+			return fileType, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported file: %s", filename)
+}
+
+// ProcessFile converts a file supplied at runtime to a document using the matching processor
+func (c *Client) ProcessFile(ctx context.Context, filename, contentType string, content []byte) (*types.Document, error) {
+	fileType, err := c.DetectFileType(filename, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.processors[fileType].ProcessFile(ctx, filename, content)
+}
+
+// getProcessor returns the processor registered for a file type
+func (c *Client) getProcessor(fileType string) (FileProcessor, error) {
+	processor, ok := c.processors[fileType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file type: %s", fileType)
+	}
+	return processor, nil
 }
@@ -241,3 +241,80 @@ func TestClient_AllSupportedFileTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_DetectFileType(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		filename    string
+		contentType string
+		expected    string
+		wantErr     bool
+	}{
+		{filename: "report.CSV", expected: "csv"},
+		{filename: "page.htm", expected: "html"},
+		{filename: "notes", contentType: "text/plain; charset=utf-8", expected: "txt"},
+		{filename: "upload", contentType: "application/pdf", expected: "pdf"},
+		{filename: "archive.zip", contentType: "application/zip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		fileType, err := client.DetectFileType(tt.filename, tt.contentType)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("DetectFileType(%q, %q) should return an error", tt.filename, tt.contentType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("DetectFileType(%q, %q) error = %v", tt.filename, tt.contentType, err)
+		}
+		if fileType != tt.expected {
+			t.Errorf("DetectFileType(%q, %q) = %q, expected %q", tt.filename, tt.contentType, fileType, tt.expected)
+		}
+	}
+}
+
+func TestClient_ProcessFile(t *testing.T) {
+	client := NewClient()
+	ctx := context.Background()
+
+	doc, err := client.ProcessFile(ctx, "notes.txt", "", []byte("hello runtime upload"))
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if doc.Type != "txt" {
+		t.Errorf("Expected document type 'txt', got '%s'", doc.Type)
+	}
+	if doc.Title != "notes.txt" {
+		t.Errorf("Expected document title 'notes.txt', got '%s'", doc.Title)
+	}
+	if doc.Content != "hello runtime upload" {
+		t.Errorf("Expected content to be preserved, got '%s'", doc.Content)
+	}
+
+	// Same path and content produce the same ID and version hash
+	same, err := client.ProcessFile(ctx, "notes.txt", "", []byte("hello runtime upload"))
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if same.ID != doc.ID || same.VersionHash != doc.VersionHash {
+		t.Errorf("Expected stable identity, got ID %s/%s and hash %s/%s", doc.ID, same.ID, doc.VersionHash, same.VersionHash)
+	}
+
+	// Changed content keeps the ID but changes the version hash
+	changed, err := client.ProcessFile(ctx, "notes.txt", "", []byte("hello edited upload"))
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if changed.ID != doc.ID {
+		t.Errorf("Expected ID '%s' to survive a content change, got '%s'", doc.ID, changed.ID)
+	}
+	if changed.VersionHash == doc.VersionHash {
+		t.Error("Expected version hash to change with content")
+	}
+
+	if _, err := client.ProcessFile(ctx, "binary.exe", "application/octet-stream", []byte{0x4d, 0x5a}); err == nil {
+		t.Error("ProcessFile() should return an error for unsupported files")
+	}
+}
@@ -78,6 +78,11 @@ func (p *Processor) ListFiles(ctx context.Context) ([]string, error) {
 	return files, err
 }
 
+// ProcessFile converts a CSV file supplied at runtime (e.g. an upload) to a document
+func (p *Processor) ProcessFile(ctx context.Context, filePath string, content []byte) (*types.Document, error) {
+	return p.processFile(filePath, content)
+}
+
 // processFile converts a CSV file to a document using utils functions
 func (p *Processor) processFile(filePath string, content []byte) (*types.Document, error) {
 	filename := filepath.Base(filePath)
@@ -89,14 +94,15 @@ func (p *Processor) processFile(filePath string, content []byte) (*types.Documen
 	}
 
 	return &types.Document{
-		ID:        fmt.Sprintf("csv_%s_%d", strings.TrimSuffix(filename, ".csv"), time.Now().UnixNano()),
-		Type:      "csv",
-		Title:     filename,
-		Content:   string(content),
-		Source:    "embedded",
-		Location:  filePath,
-		CreatedAt: time.Now(),
-		FetchedAt: time.Now(),
+		ID:          utils.GenerateDocumentID("csv", "embedded", filePath),
+		Type:        "csv",
+		Title:       filename,
+		Content:     string(content),
+		Source:      "embedded",
+		Location:    filePath,
+		CreatedAt:   time.Now(),
+		FetchedAt:   time.Now(),
+		VersionHash: utils.GenerateVersionHash(content),
 		Metadata: map[string]interface{}{
 			"filename":      filename,
 			"file_type":     "csv",
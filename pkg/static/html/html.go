@@ -80,6 +80,11 @@ func (p *Processor) ListFiles(ctx context.Context) ([]string, error) {
 	return files, err
 }
 
+// ProcessFile converts an HTML file supplied at runtime (e.g. an upload) to a document
+func (p *Processor) ProcessFile(ctx context.Context, filePath string, content []byte) (*types.Document, error) {
+	return p.processFile(filePath, content)
+}
+
 // processFile converts an HTML file to a document using utils functions
 func (p *Processor) processFile(filePath string, content []byte) (*types.Document, error) {
 	filename := filepath.Base(filePath)
@@ -90,20 +95,25 @@ func (p *Processor) processFile(filePath string, content []byte) (*types.Documen
 		return nil, fmt.Errorf("failed to convert content to JSON: %w", err)
 	}
 
+	// Store readable text rather than markup so documents are searchable
+	parsed := utils.ParseHTML(string(content))
+
 	return &types.Document{
-		ID:        fmt.Sprintf("html_%s_%d", strings.TrimSuffix(filename, filepath.Ext(filename)), time.Now().UnixNano()),
-		Type:      "html",
-		Title:     filename,
-		Content:   string(content),
-		Source:    "embedded",
-		Location:  filePath,
-		CreatedAt: time.Now(),
-		FetchedAt: time.Now(),
+		ID:          utils.GenerateDocumentID("html", "embedded", filePath),
+		Type:        "html",
+		Title:       filename,
+		Content:     parsed.Text,
+		Source:      "embedded",
+		Location:    filePath,
+		CreatedAt:   time.Now(),
+		FetchedAt:   time.Now(),
+		VersionHash: utils.GenerateVersionHash(content),
 		Metadata: map[string]interface{}{
 			"filename":      filename,
 			"file_type":     "html",
 			"file_size":     len(content),
 			"embedded_path": filePath,
+			"links":         parsed.Links,
 			"parsed_data":   contentJSON,
 		},
 	}, nil
@@ -3,6 +3,8 @@ package html
 import (
 	"context"
 	"testing"
+
+	"github.com/ishank09/data-extraction-service/internal/utils"
 )
 
 func TestHTMLProcessor_GetDocuments_EmptyDirectory(t *testing.T) {
@@ -43,3 +45,23 @@ func TestNewProcessor(t *testing.T) {
 		t.Error("NewProcessor() should not return nil")
 	}
 }
+
+func TestHTMLProcessor_ProcessFile_ExtractsText(t *testing.T) {
+	processor := NewProcessor()
+	content := []byte(`<html><head><style>p { color: red; }</style></head><body>` +
+		`<p>First <a href="https://example.com/docs">docs</a></p><script>alert("x")</script><p>Second</p></body></html>`)
+
+	doc, err := processor.ProcessFile(context.Background(), "uploads/page.html", content)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+
+	if expected := "First docs\n\nSecond"; doc.Content != expected {
+		t.Errorf("Expected content %q, got %q", expected, doc.Content)
+	}
+
+	links, ok := doc.Metadata["links"].([]utils.HTMLLink)
+	if !ok || len(links) != 1 || links[0].URL != "https://example.com/docs" {
+		t.Errorf("Expected a single link to https://example.com/docs, got %v", doc.Metadata["links"])
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/gen2brain/go-fitz"
 	"github.com/ishank09/data-extraction-service/internal/types"
+	"github.com/ishank09/data-extraction-service/internal/utils"
 )
 
 //go:embed files/*
@@ -79,6 +80,11 @@ func (p *Processor) ListFiles(ctx context.Context) ([]string, error) {
 	return files, err
 }
 
+// ProcessFile converts a PDF file supplied at runtime (e.g. an upload) to a document
+func (p *Processor) ProcessFile(ctx context.Context, filePath string, content []byte) (*types.Document, error) {
+	return p.processFile(filePath, content)
+}
+
 // extractTextFromPDF extracts text content from PDF binary data using go-fitz
 func (p *Processor) extractTextFromPDF(pdfData []byte) (string, error) {
 	if len(pdfData) == 0 {
@@ -144,14 +150,15 @@ func (p *Processor) processFile(filePath string, content []byte) (*types.Documen
 	if err != nil {
 		// For extraction errors, provide metadata only
 		return &types.Document{
-			ID:        fmt.Sprintf("pdf_%s_%d", strings.TrimSuffix(filename, ".pdf"), time.Now().UnixNano()),
-			Type:      "pdf",
-			Title:     filename,
-			Content:   fmt.Sprintf("PDF extraction failed: %v", err),
-			Source:    "embedded",
-			Location:  filePath,
-			CreatedAt: time.Now(),
-			FetchedAt: time.Now(),
+			ID:          utils.GenerateDocumentID("pdf", "embedded", filePath),
+			Type:        "pdf",
+			Title:       filename,
+			Content:     fmt.Sprintf("PDF extraction failed: %v", err),
+			Source:      "embedded",
+			Location:    filePath,
+			CreatedAt:   time.Now(),
+			FetchedAt:   time.Now(),
+			VersionHash: utils.GenerateVersionHash(content),
 			Metadata: map[string]interface{}{
 				"filename":         filename,
 				"file_type":        "pdf",
@@ -161,14 +168,15 @@ func (p *Processor) processFile(filePath string, content []byte) (*types.Documen
 	}
 
 	return &types.Document{
-		ID:        fmt.Sprintf("pdf_%s_%d", strings.TrimSuffix(filename, ".pdf"), time.Now().UnixNano()),
-		Type:      "pdf",
-		Title:     filename,
-		Content:   extractedText,
-		Source:    "embedded",
-		Location:  filePath,
-		CreatedAt: time.Now(),
-		FetchedAt: time.Now(),
+		ID:          utils.GenerateDocumentID("pdf", "embedded", filePath),
+		Type:        "pdf",
+		Title:       filename,
+		Content:     extractedText,
+		Source:      "embedded",
+		Location:    filePath,
+		CreatedAt:   time.Now(),
+		FetchedAt:   time.Now(),
+		VersionHash: utils.GenerateVersionHash(content),
 		Metadata: map[string]interface{}{
 			"filename":   filename,
 			"file_type":  "pdf",
@@ -0,0 +1,147 @@
+package static
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ishank09/data-extraction-service/pkg/static/csv"
+	"github.com/ishank09/data-extraction-service/pkg/static/html"
+	"github.com/ishank09/data-extraction-service/pkg/static/json"
+	"github.com/ishank09/data-extraction-service/pkg/static/pdf"
+	"github.com/ishank09/data-extraction-service/pkg/static/txt"
+	"github.com/ishank09/data-extraction-service/pkg/static/xml"
+)
+
+// Registration describes a file processor and the files it handles
+type Registration struct {
+	Name       string               // File type name used in APIs, e.g. "csv"
+	Extensions []string             // File extensions including the dot, e.g. ".csv"
+	MIMETypes  []string             // MIME types used when the extension is not recognised
+	New        func() FileProcessor // Constructor invoked for every new Client
+}
+
+var (
+	registryMutex sync.RWMutex
+	registrations []Registration
+)
+
+func init() {
+	builtins := []Registration{
+		{Name: "csv", Extensions: []string{".csv"}, MIMETypes: []string{"text/csv"}, New: func() FileProcessor { return csv.NewProcessor() }},
+		{Name: "json", Extensions: []string{".json"}, MIMETypes: []string{"application/json"}, New: func() FileProcessor { return json.NewProcessor() }},
+		{Name: "txt", Extensions: []string{".txt"}, MIMETypes: []string{"text/plain"}, New: func() FileProcessor { return txt.NewProcessor() }},
+		{Name: "pdf", Extensions: []string{".pdf"}, MIMETypes: []string{"application/pdf"}, New: func() FileProcessor { return pdf.NewProcessor() }},
+		{Name: "xml", Extensions: []string{".xml"}, MIMETypes: []string{"application/xml", "text/xml"}, New: func() FileProcessor { return xml.NewProcessor() }},
+		{Name: "html", Extensions: []string{".html", ".htm"}, MIMETypes: []string{"text/html"}, New: func() FileProcessor { return html.NewProcessor() }},
+	}
+
+	for _, registration := range builtins {
+		MustRegister(registration)
+	}
+}
+
+// Register adds a file processor to the registry. Clients created afterwards
+// discover it automatically. Names, extensions and MIME types must be unique.
+func Register(registration Registration) error {
+	registration.Name = strings.ToLower(strings.TrimSpace(registration.Name))
+	if registration.Name == "" {
+		return fmt.Errorf("processor name is required")
+	}
+	if registration.New == nil {
+		return fmt.Errorf("processor %s has no constructor", registration.Name)
+	}
+	if len(registration.Extensions) == 0 {
+		return fmt.Errorf("processor %s must handle at least one extension", registration.Name)
+	}
+
+	registration.Extensions = normalizeExtensions(registration.Extensions)
+	registration.MIMETypes = normalizeMIMETypes(registration.MIMETypes)
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, existing := range registrations {
+		if existing.Name == registration.Name {
+			return fmt.Errorf("processor %s is already registered", registration.Name)
+		}
+		for _, ext := range registration.Extensions {
+			if containsString(existing.Extensions, ext) {
+				return fmt.Errorf("extension %s is already handled by processor %s", ext, existing.Name)
+			}
+		}
+		for _, mimeType := range registration.MIMETypes {
+			if containsString(existing.MIMETypes, mimeType) {
+				return fmt.Errorf("MIME type %s is already handled by processor %s", mimeType, existing.Name)
+			}
+		}
+	}
+
+	registrations = append(registrations, registration)
+	return nil
+}
+
+// MustRegister is like Register but panics on error, for use in init functions
+func MustRegister(registration Registration) {
+	if err := Register(registration); err != nil {
+		panic(err)
+	}
+}
+
+// Registrations returns a snapshot of all registered processors in registration order
+func Registrations() []Registration {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	snapshot := make([]Registration, len(registrations))
+	copy(snapshot, registrations)
+	return snapshot
+}
+
+// unregister removes the named processor from the registry so tests can restore it
+func unregister(name string) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, registration := range registrations {
+		if registration.Name == name {
+			registrations = append(registrations[:i:i], registrations[i+1:]...)
+			return
+		}
+	}
+}
+
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
+
+func normalizeMIMETypes(mimeTypes []string) []string {
+	normalized := make([]string, 0, len(mimeTypes))
+	for _, mimeType := range mimeTypes {
+		if mimeType = strings.ToLower(strings.TrimSpace(mimeType)); mimeType != "" {
+			normalized = append(normalized, mimeType)
+		}
+	}
+	return normalized
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package static
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ishank09/data-extraction-service/internal/types"
+)
+
+// markdownProcessor is a minimal external processor used to exercise the registry
+type markdownProcessor struct{}
+
+func (p *markdownProcessor) GetDocuments(ctx context.Context) ([]types.Document, error) {
+	return []types.Document{{ID: "md_readme", Type: "markdown", Title: "README.md"}}, nil
+}
+
+func (p *markdownProcessor) ListFiles(ctx context.Context) ([]string, error) {
+	return []string{"README.md"}, nil
+}
+
+func (p *markdownProcessor) ProcessFile(ctx context.Context, filePath string, content []byte) (*types.Document, error) {
+	return &types.Document{Type: "markdown", Title: filePath, Content: string(content)}, nil
+}
+
+func TestRegistrations_Builtins(t *testing.T) {
+	names := map[string]bool{}
+	for _, registration := range Registrations() {
+		names[registration.Name] = true
+	}
+
+	for _, expected := range []string{"csv", "json", "txt", "pdf", "xml", "html"} {
+		if !names[expected] {
+			t.Errorf("Expected built-in processor '%s' to be registered", expected)
+		}
+	}
+}
+
+func TestRegister_CustomProcessor(t *testing.T) {
+	err := Register(Registration{
+		Name:       "Markdown",
+		Extensions: []string{"md", ".MARKDOWN"},
+		MIMETypes:  []string{"text/markdown"},
+		New:        func() FileProcessor { return &markdownProcessor{} },
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	t.Cleanup(func() { unregister("markdown") })
+
+	client := NewClient()
+	ctx := context.Background()
+
+	found := false
+	for _, fileType := range client.GetSupportedFileTypes() {
+		if fileType == "markdown" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected registered processor to appear in GetSupportedFileTypes()")
+	}
+
+	docs, err := client.GetFilesByType(ctx, "markdown")
+	if err != nil {
+		t.Fatalf("GetFilesByType('markdown') error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Errorf("Expected 1 markdown document, got %d", len(docs))
+	}
+
+	for _, filename := range []string{"notes.md", "notes.markdown"} {
+		fileType, err := client.DetectFileType(filename, "")
+		if err != nil || fileType != "markdown" {
+			t.Errorf("DetectFileType(%q) = %q, %v; expected 'markdown'", filename, fileType, err)
+		}
+	}
+
+	doc, err := client.ProcessFile(ctx, "upload", "text/markdown", []byte("# Title"))
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if doc.Type != "markdown" {
+		t.Errorf("Expected document type 'markdown', got '%s'", doc.Type)
+	}
+}
+
+func TestRegister_Validation(t *testing.T) {
+	newProcessor := func() FileProcessor { return &markdownProcessor{} }
+
+	tests := []struct {
+		name         string
+		registration Registration
+	}{
+		{
+			name:         "rejects missing name",
+			registration: Registration{Extensions: []string{".x1"}, New: newProcessor},
+		},
+		{
+			name:         "rejects missing constructor",
+			registration: Registration{Name: "x2", Extensions: []string{".x2"}},
+		},
+		{
+			name:         "rejects missing extensions",
+			registration: Registration{Name: "x3", New: newProcessor},
+		},
+		{
+			name:         "rejects duplicate name",
+			registration: Registration{Name: "csv", Extensions: []string{".x4"}, New: newProcessor},
+		},
+		{
+			name:         "rejects duplicate extension",
+			registration: Registration{Name: "x5", Extensions: []string{".csv"}, New: newProcessor},
+		},
+		{
+			name:         "rejects duplicate MIME type",
+			registration: Registration{Name: "x6", Extensions: []string{".x6"}, MIMETypes: []string{"application/pdf"}, New: newProcessor},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Register(tt.registration); err == nil {
+				unregister(tt.registration.Name)
+				t.Error("Register() should return an error")
+			}
+		})
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	before := len(Registrations())
+
+	if err := Register(Registration{Name: "scratch", Extensions: []string{".scratch"}, New: func() FileProcessor { return &markdownProcessor{} }}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	unregister("scratch")
+
+	if after := len(Registrations()); after != before {
+		t.Errorf("Expected %d registrations after unregister, got %d", before, after)
+	}
+	if err := Register(Registration{Name: "scratch", Extensions: []string{".scratch"}, New: func() FileProcessor { return &markdownProcessor{} }}); err != nil {
+		t.Errorf("Expected an unregistered name to be available again, got %v", err)
+	}
+	unregister("scratch")
+}